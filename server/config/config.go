@@ -2,12 +2,24 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"github.com/driftdev/storage/server/zapfield"
 	"github.com/google/uuid"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+const (
+	// StorageBackendS3 keeps the content of objects in an s3 compatible object store
+	StorageBackendS3 = "s3"
+	// StorageBackendFilesystem keeps the content of objects in a directory of the local filesystem
+	StorageBackendFilesystem = "filesystem"
+)
+
+// DefaultStorageBackend is the name of the storage backend configured by the top level storage settings,
+// buckets that are created without a backend keep their objects in it
+const DefaultStorageBackend = "default"
+
 type Config struct {
 	ServiceId          string `json:"service_id" mapstructure:"service_id"`
 	ServiceName        string `json:"service_name" mapstructure:"service_name"`
@@ -18,6 +30,8 @@ type Config struct {
 
 	PostgresUrl string `json:"postgres_url" mapstructure:"postgres_url"`
 
+	StorageBackend string `json:"storage_backend" mapstructure:"storage_backend"`
+
 	S3Endpoint        string `json:"s3_endpoint" mapstructure:"s3_endpoint"`
 	S3AccessKeyId     string `json:"s3_access_key_id" mapstructure:"s3_access_key_id"`
 	S3SecretAccessKey string `json:"s3_secret_access_key" mapstructure:"s3_secret_access_key"`
@@ -26,17 +40,77 @@ type Config struct {
 	S3ForcePathStyle  bool   `json:"s3_force_path_style" mapstructure:"s3_force_path_style"`
 	S3DisableSSL      bool   `json:"s3_disable_ssl" mapstructure:"s3_disable_ssl"`
 
-	DefaultBuckets []struct {
-		Id                   string   `json:"id" mapstructure:"id"`
-		Name                 string   `json:"name" mapstructure:"name"`
-		AllowedMimeTypes     []string `json:"allowed_mime_types" mapstructure:"allowed_mime_types"`
-		MaxAllowedObjectSize *int64   `json:"max_allowed_object_size" mapstructure:"max_allowed_object_size"`
-		Public               bool     `json:"public" mapstructure:"public"`
-		Disabled             bool     `json:"disabled" mapstructure:"disabled"`
-	} `json:"default_buckets" mapstructure:"default_buckets"`
+	FilesystemRoot string `json:"filesystem_root" mapstructure:"filesystem_root"`
+	// FilesystemPublicUrl is the url clients reach the server at, pre-signed urls of the filesystem backend point to it
+	FilesystemPublicUrl string `json:"filesystem_public_url" mapstructure:"filesystem_public_url"`
+	// FilesystemSigningKey is the secret pre-signed urls of the filesystem backend are signed with.
+	// it defaults to the service api key
+	FilesystemSigningKey string `json:"filesystem_signing_key" mapstructure:"filesystem_signing_key"`
+
+	// StorageBackends declares named storage backends in addition to the default backend, so that buckets can keep
+	// their objects in another s3 bucket, endpoint, region or account. names are case insensitive
+	StorageBackends map[string]StorageBackendConfig `json:"storage_backends" mapstructure:"storage_backends"`
+
+	DefaultBuckets []DefaultBucket `json:"default_buckets" mapstructure:"default_buckets"`
 
 	DefaultPreSignedUploadUrlExpiry   int64 `json:"default_pre_signed_upload_url_expiry" mapstructure:"default_pre_signed_upload_url_expiry"`
 	DefaultPreSignedDownloadUrlExpiry int64 `json:"default_pre_signed_download_url_expiry" mapstructure:"default_pre_signed_download_url_expiry"`
+
+	DefaultMultipartUploadSessionExpiry int64 `json:"default_multipart_upload_session_expiry" mapstructure:"default_multipart_upload_session_expiry"`
+
+	LifecycleRuleEvaluationInterval int64 `json:"lifecycle_rule_evaluation_interval" mapstructure:"lifecycle_rule_evaluation_interval"`
+
+	TrashPurgeInterval int64 `json:"trash_purge_interval" mapstructure:"trash_purge_interval"`
+
+	// EncryptionMasterKey is the base64 encoded 256-bit master key the data keys of objects of encrypted buckets are
+	// wrapped with, recorded under EncryptionMasterKeyId
+	EncryptionMasterKey   string `json:"encryption_master_key" mapstructure:"encryption_master_key"`
+	EncryptionMasterKeyId string `json:"encryption_master_key_id" mapstructure:"encryption_master_key_id"`
+	// EncryptionKeyringFile is a json file of master keys by id and the id of the active key, like
+	// `{"active_key_id": "2024-06", "keys": {"2024-01": "...", "2024-06": "..."}}`. its active key takes precedence
+	// over EncryptionMasterKey. data keys wrapped with another key are re-wrapped with the active key by the
+	// re-wrap job, which runs on startup and every EncryptionKeyRewrapInterval seconds
+	EncryptionKeyringFile       string `json:"encryption_keyring_file" mapstructure:"encryption_keyring_file"`
+	EncryptionKeyRewrapInterval int64  `json:"encryption_key_rewrap_interval" mapstructure:"encryption_key_rewrap_interval"`
+}
+
+// DefaultBucket declares a bucket that is created or updated to match the declaration on startup.
+// bucket ids are generated by the database, so `id` is only used to warn when a bucket with the same name has a different id
+type DefaultBucket struct {
+	Id                   string   `json:"id" mapstructure:"id"`
+	Name                 string   `json:"name" mapstructure:"name"`
+	AllowedMimeTypes     []string `json:"allowed_mime_types" mapstructure:"allowed_mime_types"`
+	MaxAllowedObjectSize *int64   `json:"max_allowed_object_size" mapstructure:"max_allowed_object_size"`
+	MaxTotalSize         *int64   `json:"max_total_size" mapstructure:"max_total_size"`
+	MaxObjectCount       *int64   `json:"max_object_count" mapstructure:"max_object_count"`
+	TrashRetentionDays   *int32   `json:"trash_retention_days" mapstructure:"trash_retention_days"`
+	Public               bool     `json:"public" mapstructure:"public"`
+	Disabled             bool     `json:"disabled" mapstructure:"disabled"`
+	// Backend is the storage backend of the bucket, it only applies when the bucket is created
+	Backend string `json:"backend" mapstructure:"backend"`
+	// Encryption and EncryptionKmsKeyId are the server-side encryption of the bucket, they only apply when the bucket is created
+	Encryption         string `json:"encryption" mapstructure:"encryption"`
+	EncryptionKmsKeyId string `json:"encryption_kms_key_id" mapstructure:"encryption_kms_key_id"`
+	// Encrypted buckets have their objects encrypted by the service, it only applies when the bucket is created
+	Encrypted bool `json:"encrypted" mapstructure:"encrypted"`
+}
+
+// StorageBackendConfig declares a named storage backend. settings that are left empty are inherited from the
+// top level storage settings
+type StorageBackendConfig struct {
+	Type string `json:"type" mapstructure:"type"`
+
+	S3Endpoint        string `json:"s3_endpoint" mapstructure:"s3_endpoint"`
+	S3AccessKeyId     string `json:"s3_access_key_id" mapstructure:"s3_access_key_id"`
+	S3SecretAccessKey string `json:"s3_secret_access_key" mapstructure:"s3_secret_access_key"`
+	S3Bucket          string `json:"s3_bucket" mapstructure:"s3_bucket"`
+	S3Region          string `json:"s3_region" mapstructure:"s3_region"`
+	S3ForcePathStyle  *bool  `json:"s3_force_path_style" mapstructure:"s3_force_path_style"`
+	S3DisableSSL      *bool  `json:"s3_disable_ssl" mapstructure:"s3_disable_ssl"`
+
+	FilesystemRoot       string `json:"filesystem_root" mapstructure:"filesystem_root"`
+	FilesystemPublicUrl  string `json:"filesystem_public_url" mapstructure:"filesystem_public_url"`
+	FilesystemSigningKey string `json:"filesystem_signing_key" mapstructure:"filesystem_signing_key"`
 }
 
 func (c *Config) SetDefaults() {
@@ -60,10 +134,26 @@ func (c *Config) SetDefaults() {
 		c.ServicePort = "3001"
 	}
 
+	if c.StorageBackend == "" {
+		c.StorageBackend = StorageBackendS3
+	}
+
 	if c.S3Region == "" {
 		c.S3Region = "us-east-1"
 	}
 
+	if c.FilesystemRoot == "" {
+		c.FilesystemRoot = "data"
+	}
+
+	if c.FilesystemPublicUrl == "" {
+		c.FilesystemPublicUrl = "http://localhost:" + c.ServicePort
+	}
+
+	if c.FilesystemSigningKey == "" {
+		c.FilesystemSigningKey = c.ServiceApiKey
+	}
+
 	if c.DefaultPreSignedUploadUrlExpiry == 0 {
 		c.DefaultPreSignedUploadUrlExpiry = 120
 	}
@@ -71,6 +161,26 @@ func (c *Config) SetDefaults() {
 	if c.DefaultPreSignedDownloadUrlExpiry == 0 {
 		c.DefaultPreSignedDownloadUrlExpiry = 300
 	}
+
+	if c.DefaultMultipartUploadSessionExpiry == 0 {
+		c.DefaultMultipartUploadSessionExpiry = 86400
+	}
+
+	if c.LifecycleRuleEvaluationInterval == 0 {
+		c.LifecycleRuleEvaluationInterval = 3600
+	}
+
+	if c.TrashPurgeInterval == 0 {
+		c.TrashPurgeInterval = 3600
+	}
+
+	if c.EncryptionMasterKeyId == "" {
+		c.EncryptionMasterKeyId = "default"
+	}
+
+	if c.EncryptionKeyRewrapInterval == 0 {
+		c.EncryptionKeyRewrapInterval = 3600
+	}
 }
 
 func (c *Config) IsValid() error {
@@ -82,25 +192,119 @@ func (c *Config) IsValid() error {
 		return errors.New("postgres_url is a required")
 	}
 
-	if c.S3Endpoint == "" {
-		return errors.New("s3_endpoint is a required")
+	err := c.isValidStorageBackend()
+	if err != nil {
+		return err
+	}
+
+	for name := range c.StorageBackends {
+		if name == DefaultStorageBackend {
+			return fmt.Errorf("storage_backends cannot declare a backend named %s, it is configured by the top level storage settings", DefaultStorageBackend)
+		}
+
+		storageBackendConfig, _ := c.StorageBackendConfig(name)
+		err = storageBackendConfig.isValidStorageBackend()
+		if err != nil {
+			return fmt.Errorf("storage_backends.%s: %w", name, err)
+		}
 	}
 
-	if c.S3AccessKeyId == "" {
-		return errors.New("s3_access_key_id is a required")
+	for _, defaultBucket := range c.DefaultBuckets {
+		if defaultBucket.Encrypted && c.EncryptionMasterKey == "" && c.EncryptionKeyringFile == "" {
+			return fmt.Errorf("default bucket %s is encrypted, which requires encryption_master_key or encryption_keyring_file", defaultBucket.Name)
+		}
+		if defaultBucket.Backend == "" {
+			continue
+		}
+		if _, ok := c.StorageBackendConfig(defaultBucket.Backend); !ok {
+			return fmt.Errorf("default bucket %s has an unknown backend %s", defaultBucket.Name, defaultBucket.Backend)
+		}
 	}
 
-	if c.S3SecretAccessKey == "" {
-		return errors.New("s3_secret_access_key is a required")
+	return nil
+}
+
+// StorageBackendNames returns the names of the default and the named storage backends
+func (c *Config) StorageBackendNames() []string {
+	names := []string{DefaultStorageBackend}
+	for name := range c.StorageBackends {
+		names = append(names, name)
 	}
+	return names
+}
 
-	if c.S3Bucket == "" {
-		return errors.New("s3_bucket_name is a required")
+// StorageBackendConfig returns a copy of the config with the top level storage settings replaced by the settings
+// of the named storage backend, which is what the backend is created from
+func (c *Config) StorageBackendConfig(name string) (*Config, bool) {
+	storageBackendConfig := *c
+	if name == DefaultStorageBackend {
+		return &storageBackendConfig, true
+	}
+
+	backend, ok := c.StorageBackends[name]
+	if !ok {
+		return nil, false
+	}
+
+	storageBackendConfig.StorageBackend = inherit(backend.Type, c.StorageBackend)
+	storageBackendConfig.S3Endpoint = inherit(backend.S3Endpoint, c.S3Endpoint)
+	storageBackendConfig.S3AccessKeyId = inherit(backend.S3AccessKeyId, c.S3AccessKeyId)
+	storageBackendConfig.S3SecretAccessKey = inherit(backend.S3SecretAccessKey, c.S3SecretAccessKey)
+	storageBackendConfig.S3Bucket = inherit(backend.S3Bucket, c.S3Bucket)
+	storageBackendConfig.S3Region = inherit(backend.S3Region, c.S3Region)
+	if backend.S3ForcePathStyle != nil {
+		storageBackendConfig.S3ForcePathStyle = *backend.S3ForcePathStyle
+	}
+	if backend.S3DisableSSL != nil {
+		storageBackendConfig.S3DisableSSL = *backend.S3DisableSSL
+	}
+	storageBackendConfig.FilesystemRoot = inherit(backend.FilesystemRoot, c.FilesystemRoot)
+	storageBackendConfig.FilesystemPublicUrl = inherit(backend.FilesystemPublicUrl, c.FilesystemPublicUrl)
+	storageBackendConfig.FilesystemSigningKey = inherit(backend.FilesystemSigningKey, c.FilesystemSigningKey)
+
+	return &storageBackendConfig, true
+}
+
+func (c *Config) isValidStorageBackend() error {
+	switch c.StorageBackend {
+	case StorageBackendS3:
+		if c.S3Endpoint == "" {
+			return errors.New("s3_endpoint is a required")
+		}
+
+		if c.S3AccessKeyId == "" {
+			return errors.New("s3_access_key_id is a required")
+		}
+
+		if c.S3SecretAccessKey == "" {
+			return errors.New("s3_secret_access_key is a required")
+		}
+
+		if c.S3Bucket == "" {
+			return errors.New("s3_bucket_name is a required")
+		}
+	case StorageBackendFilesystem:
+		if c.FilesystemRoot == "" {
+			return errors.New("filesystem_root is a required")
+		}
+
+		if c.FilesystemSigningKey == "" {
+			return errors.New("filesystem_signing_key is a required")
+		}
+	default:
+		return errors.New("storage_backend must be one of s3, filesystem")
 	}
 
 	return nil
 }
 
+func inherit(value string, inherited string) string {
+	if value == "" {
+		return inherited
+	}
+	return value
+}
+
 func NewConfig() *Config {
 	const op = "config.NewConfig"
 
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() *Config {
+	config := &Config{
+		ServiceApiKey:     "01HPG7BZW4HDEHWPS0FT50N3NX",
+		PostgresUrl:       "postgresql://localhost:5432/postgres",
+		S3Endpoint:        "http://localhost:9000",
+		S3AccessKeyId:     "access-key-id",
+		S3SecretAccessKey: "secret-access-key",
+		S3Bucket:          "storage",
+		S3ForcePathStyle:  true,
+	}
+	config.SetDefaults()
+	return config
+}
+
+func TestConfig_StorageBackendConfig(t *testing.T) {
+	config := newTestConfig()
+	config.StorageBackends = map[string]StorageBackendConfig{
+		"eu": {
+			S3Endpoint:       "https://s3.eu-central-1.amazonaws.com",
+			S3Bucket:         "storage-eu",
+			S3Region:         "eu-central-1",
+			S3ForcePathStyle: lo.ToPtr(false),
+		},
+	}
+	require.NoError(t, config.IsValid())
+	assert.ElementsMatch(t, []string{DefaultStorageBackend, "eu"}, config.StorageBackendNames())
+
+	defaultConfig, ok := config.StorageBackendConfig(DefaultStorageBackend)
+	require.True(t, ok)
+	assert.Equal(t, "storage", defaultConfig.S3Bucket)
+
+	euConfig, ok := config.StorageBackendConfig("eu")
+	require.True(t, ok)
+	assert.Equal(t, StorageBackendS3, euConfig.StorageBackend)
+	assert.Equal(t, "https://s3.eu-central-1.amazonaws.com", euConfig.S3Endpoint)
+	assert.Equal(t, "storage-eu", euConfig.S3Bucket)
+	assert.Equal(t, "eu-central-1", euConfig.S3Region)
+	assert.Equal(t, "access-key-id", euConfig.S3AccessKeyId)
+	assert.False(t, euConfig.S3ForcePathStyle)
+	assert.Equal(t, "storage", config.S3Bucket)
+
+	_, ok = config.StorageBackendConfig("us")
+	assert.False(t, ok)
+}
+
+func TestConfig_IsValid_StorageBackends(t *testing.T) {
+	tests := []struct {
+		name            string
+		storageBackends map[string]StorageBackendConfig
+		defaultBuckets  []DefaultBucket
+		wantErr         bool
+	}{
+		{
+			name:            "Valid named filesystem backend",
+			storageBackends: map[string]StorageBackendConfig{"local": {Type: StorageBackendFilesystem}},
+			defaultBuckets:  []DefaultBucket{{Name: "avatars", Backend: "local"}},
+			wantErr:         false,
+		},
+		{
+			name:            "Invalid backend named default",
+			storageBackends: map[string]StorageBackendConfig{DefaultStorageBackend: {}},
+			wantErr:         true,
+		},
+		{
+			name:            "Invalid backend type",
+			storageBackends: map[string]StorageBackendConfig{"eu": {Type: "gcs"}},
+			wantErr:         true,
+		},
+		{
+			name:           "Invalid default bucket backend",
+			defaultBuckets: []DefaultBucket{{Name: "avatars", Backend: "eu"}},
+			wantErr:        true,
+		},
+		{
+			name:           "Invalid encrypted default bucket without a master key",
+			defaultBuckets: []DefaultBucket{{Name: "records", Encrypted: true}},
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.StorageBackends = tt.storageBackends
+			config.DefaultBuckets = tt.defaultBuckets
+
+			err := config.IsValid()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -30,6 +30,11 @@ func (bc *BucketController) RegisterBucketRoutes(app *fiber.App) {
 	routesV1.Get("/buckets/search", bc.SearchBuckets)
 	routesV1.Get("/buckets/:bucket_id", bc.GetBucket)
 	routesV1.Get("/buckets/:bucket_id/size", bc.GetBucketSize)
+	routesV1.Post("/buckets/:bucket_id/lifecycle-rules", bc.CreateBucketLifecycleRule)
+	routesV1.Put("/buckets/:bucket_id/lifecycle-rules/:lifecycle_rule_id", bc.UpdateBucketLifecycleRule)
+	routesV1.Delete("/buckets/:bucket_id/lifecycle-rules/:lifecycle_rule_id", bc.DeleteBucketLifecycleRule)
+	routesV1.Get("/buckets/:bucket_id/lifecycle-rules", bc.ListBucketLifecycleRules)
+	routesV1.Get("/buckets/:bucket_id/lifecycle-rules/:lifecycle_rule_id", bc.GetBucketLifecycleRule)
 }
 
 // CreateBucket is used to create a bucket
@@ -179,16 +184,25 @@ func (bc *BucketController) DeleteBucket(ctx *fiber.Ctx) error {
 
 // ListAllBuckets is used to list all buckets
 // @Summary List all buckets
-// @Description List all buckets
+// @Description List all buckets ordered by id a page at a time. pass `next_cursor` or `prev_cursor` of a page as `cursor` to get the adjacent page
 // @Tags buckets
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Bucket
+// @Param cursor query string false "Cursor"
+// @Param limit query int false "Limit" default(10)
+// @Success 200 {object} models.PaginationResult[models.Bucket]
 // @Failure 400 {object} middleware.HttpError
 // @Failure 500 {object} middleware.HttpError
 // @Router /api/v1/buckets [get]
 func (bc *BucketController) ListAllBuckets(ctx *fiber.Ctx) error {
-	buckets, err := bc.bucketService.ListAllBuckets(ctx.Context())
+	var paginationInput models.PaginationInput
+
+	err := ctx.QueryParser(&paginationInput)
+	if err != nil {
+		return err
+	}
+
+	buckets, err := bc.bucketService.ListAllBuckets(ctx.Context(), &paginationInput)
 	if err != nil {
 		return err
 	}
@@ -196,21 +210,34 @@ func (bc *BucketController) ListAllBuckets(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(buckets)
 }
 
-// SearchBuckets is used to list all buckets
+// SearchBuckets is used to search buckets by name
 // @Summary Search buckets
-// @Description List all buckets
+// @Description Search buckets whose name starts with, contains or is similar to `name` depending on `mode`. results are ordered
+// @Description by how similar the bucket name is to `name` and then by id a page at a time. pass `next_cursor` or `prev_cursor`
+// @Description of a page as `cursor` to get the adjacent page
 // @Tags buckets
 // @Accept json
 // @Produce json
 // @Param name query string true "Bucket Name"
-// @Success 200 {array} models.Bucket
+// @Param mode query string false "Search Mode" Enums(prefix, contains, fuzzy) default(contains)
+// @Param cursor query string false "Cursor"
+// @Param limit query int false "Limit" default(10)
+// @Success 200 {object} models.PaginationResult[models.Bucket]
 // @Failure 400 {object} middleware.HttpError
 // @Failure 500 {object} middleware.HttpError
 // @Router /api/v1/buckets/search [get]
 func (bc *BucketController) SearchBuckets(ctx *fiber.Ctx) error {
+	var paginationInput models.PaginationInput
+
 	name := ctx.Query("name")
+	mode := ctx.Query("mode")
 
-	buckets, err := bc.bucketService.SearchBuckets(ctx.Context(), name)
+	err := ctx.QueryParser(&paginationInput)
+	if err != nil {
+		return err
+	}
+
+	buckets, err := bc.bucketService.SearchBuckets(ctx.Context(), name, mode, &paginationInput)
 	if err != nil {
 		return err
 	}
@@ -242,7 +269,8 @@ func (bc *BucketController) GetBucket(ctx *fiber.Ctx) error {
 
 // GetBucketSize is used to get size of a bucket
 // @Summary Get size of a bucket
-// @Description Get size of a bucket
+// @Description Get the size and object count of a bucket together with its quotas. pending uploads are counted as they reserve their declared size
+// @Description and objects in the trash are not counted
 // @Tags buckets
 // @Accept json
 // @Produce json
@@ -261,3 +289,142 @@ func (bc *BucketController) GetBucketSize(ctx *fiber.Ctx) error {
 
 	return ctx.Status(fiber.StatusOK).JSON(bucketSize)
 }
+
+// CreateBucketLifecycleRule is used to create a lifecycle rule for a bucket
+// @Summary Create a bucket lifecycle rule
+// @Description Create a lifecycle rule that expires the objects of a bucket or aborts its pending uploads. rules are evaluated periodically and matching objects are deleted in the background
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param lifecycle_rule body models.BucketLifecycleRuleCreate true "Bucket Lifecycle Rule Create"
+// @Success 201 {object} models.BucketLifecycleRule
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 409 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/buckets/{bucket_id}/lifecycle-rules [post]
+func (bc *BucketController) CreateBucketLifecycleRule(ctx *fiber.Ctx) error {
+	var lifecycleRuleCreate models.BucketLifecycleRuleCreate
+
+	err := ctx.BodyParser(&lifecycleRuleCreate)
+	if err != nil {
+		return err
+	}
+
+	lifecycleRuleCreate.BucketId = ctx.Params("bucket_id")
+
+	createdLifecycleRule, err := bc.bucketService.CreateBucketLifecycleRule(ctx.Context(), &lifecycleRuleCreate)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(createdLifecycleRule)
+}
+
+// UpdateBucketLifecycleRule is used to replace a lifecycle rule of a bucket
+// @Summary Update a bucket lifecycle rule
+// @Description Replace the settings of a lifecycle rule of a bucket
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param lifecycle_rule_id path string true "Lifecycle Rule ID"
+// @Param lifecycle_rule body models.BucketLifecycleRuleUpdate true "Bucket Lifecycle Rule Update"
+// @Success 200 {object} models.BucketLifecycleRule
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 409 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/buckets/{bucket_id}/lifecycle-rules/{lifecycle_rule_id} [put]
+func (bc *BucketController) UpdateBucketLifecycleRule(ctx *fiber.Ctx) error {
+	var lifecycleRuleUpdate models.BucketLifecycleRuleUpdate
+
+	err := ctx.BodyParser(&lifecycleRuleUpdate)
+	if err != nil {
+		return err
+	}
+
+	lifecycleRuleUpdate.Id = ctx.Params("lifecycle_rule_id")
+	lifecycleRuleUpdate.BucketId = ctx.Params("bucket_id")
+
+	updatedLifecycleRule, err := bc.bucketService.UpdateBucketLifecycleRule(ctx.Context(), &lifecycleRuleUpdate)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(updatedLifecycleRule)
+}
+
+// DeleteBucketLifecycleRule is used to delete a lifecycle rule of a bucket
+// @Summary Delete a bucket lifecycle rule
+// @Description Delete a lifecycle rule of a bucket. objects that already matched the rule may still be deleted
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param lifecycle_rule_id path string true "Lifecycle Rule ID"
+// @Success 204
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/buckets/{bucket_id}/lifecycle-rules/{lifecycle_rule_id} [delete]
+func (bc *BucketController) DeleteBucketLifecycleRule(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+	lifecycleRuleId := ctx.Params("lifecycle_rule_id")
+
+	err := bc.bucketService.DeleteBucketLifecycleRule(ctx.Context(), bucketId, lifecycleRuleId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ListBucketLifecycleRules is used to list the lifecycle rules of a bucket
+// @Summary List bucket lifecycle rules
+// @Description List the lifecycle rules of a bucket
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Success 200 {array} models.BucketLifecycleRule
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/buckets/{bucket_id}/lifecycle-rules [get]
+func (bc *BucketController) ListBucketLifecycleRules(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+
+	lifecycleRules, err := bc.bucketService.ListBucketLifecycleRules(ctx.Context(), bucketId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(lifecycleRules)
+}
+
+// GetBucketLifecycleRule is used to get a lifecycle rule of a bucket
+// @Summary Get a bucket lifecycle rule
+// @Description Get a lifecycle rule of a bucket
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param lifecycle_rule_id path string true "Lifecycle Rule ID"
+// @Success 200 {object} models.BucketLifecycleRule
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/buckets/{bucket_id}/lifecycle-rules/{lifecycle_rule_id} [get]
+func (bc *BucketController) GetBucketLifecycleRule(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+	lifecycleRuleId := ctx.Params("lifecycle_rule_id")
+
+	lifecycleRule, err := bc.bucketService.GetBucketLifecycleRule(ctx.Context(), bucketId, lifecycleRuleId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(lifecycleRule)
+}
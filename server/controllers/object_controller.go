@@ -1,8 +1,17 @@
 package controllers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"github.com/driftdev/storage/server/models"
 	"github.com/driftdev/storage/server/services"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
 )
 
 type ObjectController struct {
@@ -23,9 +32,30 @@ func (oc *ObjectController) RegisterObjectRoutes(app *fiber.App) {
 	routesV1.Post("/objects/pre-signed/upload/:bucket_id", oc.CreatePreSignedUploadSession)
 	routesV1.Post("/objects/pre-signed/upload/:bucket_id/:object_id/complete", oc.CompletePreSignedUploadSession)
 	routesV1.Get("/objects/pre-signed/download/:bucket_id/:object_id", oc.CreatePreSignedDownloadSession)
+	routesV1.Post("/objects/pre-signed/multipart/:bucket_id", oc.CreateMultipartUploadSession)
+	routesV1.Post("/objects/pre-signed/multipart/:bucket_id/:object_id/parts", oc.CreatePreSignedUploadParts)
+	routesV1.Get("/objects/pre-signed/multipart/:bucket_id/:object_id/parts", oc.ListUploadedParts)
+	routesV1.Post("/objects/pre-signed/multipart/:bucket_id/:object_id/complete", oc.CompleteMultipartUploadSession)
+	routesV1.Delete("/objects/pre-signed/multipart/:bucket_id/:object_id", oc.AbortMultipartUploadSession)
+	routesV1.Post("/objects/:bucket_id/:object_id/rename", oc.RenameObject)
+	routesV1.Post("/objects/:bucket_id/:object_id/copy", oc.CopyObject)
+	routesV1.Post("/objects/:bucket_id/:object_id/move", oc.MoveObject)
+	routesV1.Patch("/objects/:bucket_id/:object_id", oc.UpdateObject)
 	routesV1.Delete("/objects/:bucket_id/:object_id", oc.DeleteObject)
+	routesV1.Post("/objects/:bucket_id/delete", oc.CreateObjectBulkDeletion)
+	routesV1.Get("/objects/:bucket_id/delete/:bulk_deletion_id", oc.GetObjectBulkDeletion)
+	routesV1.Get("/objects/trash/:bucket_id", oc.ListTrashedObjects)
+	routesV1.Post("/objects/trash/:bucket_id/:object_id/restore", oc.RestoreObject)
+	routesV1.Delete("/objects/trash/:bucket_id/:object_id", oc.DeleteTrashedObject)
+	routesV1.Put("/objects/tags/:bucket_id/:object_id", oc.UpdateObjectTags)
 	routesV1.Get("/objects/search/:bucket_id", oc.SearchObjects)
+	routesV1.Get("/objects/list/:bucket_id", oc.ListObjects)
 	routesV1.Get("/objects/:bucket_id/:object_id", oc.GetObject)
+	routesV1.Get("/objects/:bucket_id/:object_id/content", oc.DownloadObject)
+	routesV1.Put("/objects/:bucket_id/*", oc.UploadObject)
+
+	// public routes are served without an api key, see middleware.KeyAuth
+	app.Get("/public/:bucket_name/*", oc.DownloadPublicObject)
 }
 
 // CreatePreSignedUploadSession is used to create a pre signed upload session
@@ -109,9 +139,315 @@ func (oc *ObjectController) CreatePreSignedDownloadSession(ctx *fiber.Ctx) error
 	return ctx.Status(fiber.StatusOK).JSON(preSignedDownloadObject)
 }
 
+// CreateMultipartUploadSession is used to create a multipart upload session
+// @Summary Create a multipart upload session
+// @Description Create a multipart upload session for uploading large objects in parts with pre-signed part urls
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param session body models.MultipartUploadSessionCreate true "Multipart Upload Session Create"
+// @Success 201 {object} models.MultipartUploadSession
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/pre-signed/multipart/{bucket_id} [post]
+func (oc *ObjectController) CreateMultipartUploadSession(ctx *fiber.Ctx) error {
+	var multipartUploadSessionCreate models.MultipartUploadSessionCreate
+
+	multipartUploadSessionCreate.BucketId = ctx.Params("bucket_id")
+
+	err := ctx.BodyParser(&multipartUploadSessionCreate)
+	if err != nil {
+		return err
+	}
+
+	multipartUploadSession, err := oc.objectService.CreateMultipartUploadSession(ctx.Context(), &multipartUploadSessionCreate)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(multipartUploadSession)
+}
+
+// CreatePreSignedUploadParts is used to create pre signed upload urls for parts of a multipart upload session
+// @Summary Create pre signed upload part urls
+// @Description Create pre signed upload urls for parts of a multipart upload session
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param parts body models.PreSignedUploadPartsCreate true "Pre Signed Upload Parts Create"
+// @Success 201 {array} models.PreSignedUploadPart
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/pre-signed/multipart/{bucket_id}/{object_id}/parts [post]
+func (oc *ObjectController) CreatePreSignedUploadParts(ctx *fiber.Ctx) error {
+	var preSignedUploadPartsCreate models.PreSignedUploadPartsCreate
+
+	preSignedUploadPartsCreate.BucketId = ctx.Params("bucket_id")
+	preSignedUploadPartsCreate.ObjectId = ctx.Params("object_id")
+
+	err := ctx.BodyParser(&preSignedUploadPartsCreate)
+	if err != nil {
+		return err
+	}
+
+	preSignedUploadParts, err := oc.objectService.CreatePreSignedUploadParts(ctx.Context(), &preSignedUploadPartsCreate)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(preSignedUploadParts)
+}
+
+// ListUploadedParts is used to list the uploaded parts of a multipart upload session
+// @Summary List uploaded parts
+// @Description List the parts that have been uploaded so far for a multipart upload session
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Success 200 {array} models.UploadedPart
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/pre-signed/multipart/{bucket_id}/{object_id}/parts [get]
+func (oc *ObjectController) ListUploadedParts(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+	objectId := ctx.Params("object_id")
+
+	uploadedParts, err := oc.objectService.ListUploadedParts(ctx.Context(), bucketId, objectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(uploadedParts)
+}
+
+// CompleteMultipartUploadSession is used to complete a multipart upload session
+// @Summary Complete a multipart upload session
+// @Description Complete a multipart upload session with the part numbers and etags of all uploaded parts
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param parts body models.MultipartUploadSessionComplete true "Multipart Upload Session Complete"
+// @Success 200 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/pre-signed/multipart/{bucket_id}/{object_id}/complete [post]
+func (oc *ObjectController) CompleteMultipartUploadSession(ctx *fiber.Ctx) error {
+	var multipartUploadSessionComplete models.MultipartUploadSessionComplete
+
+	multipartUploadSessionComplete.BucketId = ctx.Params("bucket_id")
+	multipartUploadSessionComplete.ObjectId = ctx.Params("object_id")
+
+	err := ctx.BodyParser(&multipartUploadSessionComplete)
+	if err != nil {
+		return err
+	}
+
+	object, err := oc.objectService.CompleteMultipartUploadSession(ctx.Context(), &multipartUploadSessionComplete)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(object)
+}
+
+// AbortMultipartUploadSession is used to abort a multipart upload session
+// @Summary Abort a multipart upload session
+// @Description Abort a multipart upload session and discard all uploaded parts
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Success 204
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/pre-signed/multipart/{bucket_id}/{object_id} [delete]
+func (oc *ObjectController) AbortMultipartUploadSession(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+	objectId := ctx.Params("object_id")
+
+	err := oc.objectService.AbortMultipartUploadSession(ctx.Context(), bucketId, objectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// RenameObject is used to rename an object
+// @Summary Rename an object
+// @Description Rename an object within its bucket. the object keeps its id and the old name is removed from storage in the background
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param rename body models.ObjectRename true "Object Rename"
+// @Success 200 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/{bucket_id}/{object_id}/rename [post]
+func (oc *ObjectController) RenameObject(ctx *fiber.Ctx) error {
+	var objectRename models.ObjectRename
+
+	objectRename.BucketId = ctx.Params("bucket_id")
+	objectRename.ObjectId = ctx.Params("object_id")
+
+	err := ctx.BodyParser(&objectRename)
+	if err != nil {
+		return err
+	}
+
+	object, err := oc.objectService.RenameObject(ctx.Context(), &objectRename)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(object)
+}
+
+// CopyObject is used to copy an object
+// @Summary Copy an object
+// @Description Copy an object to a new name in the same bucket or in another bucket
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param copy body models.ObjectCopy true "Object Copy"
+// @Success 201 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/{bucket_id}/{object_id}/copy [post]
+func (oc *ObjectController) CopyObject(ctx *fiber.Ctx) error {
+	var objectCopy models.ObjectCopy
+
+	objectCopy.BucketId = ctx.Params("bucket_id")
+	objectCopy.ObjectId = ctx.Params("object_id")
+
+	err := ctx.BodyParser(&objectCopy)
+	if err != nil {
+		return err
+	}
+
+	object, err := oc.objectService.CopyObject(ctx.Context(), &objectCopy)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(object)
+}
+
+// MoveObject is used to move an object
+// @Summary Move an object
+// @Description Move an object to a new name in the same bucket or in another bucket. the object keeps its id and the source is removed from storage in the background
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param move body models.ObjectMove true "Object Move"
+// @Success 200 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/{bucket_id}/{object_id}/move [post]
+func (oc *ObjectController) MoveObject(ctx *fiber.Ctx) error {
+	var objectMove models.ObjectMove
+
+	objectMove.BucketId = ctx.Params("bucket_id")
+	objectMove.ObjectId = ctx.Params("object_id")
+
+	err := ctx.BodyParser(&objectMove)
+	if err != nil {
+		return err
+	}
+
+	object, err := oc.objectService.MoveObject(ctx.Context(), &objectMove)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(object)
+}
+
+// UpdateObject is used to update the mime type and metadata of an object
+// @Summary Update an object
+// @Description Update the mime type and metadata of an object. metadata is merged into the existing metadata
+// @Description unless `metadata_mode` is `replace`. a new mime type must be allowed by the bucket
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param object body models.ObjectUpdate true "Object Update"
+// @Success 200 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/{bucket_id}/{object_id} [patch]
+func (oc *ObjectController) UpdateObject(ctx *fiber.Ctx) error {
+	var objectUpdate models.ObjectUpdate
+
+	objectUpdate.BucketId = ctx.Params("bucket_id")
+	objectUpdate.ObjectId = ctx.Params("object_id")
+
+	err := ctx.BodyParser(&objectUpdate)
+	if err != nil {
+		return err
+	}
+
+	object, err := oc.objectService.UpdateObject(ctx.Context(), &objectUpdate)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(object)
+}
+
+// UpdateObjectTags is used to replace the tags of an object
+// @Summary Update the tags of an object
+// @Description Replace all tags of an object. an object can have at most 10 tags with keys of at most 128 and values
+// @Description of at most 256 characters. an empty tag set removes all tags
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param object body models.ObjectTagsUpdate true "Object Tags Update"
+// @Success 200 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/tags/{bucket_id}/{object_id} [put]
+func (oc *ObjectController) UpdateObjectTags(ctx *fiber.Ctx) error {
+	var objectTagsUpdate models.ObjectTagsUpdate
+
+	objectTagsUpdate.BucketId = ctx.Params("bucket_id")
+	objectTagsUpdate.ObjectId = ctx.Params("object_id")
+
+	err := ctx.BodyParser(&objectTagsUpdate)
+	if err != nil {
+		return err
+	}
+
+	object, err := oc.objectService.UpdateObjectTags(ctx.Context(), &objectTagsUpdate)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(object)
+}
+
 // DeleteObject is used to delete an object
 // @Summary Delete an object
-// @Description Delete an object
+// @Description Delete an object. the object is moved to the trash of the bucket and is deleted permanently once the
+// @Description trash retention of the bucket has passed. the name of a trashed object can be used by new objects right away.
+// @Description objects of buckets with a trash retention of 0 days are deleted right away
 // @Tags objects
 // @Accept json
 // @Produce json
@@ -119,6 +455,8 @@ func (oc *ObjectController) CreatePreSignedDownloadSession(ctx *fiber.Ctx) error
 // @Param object_id path string true "Object ID"
 // @Success 204
 // @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 409 {object} middleware.HttpError
 // @Failure 500 {object} middleware.HttpError
 // @Router /api/v1/objects/{bucket_id}/{object_id} [delete]
 func (oc *ObjectController) DeleteObject(ctx *fiber.Ctx) error {
@@ -133,28 +471,83 @@ func (oc *ObjectController) DeleteObject(ctx *fiber.Ctx) error {
 	return ctx.SendStatus(fiber.StatusNoContent)
 }
 
-// SearchObjects is used to search objects
-// @Summary Search objects by path
-// @Description Search objects by path
+// CreateObjectBulkDeletion is used to delete many objects of a bucket at once
+// @Summary Delete objects in bulk
+// @Description Delete the uploaded objects of a bucket selected by `object_ids`, or by a name `prefix` and the `tags` the objects
+// @Description must have, with a single job that deletes them in batches. the objects are moved to the trash of the bucket unless
+// @Description `permanent` is set or the bucket has a trash retention of 0 days. poll the returned bulk deletion for its progress
+// @Description and the objects that failed to delete
 // @Tags objects
 // @Accept json
 // @Produce json
 // @Param bucket_id path string true "Bucket ID"
-// @Param object_path query string true "Object Path"
-// @Param limit query int true "Limit"
-// @Param offset query int true "Offset"
-// @Success 200 {array} models.Object
+// @Param bulk_deletion body models.ObjectBulkDeletionCreate true "Object Bulk Deletion Create"
+// @Success 202 {object} models.ObjectBulkDeletion
 // @Failure 400 {object} middleware.HttpError
 // @Failure 500 {object} middleware.HttpError
-// @Router /api/v1/objects/search/{bucket_id} [get]
-func (oc *ObjectController) SearchObjects(ctx *fiber.Ctx) error {
+// @Router /api/v1/objects/{bucket_id}/delete [post]
+func (oc *ObjectController) CreateObjectBulkDeletion(ctx *fiber.Ctx) error {
+	var bulkDeletionCreate models.ObjectBulkDeletionCreate
+
+	bulkDeletionCreate.BucketId = ctx.Params("bucket_id")
+
+	err := ctx.BodyParser(&bulkDeletionCreate)
+	if err != nil {
+		return err
+	}
+
+	bulkDeletion, err := oc.objectService.CreateObjectBulkDeletion(ctx.Context(), &bulkDeletionCreate)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(bulkDeletion)
+}
+
+// GetObjectBulkDeletion is used to get the progress of a bulk deletion
+// @Summary Get a bulk deletion
+// @Description Get the status, the counts of deleted and failed objects and the reported failures of a bulk deletion
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param bulk_deletion_id path string true "Bulk Deletion ID"
+// @Success 200 {object} models.ObjectBulkDeletion
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/{bucket_id}/delete/{bulk_deletion_id} [get]
+func (oc *ObjectController) GetObjectBulkDeletion(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+	bulkDeletionId := ctx.Params("bulk_deletion_id")
+
+	bulkDeletion, err := oc.objectService.GetObjectBulkDeletion(ctx.Context(), bucketId, bulkDeletionId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(bulkDeletion)
+}
+
+// ListTrashedObjects is used to list the objects in the trash of a bucket
+// @Summary List trashed objects
+// @Description List the objects in the trash of a bucket, most recently deleted first
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param cursor query string false "Cursor"
+// @Param limit query int false "Limit" default(10)
+// @Success 200 {object} models.ObjectSearchResult
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/trash/{bucket_id} [get]
+func (oc *ObjectController) ListTrashedObjects(ctx *fiber.Ctx) error {
 	bucketId := ctx.Params("bucket_id")
 
-	objectPath := ctx.Query("object_path")
 	limit := ctx.QueryInt("limit")
 	offset := ctx.QueryInt("offset")
 
-	objects, err := oc.objectService.SearchObjects(ctx.Context(), bucketId, objectPath, int32(limit), int32(offset))
+	objects, err := oc.objectService.ListTrashedObjects(ctx.Context(), bucketId, int32(limit), int32(offset))
 	if err != nil {
 		return err
 	}
@@ -162,6 +555,148 @@ func (oc *ObjectController) SearchObjects(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(objects)
 }
 
+// RestoreObject is used to restore an object from the trash
+// @Summary Restore a trashed object
+// @Description Restore an object from the trash of a bucket under its original name. fails if another object with the same name
+// @Description has been created since the object was deleted
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Success 200 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 409 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/trash/{bucket_id}/{object_id}/restore [post]
+func (oc *ObjectController) RestoreObject(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+	objectId := ctx.Params("object_id")
+
+	object, err := oc.objectService.RestoreObject(ctx.Context(), bucketId, objectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(object)
+}
+
+// DeleteTrashedObject is used to permanently delete an object from the trash
+// @Summary Delete a trashed object
+// @Description Delete an object from the trash of a bucket permanently before the trash retention of the bucket has passed
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Success 204
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/trash/{bucket_id}/{object_id} [delete]
+func (oc *ObjectController) DeleteTrashedObject(ctx *fiber.Ctx) error {
+	bucketId := ctx.Params("bucket_id")
+	objectId := ctx.Params("object_id")
+
+	err := oc.objectService.DeleteTrashedObject(ctx.Context(), bucketId, objectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ListObjects is used to list the objects and common prefixes below a prefix
+// @Summary List objects by prefix
+// @Description List the objects of a bucket whose name starts with `prefix` ordered by name. with a `delimiter` the objects
+// @Description below the prefix whose remaining name contains the delimiter are grouped into common prefixes like sub folders.
+// @Description pass `next_cursor` of a page as `cursor` to get the next page
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param prefix query string false "Prefix"
+// @Param delimiter query string false "Delimiter"
+// @Param tag query []string false "Tag filters in the format key=value" collectionFormat(multi)
+// @Param cursor query string false "Cursor"
+// @Param limit query int false "Limit" default(10)
+// @Success 200 {object} models.ObjectListResult
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/list/{bucket_id} [get]
+func (oc *ObjectController) ListObjects(ctx *fiber.Ctx) error {
+	var objectListInput models.ObjectListInput
+	var paginationInput models.PaginationInput
+
+	err := ctx.QueryParser(&objectListInput)
+	if err != nil {
+		return err
+	}
+
+	objectListInput.BucketId = ctx.Params("bucket_id")
+
+	err = ctx.QueryParser(&paginationInput)
+	if err != nil {
+		return err
+	}
+
+	objectList, err := oc.objectService.ListObjects(ctx.Context(), &objectListInput, &paginationInput)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(objectList)
+}
+
+// SearchObjects is used to search objects
+// @Summary Search objects by path, tags and metadata
+// @Description Search objects whose name starts with, contains or is similar to `object_path` depending on `mode` and that match
+// @Description all of the given tag and metadata filters. `metadata_equals`, `metadata_contains` and `metadata_range` are json
+// @Description objects, for example `metadata_equals={"owner_id":"42"}` or `metadata_range={"width":{"gte":1024,"lt":4096}}`.
+// @Description results are ordered by how similar the object name is to `object_path` and then by id. pass `next_cursor` of a
+// @Description page as `cursor` to get the next page
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_path query string false "Object Path"
+// @Param mode query string false "Search Mode" Enums(prefix, contains, fuzzy) default(contains)
+// @Param tag query []string false "Tag filters in the format key=value" collectionFormat(multi)
+// @Param metadata_equals query string false "Metadata keys and their expected string, number, boolean or null values as a json object"
+// @Param metadata_contains query string false "Json object the metadata must contain"
+// @Param metadata_exists query []string false "Metadata keys that must exist" collectionFormat(multi)
+// @Param metadata_range query string false "Metadata keys and their gt, gte, lt and lte bounds as a json object"
+// @Param cursor query string false "Cursor"
+// @Param limit query int false "Limit" default(10)
+// @Success 200 {object} models.ObjectSearchResult
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/search/{bucket_id} [get]
+func (oc *ObjectController) SearchObjects(ctx *fiber.Ctx) error {
+	var objectSearchInput models.ObjectSearchInput
+	var paginationInput models.PaginationInput
+
+	err := ctx.QueryParser(&objectSearchInput)
+	if err != nil {
+		return err
+	}
+
+	objectSearchInput.BucketId = ctx.Params("bucket_id")
+
+	err = ctx.QueryParser(&paginationInput)
+	if err != nil {
+		return err
+	}
+
+	objectSearch, err := oc.objectService.SearchObjects(ctx.Context(), &objectSearchInput, &paginationInput)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(objectSearch)
+}
+
 // GetObject is used to get an object
 // @Summary Get an object
 // @Description Get an object
@@ -185,3 +720,243 @@ func (oc *ObjectController) GetObject(ctx *fiber.Ctx) error {
 
 	return ctx.Status(fiber.StatusOK).JSON(object)
 }
+
+// UploadObject is used to upload an object through the server
+// @Summary Upload an object
+// @Description Upload an object by streaming the request body to storage. the body can either be the raw object content
+// @Description or a multipart/form-data body with the object content in a `file` field
+// @Tags objects
+// @Accept application/octet-stream,multipart/form-data
+// @Produce json
+// @Param bucket_id path string true "Bucket ID"
+// @Param name path string true "Object Name"
+// @Success 201 {object} models.Object
+// @Failure 400 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/{bucket_id}/{name} [put]
+func (oc *ObjectController) UploadObject(ctx *fiber.Ctx) error {
+	var objectUploadCreate models.ObjectUploadCreate
+
+	objectUploadCreate.BucketId = ctx.Params("bucket_id")
+
+	name, err := url.PathUnescape(ctx.Params("*"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "object name is not a valid url path")
+	}
+	objectUploadCreate.Name = name
+
+	content, err := objectUploadContent(ctx, &objectUploadCreate)
+	if err != nil {
+		return err
+	}
+
+	object, err := oc.objectService.UploadObject(ctx.Context(), &objectUploadCreate, content)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(object)
+}
+
+// objectUploadContent returns a reader over the object content of an upload request. for multipart/form-data
+// requests it is the first file part, otherwise it is the raw request body
+func objectUploadContent(ctx *fiber.Ctx, objectUploadCreate *models.ObjectUploadCreate) (io.Reader, error) {
+	var body io.Reader = ctx.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.Body())
+	}
+
+	mediaType, params, err := parseUploadContentType(ctx.Get(fiber.HeaderContentType))
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaType == nil || *mediaType != fiber.MIMEMultipartForm {
+		objectUploadCreate.MimeType = mediaType
+		objectUploadCreate.Size = int64(max(ctx.Request().Header.ContentLength(), 0))
+		return body, nil
+	}
+
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "multipart form does not contain a file")
+			}
+			return nil, fiber.NewError(fiber.StatusBadRequest, "multipart form is not valid")
+		}
+
+		if part.FileName() == "" {
+			continue
+		}
+
+		objectUploadCreate.MimeType, _, err = parseUploadContentType(part.Header.Get(fiber.HeaderContentType))
+		if err != nil {
+			return nil, err
+		}
+
+		return part, nil
+	}
+}
+
+// parseUploadContentType returns the media type declared by an upload. it is nil when no content type was sent
+// or when it is the form encoding most http clients send by default, so that the mime type is inferred instead
+func parseUploadContentType(contentType string) (*string, map[string]string, error) {
+	if contentType == "" {
+		return nil, nil, nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("content type '%s' is not valid", contentType))
+	}
+
+	if mediaType == fiber.MIMEApplicationForm {
+		return nil, nil, nil
+	}
+
+	return &mediaType, params, nil
+}
+
+// DownloadObject is used to download the content of an object through the server
+// @Summary Download an object
+// @Description Download the content of an object through the server. supports single byte ranges with the `Range` header
+// @Description and conditional requests with the `If-None-Match` and `If-Modified-Since` headers. HEAD only returns the headers
+// @Description full downloads of objects uploaded with a checksum return it in the `x-amz-checksum-sha256`, `x-amz-checksum-crc32c` or `Content-MD5` header
+// @Tags objects
+// @Produce octet-stream
+// @Param bucket_id path string true "Bucket ID"
+// @Param object_id path string true "Object ID"
+// @Param Range header string false "Byte Range"
+// @Param If-None-Match header string false "ETag"
+// @Param If-Modified-Since header string false "HTTP Date"
+// @Param X-Encryption-Customer-Key header string false "Base64 encoded 256-bit key of objects encrypted with sse-c"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304
+// @Failure 400 {object} middleware.HttpError
+// @Failure 403 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 416 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /api/v1/objects/{bucket_id}/{object_id}/content [get]
+func (oc *ObjectController) DownloadObject(ctx *fiber.Ctx) error {
+	objectDownload := models.ObjectDownload{
+		BucketId: ctx.Params("bucket_id"),
+		ObjectId: ctx.Params("object_id"),
+		HeadOnly: ctx.Method() == fiber.MethodHead,
+	}
+
+	objectDownload.Range, objectDownload.IfNoneMatch, objectDownload.IfModifiedSince = objectDownloadHeaders(ctx)
+
+	objectContent, err := oc.objectService.DownloadObject(ctx.Context(), &objectDownload)
+	if err != nil {
+		return err
+	}
+
+	return sendObjectContent(ctx, objectContent)
+}
+
+// DownloadPublicObject is used to download the content of an object of a public bucket without an api key
+// @Summary Download a public object
+// @Description Download the content of an object of a public bucket by bucket name and object name without an api key.
+// @Description private buckets respond with 404. supports the same range and conditional headers as the object download
+// @Tags objects
+// @Produce octet-stream
+// @Param bucket_name path string true "Bucket Name"
+// @Param object_name path string true "Object Name"
+// @Param Range header string false "Byte Range"
+// @Param If-None-Match header string false "ETag"
+// @Param If-Modified-Since header string false "HTTP Date"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304
+// @Failure 400 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 416 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /public/{bucket_name}/{object_name} [get]
+func (oc *ObjectController) DownloadPublicObject(ctx *fiber.Ctx) error {
+	objectName, err := url.PathUnescape(ctx.Params("*"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "object name is not a valid url path")
+	}
+
+	publicObjectDownload := models.PublicObjectDownload{
+		BucketName: ctx.Params("bucket_name"),
+		ObjectName: objectName,
+		HeadOnly:   ctx.Method() == fiber.MethodHead,
+	}
+
+	publicObjectDownload.Range, publicObjectDownload.IfNoneMatch, publicObjectDownload.IfModifiedSince = objectDownloadHeaders(ctx)
+
+	objectContent, err := oc.objectService.DownloadPublicObject(ctx.Context(), &publicObjectDownload)
+	if err != nil {
+		return err
+	}
+
+	return sendObjectContent(ctx, objectContent)
+}
+
+// objectChecksumHeader returns the header s3 uses for the checksum of an object so clients can verify
+// downloads the same way as objects downloaded from s3 directly
+func objectChecksumHeader(algorithm string) string {
+	switch algorithm {
+	case models.ObjectChecksumAlgorithmMD5:
+		return "Content-MD5"
+	default:
+		return "x-amz-checksum-" + algorithm
+	}
+}
+
+// objectDownloadHeaders returns the range and conditional headers of a download request. an unparsable
+// `If-Modified-Since` header is ignored as required by rfc 9110
+func objectDownloadHeaders(ctx *fiber.Ctx) (rangeHeader *string, ifNoneMatch *string, ifModifiedSince *time.Time) {
+	if value := ctx.Get(fiber.HeaderRange); value != "" {
+		rangeHeader = &value
+	}
+
+	if value := ctx.Get(fiber.HeaderIfNoneMatch); value != "" {
+		ifNoneMatch = &value
+	}
+
+	if value := ctx.Get(fiber.HeaderIfModifiedSince); value != "" {
+		if modifiedSince, err := http.ParseTime(value); err == nil {
+			ifModifiedSince = &modifiedSince
+		}
+	}
+
+	return rangeHeader, ifNoneMatch, ifModifiedSince
+}
+
+func sendObjectContent(ctx *fiber.Ctx, objectContent *models.ObjectContent) error {
+	ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+	ctx.Set(fiber.HeaderETag, objectContent.ETag)
+	ctx.Set(fiber.HeaderLastModified, objectContent.LastModified.UTC().Format(http.TimeFormat))
+
+	if objectContent.NotModified {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx.Set(fiber.HeaderContentType, objectContent.MimeType)
+
+	if objectContent.ChecksumAlgorithm != nil && objectContent.Checksum != nil {
+		ctx.Set(objectChecksumHeader(*objectContent.ChecksumAlgorithm), *objectContent.Checksum)
+	}
+
+	status := fiber.StatusOK
+	if objectContent.ContentRange != nil {
+		status = fiber.StatusPartialContent
+		ctx.Set(fiber.HeaderContentRange, *objectContent.ContentRange)
+	}
+	ctx.Status(status)
+
+	if objectContent.Content == nil {
+		ctx.Response().SkipBody = true
+		ctx.Response().Header.SetContentLength(int(objectContent.ContentLength))
+		return nil
+	}
+
+	return ctx.SendStream(objectContent.Content, int(objectContent.ContentLength))
+}
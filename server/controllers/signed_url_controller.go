@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+
+	"github.com/driftdev/storage/server/models"
+	"github.com/driftdev/storage/server/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SignedUrlController struct {
+	signedUrlService *services.SignedUrlService
+}
+
+func NewSignedUrlController(signedUrlService *services.SignedUrlService) *SignedUrlController {
+	return &SignedUrlController{
+		signedUrlService: signedUrlService,
+	}
+}
+
+func (sc *SignedUrlController) RegisterSignedUrlRoutes(app *fiber.App) {
+	// signed routes are served without an api key, see middleware.KeyAuth
+	app.Put("/signed/:bucket_name/*", sc.UploadObject)
+	app.Get("/signed/:bucket_name/*", sc.DownloadObject)
+}
+
+// UploadObject is used to upload the content of an object to a pre-signed url of the filesystem storage backend
+// @Summary Upload to a pre-signed url
+// @Description Upload the content of an object or a part of a multipart upload to a pre-signed url. only served when
+// @Description objects are kept on the filesystem. the content type header and the content have to match the values the url was signed with
+// @Tags signed
+// @Accept octet-stream
+// @Param bucket_name path string true "Bucket Name"
+// @Param object_name path string true "Object Name"
+// @Param expires query int true "Expires At"
+// @Param signature query string true "Signature"
+// @Success 200
+// @Failure 400 {object} middleware.HttpError
+// @Failure 403 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /signed/{bucket_name}/{object_name} [put]
+func (sc *SignedUrlController) UploadObject(ctx *fiber.Ctx) error {
+	var signedObjectUpload models.SignedObjectUpload
+
+	err := parseSignedObjectRequest(ctx, &signedObjectUpload.SignedObjectRequest)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader = ctx.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.Body())
+	}
+
+	signedObjectUpload.MimeType = ctx.Get(fiber.HeaderContentType)
+	signedObjectUpload.Content = body
+
+	etag, err := sc.signedUrlService.UploadObject(ctx.Context(), &signedObjectUpload)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderETag, etag)
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// DownloadObject is used to download the content of an object from a pre-signed url of the filesystem storage backend
+// @Summary Download from a pre-signed url
+// @Description Download the content of an object from a pre-signed url. only served when objects are kept on the filesystem
+// @Tags signed
+// @Produce octet-stream
+// @Param bucket_name path string true "Bucket Name"
+// @Param object_name path string true "Object Name"
+// @Param expires query int true "Expires At"
+// @Param signature query string true "Signature"
+// @Param Range header string false "Byte Range"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Failure 403 {object} middleware.HttpError
+// @Failure 404 {object} middleware.HttpError
+// @Failure 416 {object} middleware.HttpError
+// @Failure 500 {object} middleware.HttpError
+// @Router /signed/{bucket_name}/{object_name} [get]
+func (sc *SignedUrlController) DownloadObject(ctx *fiber.Ctx) error {
+	var signedObjectDownload models.SignedObjectDownload
+
+	err := parseSignedObjectRequest(ctx, &signedObjectDownload.SignedObjectRequest)
+	if err != nil {
+		return err
+	}
+
+	if value := ctx.Get(fiber.HeaderRange); value != "" {
+		signedObjectDownload.Range = &value
+	}
+
+	objectContent, err := sc.signedUrlService.DownloadObject(ctx.Context(), &signedObjectDownload)
+	if err != nil {
+		return err
+	}
+
+	return sendObjectContent(ctx, objectContent)
+}
+
+func parseSignedObjectRequest(ctx *fiber.Ctx, signedObjectRequest *models.SignedObjectRequest) error {
+	objectName, err := url.PathUnescape(ctx.Params("*"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "object name is not a valid url path")
+	}
+
+	err = ctx.QueryParser(signedObjectRequest)
+	if err != nil {
+		return err
+	}
+
+	signedObjectRequest.BucketName = ctx.Params("bucket_name")
+	signedObjectRequest.ObjectName = objectName
+
+	return nil
+}
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"time"
 )
 
 const bucketCount = `-- name: BucketCount :one
@@ -23,11 +24,19 @@ func (q *Queries) BucketCount(ctx context.Context) (int64, error) {
 
 const bucketCreate = `-- name: BucketCreate :one
 insert into storage.buckets
-    (name, allowed_mime_types, max_allowed_object_size, public)
+    (name, allowed_mime_types, max_allowed_object_size, max_total_size, max_object_count, trash_retention_days, backend,
+     encryption, encryption_kms_key_id, encrypted, public)
 values ($1,
         $2,
         $3,
-        $4)
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $10,
+        $11)
 returning id
 `
 
@@ -35,6 +44,13 @@ type BucketCreateParams struct {
 	Name                 string
 	AllowedMimeTypes     []string
 	MaxAllowedObjectSize *int64
+	MaxTotalSize         *int64
+	MaxObjectCount       *int64
+	TrashRetentionDays   int32
+	Backend              string
+	Encryption           *string
+	EncryptionKmsKeyID   *string
+	Encrypted            bool
 	Public               bool
 }
 
@@ -43,6 +59,13 @@ func (q *Queries) BucketCreate(ctx context.Context, arg *BucketCreateParams) (st
 		arg.Name,
 		arg.AllowedMimeTypes,
 		arg.MaxAllowedObjectSize,
+		arg.MaxTotalSize,
+		arg.MaxObjectCount,
+		arg.TrashRetentionDays,
+		arg.Backend,
+		arg.Encryption,
+		arg.EncryptionKmsKeyID,
+		arg.Encrypted,
 		arg.Public,
 	)
 	var id string
@@ -89,6 +112,13 @@ select id,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -110,6 +140,13 @@ func (q *Queries) BucketGetById(ctx context.Context, id string) (*StorageBucket,
 		&i.Name,
 		&i.AllowedMimeTypes,
 		&i.MaxAllowedObjectSize,
+		&i.MaxTotalSize,
+		&i.MaxObjectCount,
+		&i.TrashRetentionDays,
+		&i.Backend,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.Encrypted,
 		&i.Public,
 		&i.Disabled,
 		&i.Locked,
@@ -127,6 +164,13 @@ select id,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -148,6 +192,13 @@ func (q *Queries) BucketGetByName(ctx context.Context, name string) (*StorageBuc
 		&i.Name,
 		&i.AllowedMimeTypes,
 		&i.MaxAllowedObjectSize,
+		&i.MaxTotalSize,
+		&i.MaxObjectCount,
+		&i.TrashRetentionDays,
+		&i.Backend,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.Encrypted,
 		&i.Public,
 		&i.Disabled,
 		&i.Locked,
@@ -199,12 +250,39 @@ func (q *Queries) BucketGetSizeById(ctx context.Context, id string) (*BucketGetS
 	return &i, err
 }
 
+const bucketGetUsageById = `-- name: BucketGetUsageById :one
+select count(1)::bigint               as object_count,
+       coalesce(sum(size), 0)::bigint as size
+from storage.objects
+where bucket_id = $1
+  and deleted_at is null
+`
+
+type BucketGetUsageByIdRow struct {
+	ObjectCount int64
+	Size        int64
+}
+
+func (q *Queries) BucketGetUsageById(ctx context.Context, id string) (*BucketGetUsageByIdRow, error) {
+	row := q.db.QueryRow(ctx, bucketGetUsageById, id)
+	var i BucketGetUsageByIdRow
+	err := row.Scan(&i.ObjectCount, &i.Size)
+	return &i, err
+}
+
 const bucketListAll = `-- name: BucketListAll :many
 select id,
        version,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -230,6 +308,13 @@ func (q *Queries) BucketListAll(ctx context.Context) ([]*StorageBucket, error) {
 			&i.Name,
 			&i.AllowedMimeTypes,
 			&i.MaxAllowedObjectSize,
+			&i.MaxTotalSize,
+			&i.MaxObjectCount,
+			&i.TrashRetentionDays,
+			&i.Backend,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.Encrypted,
 			&i.Public,
 			&i.Disabled,
 			&i.Locked,
@@ -254,6 +339,13 @@ select id,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -262,7 +354,8 @@ select id,
        created_at,
        updated_at
 from storage.buckets
-where id >= $1
+where id > $1
+order by id
 limit $2
 `
 
@@ -286,6 +379,84 @@ func (q *Queries) BucketListPaginated(ctx context.Context, arg *BucketListPagina
 			&i.Name,
 			&i.AllowedMimeTypes,
 			&i.MaxAllowedObjectSize,
+			&i.MaxTotalSize,
+			&i.MaxObjectCount,
+			&i.TrashRetentionDays,
+			&i.Backend,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.Encrypted,
+			&i.Public,
+			&i.Disabled,
+			&i.Locked,
+			&i.LockReason,
+			&i.LockedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const bucketListPaginatedPrevious = `-- name: BucketListPaginatedPrevious :many
+select id,
+       version,
+       name,
+       allowed_mime_types,
+       max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
+       public,
+       disabled,
+       locked,
+       lock_reason,
+       locked_at,
+       created_at,
+       updated_at
+from storage.buckets
+where id < $1
+order by id desc
+limit $2
+`
+
+type BucketListPaginatedPreviousParams struct {
+	Cursor string
+	Limit  int32
+}
+
+func (q *Queries) BucketListPaginatedPrevious(ctx context.Context, arg *BucketListPaginatedPreviousParams) ([]*StorageBucket, error) {
+	rows, err := q.db.Query(ctx, bucketListPaginatedPrevious, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*StorageBucket
+	for rows.Next() {
+		var i StorageBucket
+		if err := rows.Scan(
+			&i.ID,
+			&i.Version,
+			&i.Name,
+			&i.AllowedMimeTypes,
+			&i.MaxAllowedObjectSize,
+			&i.MaxTotalSize,
+			&i.MaxObjectCount,
+			&i.TrashRetentionDays,
+			&i.Backend,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.Encrypted,
 			&i.Public,
 			&i.Disabled,
 			&i.Locked,
@@ -322,38 +493,257 @@ func (q *Queries) BucketLock(ctx context.Context, arg *BucketLockParams) error {
 	return err
 }
 
-const bucketSearch = `-- name: BucketSearch :many
+const bucketLockQuotaById = `-- name: BucketLockQuotaById :exec
+select pg_advisory_xact_lock(hashtextextended($1::text, 0))
+`
+
+func (q *Queries) BucketLockQuotaById(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, bucketLockQuotaById, id)
+	return err
+}
+
+const bucketSearchPaginated = `-- name: BucketSearchPaginated :many
 select id,
        version,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
        lock_reason,
        locked_at,
        created_at,
-       updated_at
-from storage.buckets
-where name ilike '%' || $1::text || '%'
+       updated_at,
+       score
+from (select id,
+             version,
+             name,
+             allowed_mime_types,
+             max_allowed_object_size,
+             max_total_size,
+             max_object_count,
+             trash_retention_days,
+             backend,
+             encryption,
+             encryption_kms_key_id,
+             encrypted,
+             public,
+             disabled,
+             locked,
+             lock_reason,
+             locked_at,
+             created_at,
+             updated_at,
+             word_similarity($1::text, name) as score
+      from storage.buckets
+      where ($2::boolean and $1::text <% name)
+         or (not $2::boolean and name ilike $3::text)) as bucket
+where $4::text = ''
+   or score < $5::real
+   or (score = $5::real and id > $4::text)
+order by score desc, id
+limit $6
+`
+
+type BucketSearchPaginatedParams struct {
+	Name        string
+	Fuzzy       bool
+	Pattern     string
+	CursorID    string
+	CursorScore float32
+	Limit       int32
+}
+
+type BucketSearchPaginatedRow struct {
+	ID                   string
+	Version              int32
+	Name                 string
+	AllowedMimeTypes     []string
+	MaxAllowedObjectSize *int64
+	MaxTotalSize         *int64
+	MaxObjectCount       *int64
+	TrashRetentionDays   int32
+	Backend              string
+	Encryption           *string
+	EncryptionKmsKeyID   *string
+	Encrypted            bool
+	Public               bool
+	Disabled             bool
+	Locked               bool
+	LockReason           *string
+	LockedAt             *time.Time
+	CreatedAt            time.Time
+	UpdatedAt            *time.Time
+	Score                float32
+}
+
+func (q *Queries) BucketSearchPaginated(ctx context.Context, arg *BucketSearchPaginatedParams) ([]*BucketSearchPaginatedRow, error) {
+	rows, err := q.db.Query(ctx, bucketSearchPaginated,
+		arg.Name,
+		arg.Fuzzy,
+		arg.Pattern,
+		arg.CursorID,
+		arg.CursorScore,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*BucketSearchPaginatedRow
+	for rows.Next() {
+		var i BucketSearchPaginatedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Version,
+			&i.Name,
+			&i.AllowedMimeTypes,
+			&i.MaxAllowedObjectSize,
+			&i.MaxTotalSize,
+			&i.MaxObjectCount,
+			&i.TrashRetentionDays,
+			&i.Backend,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.Encrypted,
+			&i.Public,
+			&i.Disabled,
+			&i.Locked,
+			&i.LockReason,
+			&i.LockedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const bucketSearchPaginatedPrevious = `-- name: BucketSearchPaginatedPrevious :many
+select id,
+       version,
+       name,
+       allowed_mime_types,
+       max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
+       public,
+       disabled,
+       locked,
+       lock_reason,
+       locked_at,
+       created_at,
+       updated_at,
+       score
+from (select id,
+             version,
+             name,
+             allowed_mime_types,
+             max_allowed_object_size,
+             max_total_size,
+             max_object_count,
+             trash_retention_days,
+             backend,
+             encryption,
+             encryption_kms_key_id,
+             encrypted,
+             public,
+             disabled,
+             locked,
+             lock_reason,
+             locked_at,
+             created_at,
+             updated_at,
+             word_similarity($1::text, name) as score
+      from storage.buckets
+      where ($2::boolean and $1::text <% name)
+         or (not $2::boolean and name ilike $3::text)) as bucket
+where score > $4::real
+   or (score = $4::real and id < $5::text)
+order by score, id desc
+limit $6
 `
 
-func (q *Queries) BucketSearch(ctx context.Context, name string) ([]*StorageBucket, error) {
-	rows, err := q.db.Query(ctx, bucketSearch, name)
+type BucketSearchPaginatedPreviousParams struct {
+	Name        string
+	Fuzzy       bool
+	Pattern     string
+	CursorScore float32
+	CursorID    string
+	Limit       int32
+}
+
+type BucketSearchPaginatedPreviousRow struct {
+	ID                   string
+	Version              int32
+	Name                 string
+	AllowedMimeTypes     []string
+	MaxAllowedObjectSize *int64
+	MaxTotalSize         *int64
+	MaxObjectCount       *int64
+	TrashRetentionDays   int32
+	Backend              string
+	Encryption           *string
+	EncryptionKmsKeyID   *string
+	Encrypted            bool
+	Public               bool
+	Disabled             bool
+	Locked               bool
+	LockReason           *string
+	LockedAt             *time.Time
+	CreatedAt            time.Time
+	UpdatedAt            *time.Time
+	Score                float32
+}
+
+func (q *Queries) BucketSearchPaginatedPrevious(ctx context.Context, arg *BucketSearchPaginatedPreviousParams) ([]*BucketSearchPaginatedPreviousRow, error) {
+	rows, err := q.db.Query(ctx, bucketSearchPaginatedPrevious,
+		arg.Name,
+		arg.Fuzzy,
+		arg.Pattern,
+		arg.CursorScore,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []*StorageBucket
+	var items []*BucketSearchPaginatedPreviousRow
 	for rows.Next() {
-		var i StorageBucket
+		var i BucketSearchPaginatedPreviousRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Version,
 			&i.Name,
 			&i.AllowedMimeTypes,
 			&i.MaxAllowedObjectSize,
+			&i.MaxTotalSize,
+			&i.MaxObjectCount,
+			&i.TrashRetentionDays,
+			&i.Backend,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.Encrypted,
 			&i.Public,
 			&i.Disabled,
 			&i.Locked,
@@ -361,6 +751,7 @@ func (q *Queries) BucketSearch(ctx context.Context, name string) ([]*StorageBuck
 			&i.LockedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Score,
 		); err != nil {
 			return nil, err
 		}
@@ -387,25 +778,69 @@ func (q *Queries) BucketUnlock(ctx context.Context, id string) error {
 
 const bucketUpdate = `-- name: BucketUpdate :exec
 update storage.buckets
-set max_allowed_object_size = coalesce($1, max_allowed_object_size),
-    public                  = coalesce($2, public),
-    allowed_mime_types      = coalesce($3, allowed_mime_types)
-where id = $4
+set max_allowed_object_size = $1,
+    max_total_size          = $2,
+    max_object_count        = $3,
+    trash_retention_days    = coalesce($4, trash_retention_days),
+    public                  = coalesce($5, public),
+    allowed_mime_types      = coalesce($6, allowed_mime_types)
+where id = $7
 `
 
 type BucketUpdateParams struct {
 	MaxAllowedObjectSize *int64
+	MaxTotalSize         *int64
+	MaxObjectCount       *int64
+	TrashRetentionDays   *int32
 	Public               *bool
 	AllowedMimeTypes     []string
 	ID                   string
 }
 
+// the limits are set as given, so that null removes them. the update is merged with the bucket by the caller
 func (q *Queries) BucketUpdate(ctx context.Context, arg *BucketUpdateParams) error {
 	_, err := q.db.Exec(ctx, bucketUpdate,
 		arg.MaxAllowedObjectSize,
+		arg.MaxTotalSize,
+		arg.MaxObjectCount,
+		arg.TrashRetentionDays,
 		arg.Public,
 		arg.AllowedMimeTypes,
 		arg.ID,
 	)
 	return err
 }
+
+const bucketUpdateSettings = `-- name: BucketUpdateSettings :exec
+update storage.buckets
+set allowed_mime_types      = $1,
+    max_allowed_object_size = $2,
+    max_total_size          = $3,
+    max_object_count        = $4,
+    trash_retention_days    = $5,
+    public                  = $6
+where id = $7
+`
+
+type BucketUpdateSettingsParams struct {
+	AllowedMimeTypes     []string
+	MaxAllowedObjectSize *int64
+	MaxTotalSize         *int64
+	MaxObjectCount       *int64
+	TrashRetentionDays   int32
+	Public               bool
+	ID                   string
+}
+
+func (q *Queries) BucketUpdateSettings(ctx context.Context, arg *BucketUpdateSettingsParams) error {
+	_, err := q.db.Exec(ctx, bucketUpdateSettings,
+		arg.AllowedMimeTypes,
+		arg.MaxAllowedObjectSize,
+		arg.MaxTotalSize,
+		arg.MaxObjectCount,
+		arg.TrashRetentionDays,
+		arg.Public,
+		arg.ID,
+	)
+	return err
+}
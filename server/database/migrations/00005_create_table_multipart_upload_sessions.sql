@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create or replace function storage.on_multipart_upload_session_create()
+    returns trigger as
+$$
+begin
+    new.created_at = now();
+
+    return new;
+end;
+$$ language plpgsql;
+
+create table if not exists storage.multipart_upload_sessions
+(
+    object_id  text                      not null,
+    upload_id  text                      not null,
+    expires_at timestamptz               not null,
+    created_at timestamptz default now() not null,
+    constraint multipart_upload_sessions_object_id_primary_key primary key (object_id),
+    constraint multipart_upload_sessions_object_id_foreign_key foreign key (object_id) references storage.objects (id) on delete cascade,
+    constraint multipart_upload_sessions_upload_id_check check ( trim(upload_id) <> '' )
+);
+
+create or replace trigger multipart_upload_session_on_create
+    before insert
+    on storage.multipart_upload_sessions
+    for each row
+execute function storage.on_multipart_upload_session_create();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop trigger if exists multipart_upload_session_on_create on storage.multipart_upload_sessions;
+
+drop table if exists storage.multipart_upload_sessions;
+
+drop function if exists storage.on_multipart_upload_session_create;
+
+-- +goose StatementEnd
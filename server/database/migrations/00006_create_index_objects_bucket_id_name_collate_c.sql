@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- prefix listings compare names byte wise with the "C" collation so that every name starting with a prefix
+-- falls in a single contiguous range of this index, independent of the collation of the database
+create index if not exists objects_bucket_id_name_collate_c_index on storage.objects using btree (bucket_id, name collate "C");
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists storage.objects_bucket_id_name_collate_c_index;
+
+-- +goose StatementEnd
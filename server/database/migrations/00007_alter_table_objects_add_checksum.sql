@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- checksum is the base64 encoded digest of the object content declared by the client at upload,
+-- in the same encoding s3 uses for the x-amz-checksum-* and content-md5 headers
+alter table storage.objects
+    add column if not exists checksum_algorithm text null,
+    add column if not exists checksum           text null,
+    add constraint objects_checksum_algorithm_check check ( checksum_algorithm in ('sha256', 'crc32c', 'md5') ),
+    add constraint objects_checksum_check check ( (checksum_algorithm is null) = (checksum is null) );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.objects
+    drop constraint if exists objects_checksum_check,
+    drop constraint if exists objects_checksum_algorithm_check,
+    drop column if exists checksum,
+    drop column if exists checksum_algorithm;
+
+-- +goose StatementEnd
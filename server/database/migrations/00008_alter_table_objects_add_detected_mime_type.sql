@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- detected_mime_type is the mime type sniffed from the first bytes of the content once an upload completes.
+-- it can differ from the declared mime_type, which is what the object is served with
+alter table storage.objects
+    add column if not exists detected_mime_type text null;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.objects
+    drop column if exists detected_mime_type;
+
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- quotas count pending uploads as well, since their object rows reserve the declared size until the upload completes or expires
+alter table storage.buckets
+    add column if not exists max_total_size   bigint null,
+    add column if not exists max_object_count bigint null,
+    add constraint buckets_max_total_size_check check ( max_total_size is null or max_total_size > 0 ),
+    add constraint buckets_max_object_count_check check ( max_object_count is null or max_object_count > 0 );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.buckets
+    drop constraint if exists buckets_max_object_count_check,
+    drop constraint if exists buckets_max_total_size_check,
+    drop column if exists max_object_count,
+    drop column if exists max_total_size;
+
+-- +goose StatementEnd
@@ -0,0 +1,94 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create or replace function storage.on_bucket_lifecycle_rule_create()
+    returns trigger as
+$$
+begin
+    new.id = 'lifecycle_rule_' || storage.gen_random_ulid();
+    new.version = 0;
+    new.created_at = now();
+
+    return new;
+end;
+$$ language plpgsql;
+
+create or replace function storage.on_bucket_lifecycle_rule_update()
+    returns trigger as
+$$
+begin
+    new.version = new.version + 1;
+    new.updated_at = now();
+
+    return new;
+end;
+$$ language plpgsql;
+
+-- a rule matches the objects of its bucket whose name starts with `prefix` and whose metadata contains `metadata`.
+-- completed objects are expired after `expire_after_days` since creation or `expire_after_days_since_last_access`
+-- since the last access, and pending uploads are aborted after `abort_pending_upload_after_hours` since creation
+create table if not exists storage.bucket_lifecycle_rules
+(
+    id                                  text                      not null,
+    version                             int         default 0     not null,
+    bucket_id                           text                      not null,
+    name                                text                      not null,
+    enabled                             boolean     default true  not null,
+    prefix                              text                      null,
+    metadata                            jsonb                     null,
+    expire_after_days                   int                       null,
+    expire_after_days_since_last_access int                       null,
+    abort_pending_upload_after_hours    int                       null,
+    created_at                          timestamptz default now() not null,
+    updated_at                          timestamptz               null,
+    constraint bucket_lifecycle_rules_id_primary_key primary key (id),
+    constraint bucket_lifecycle_rules_bucket_id_foreign_key foreign key (bucket_id) references storage.buckets (id) on delete cascade,
+    constraint bucket_lifecycle_rules_id_version_unique unique (id, version),
+    constraint bucket_lifecycle_rules_name_unique unique (bucket_id, name),
+    constraint bucket_lifecycle_rules_id_check check ( trim(id) <> '' ),
+    constraint bucket_lifecycle_rules_version_check check ( version >= 0 ),
+    constraint bucket_lifecycle_rules_name_check check ( trim(name) <> '' ),
+    constraint bucket_lifecycle_rules_prefix_check check ( prefix is null or prefix <> '' ),
+    constraint bucket_lifecycle_rules_metadata_check check ( metadata is null or jsonb_typeof(metadata) = 'object' ),
+    constraint bucket_lifecycle_rules_expire_after_days_check check ( expire_after_days is null or expire_after_days > 0 ),
+    constraint bucket_lifecycle_rules_expire_after_days_since_last_access_check check ( expire_after_days_since_last_access is null or
+                                                                                        expire_after_days_since_last_access > 0 ),
+    constraint bucket_lifecycle_rules_abort_pending_upload_after_hours_check check ( abort_pending_upload_after_hours is null or
+                                                                                     abort_pending_upload_after_hours > 0 ),
+    constraint bucket_lifecycle_rules_action_check check ( expire_after_days is not null or
+                                                           expire_after_days_since_last_access is not null or
+                                                           abort_pending_upload_after_hours is not null )
+);
+
+create index if not exists bucket_lifecycle_rules_bucket_id_index on storage.bucket_lifecycle_rules using btree (bucket_id);
+
+create or replace trigger bucket_lifecycle_rule_on_create
+    before insert
+    on storage.bucket_lifecycle_rules
+    for each row
+execute function storage.on_bucket_lifecycle_rule_create();
+
+create or replace trigger bucket_lifecycle_rule_on_update
+    before update
+    on storage.bucket_lifecycle_rules
+    for each row
+execute function storage.on_bucket_lifecycle_rule_update();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop trigger if exists bucket_lifecycle_rule_on_update on storage.bucket_lifecycle_rules;
+
+drop trigger if exists bucket_lifecycle_rule_on_create on storage.bucket_lifecycle_rules;
+
+drop index if exists storage.bucket_lifecycle_rules_bucket_id_index;
+
+drop table if exists storage.bucket_lifecycle_rules;
+
+drop function if exists storage.on_bucket_lifecycle_rule_update();
+
+drop function if exists storage.on_bucket_lifecycle_rule_create();
+
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- deleted objects are kept in the trash of their bucket for `trash_retention_days` before they are purged.
+-- a retention of 0 days deletes objects right away
+alter table storage.buckets
+    add column if not exists trash_retention_days int default 7 not null,
+    add constraint buckets_trash_retention_days_check check ( trash_retention_days >= 0 );
+
+-- a trashed object moves its content to the trash of its bucket under its id and releases its name, so the name is only
+-- unique among the objects that are not in the trash
+alter table storage.objects
+    add column if not exists deleted_at timestamptz null,
+    drop constraint if exists objects_name_unique;
+
+create unique index if not exists objects_name_unique_index on storage.objects using btree (bucket_id, name) where deleted_at is null;
+
+create index if not exists objects_deleted_at_index on storage.objects using btree (deleted_at) where deleted_at is not null;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+delete
+from storage.objects
+where deleted_at is not null;
+
+drop index if exists storage.objects_deleted_at_index;
+
+drop index if exists storage.objects_name_unique_index;
+
+alter table storage.objects
+    add constraint objects_name_unique unique (bucket_id, name),
+    drop column if exists deleted_at;
+
+alter table storage.buckets
+    drop constraint if exists buckets_trash_retention_days_check,
+    drop column if exists trash_retention_days;
+
+-- +goose StatementEnd
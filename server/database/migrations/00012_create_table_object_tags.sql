@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- tags are key value pairs of an object that can be used to filter objects. keys are unique per object and the
+-- number of tags per object as well as the length of keys and values are limited by the service
+create table if not exists storage.object_tags
+(
+    object_id text not null,
+    key       text not null,
+    value     text not null,
+    constraint object_tags_primary_key primary key (object_id, key),
+    constraint object_tags_object_id_foreign_key foreign key (object_id) references storage.objects (id) on delete cascade,
+    constraint object_tags_key_check check ( key <> '' )
+);
+
+create index if not exists object_tags_key_value_index on storage.object_tags using btree (key, value);
+
+-- `tags` of a lifecycle rule only matches objects that have all of the tags
+alter table storage.bucket_lifecycle_rules
+    add column if not exists tags jsonb null,
+    add constraint bucket_lifecycle_rules_tags_check check ( tags is null or jsonb_typeof(tags) = 'object' );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.bucket_lifecycle_rules
+    drop constraint if exists bucket_lifecycle_rules_tags_check,
+    drop column if exists tags;
+
+drop index if exists storage.object_tags_key_value_index;
+
+drop table if exists storage.object_tags;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- serves the containment and key existence filters of object search. range filters cannot use it and are applied to
+-- the objects matched by the other filters
+create index if not exists objects_metadata_index on storage.objects using gin (metadata);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists storage.objects_metadata_index;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create extension if not exists pg_trgm;
+
+-- serve the prefix and contains searches with `ilike` and the fuzzy searches with `<%` on names, which would
+-- otherwise scan every object of a bucket
+create index if not exists objects_name_trgm_index on storage.objects using gin (name gin_trgm_ops);
+
+create index if not exists buckets_name_trgm_index on storage.buckets using gin (name gin_trgm_ops);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists storage.buckets_name_trgm_index;
+
+drop index if exists storage.objects_name_trgm_index;
+
+drop extension if exists pg_trgm;
+
+-- +goose StatementEnd
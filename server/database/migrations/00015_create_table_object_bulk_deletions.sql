@@ -0,0 +1,92 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create or replace function storage.on_object_bulk_deletion_create()
+    returns trigger as
+$$
+begin
+    new.id = 'bulk_deletion_' || storage.gen_random_ulid();
+    new.version = 0;
+    new.created_at = now();
+
+    return new;
+end;
+$$ language plpgsql;
+
+create or replace function storage.on_object_bulk_deletion_update()
+    returns trigger as
+$$
+begin
+    new.version = new.version + 1;
+    new.updated_at = now();
+
+    return new;
+end;
+$$ language plpgsql;
+
+-- a bulk deletion deletes the uploaded objects of a bucket selected by `object_ids`, or by a name `prefix` and the
+-- `tags` the objects must have, with a single job. the objects are moved to the trash of the bucket unless the bulk deletion is `permanent`. the counts and failures are updated after every batch so that the progress can be polled, and
+-- `last_object_id` lets a retried job resume after the last batch it processed
+create table if not exists storage.object_bulk_deletions
+(
+    id             text                            not null,
+    version        int         default 0           not null,
+    bucket_id      text                            not null,
+    object_ids     text[]                          null,
+    prefix         text                            null,
+    tags           jsonb                           null,
+    permanent      boolean     default false       not null,
+    status         text        default 'pending'   not null,
+    total_count    bigint      default 0           not null,
+    deleted_count  bigint      default 0           not null,
+    failed_count   bigint      default 0           not null,
+    failures       jsonb       default '[]'::jsonb not null,
+    last_object_id text                            null,
+    created_at     timestamptz default now()       not null,
+    updated_at     timestamptz                     null,
+    completed_at   timestamptz                     null,
+    constraint object_bulk_deletions_id_primary_key primary key (id),
+    constraint object_bulk_deletions_bucket_id_foreign_key foreign key (bucket_id) references storage.buckets (id) on delete cascade,
+    constraint object_bulk_deletions_id_version_unique unique (id, version),
+    constraint object_bulk_deletions_id_check check ( trim(id) <> '' ),
+    constraint object_bulk_deletions_version_check check ( version >= 0 ),
+    constraint object_bulk_deletions_selector_check check ( (object_ids is null) <> (prefix is null and tags is null) ),
+    constraint object_bulk_deletions_prefix_check check ( prefix is null or prefix <> '' ),
+    constraint object_bulk_deletions_tags_check check ( tags is null or jsonb_typeof(tags) = 'object' ),
+    constraint object_bulk_deletions_status_check check ( status in ('pending', 'running', 'completed') ),
+    constraint object_bulk_deletions_counts_check check ( total_count >= 0 and deleted_count >= 0 and failed_count >= 0 ),
+    constraint object_bulk_deletions_failures_check check ( jsonb_typeof(failures) = 'array' )
+);
+
+create index if not exists object_bulk_deletions_bucket_id_index on storage.object_bulk_deletions using btree (bucket_id);
+
+create or replace trigger object_bulk_deletion_on_create
+    before insert
+    on storage.object_bulk_deletions
+    for each row
+execute function storage.on_object_bulk_deletion_create();
+
+create or replace trigger object_bulk_deletion_on_update
+    before update
+    on storage.object_bulk_deletions
+    for each row
+execute function storage.on_object_bulk_deletion_update();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop trigger if exists object_bulk_deletion_on_update on storage.object_bulk_deletions;
+
+drop trigger if exists object_bulk_deletion_on_create on storage.object_bulk_deletions;
+
+drop index if exists storage.object_bulk_deletions_bucket_id_index;
+
+drop table if exists storage.object_bulk_deletions;
+
+drop function if exists storage.on_object_bulk_deletion_update();
+
+drop function if exists storage.on_object_bulk_deletion_create();
+
+-- +goose StatementEnd
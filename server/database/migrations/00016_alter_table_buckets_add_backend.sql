@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- `backend` is the name of the storage backend the objects of the bucket are kept in. backends are declared in the
+-- config, `default` is the backend configured by the top level storage settings. it is chosen when the bucket is
+-- created and cannot be changed afterwards since the objects of the bucket are not moved
+alter table storage.buckets
+    add column if not exists backend text default 'default' not null,
+    add constraint buckets_backend_check check ( backend <> '' );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.buckets
+    drop constraint if exists buckets_backend_check,
+    drop column if exists backend;
+
+-- +goose StatementEnd
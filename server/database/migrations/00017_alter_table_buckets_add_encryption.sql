@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- `encryption` is the server-side encryption applied to every object of the bucket, one of `sse-s3`, `sse-kms` or
+-- `sse-c`. null leaves objects to the default encryption of the s3 bucket. `encryption_kms_key_id` is the kms key of
+-- `sse-kms`, null uses the aws managed key. both are chosen when the bucket is created and cannot be changed afterwards
+-- since existing objects are not re-encrypted
+alter table storage.buckets
+    add column if not exists encryption            text null,
+    add column if not exists encryption_kms_key_id text null,
+    add constraint buckets_encryption_check check ( encryption in ('sse-s3', 'sse-kms', 'sse-c') ),
+    add constraint buckets_encryption_kms_key_id_check check ( encryption_kms_key_id is null or encryption = 'sse-kms' );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.buckets
+    drop constraint if exists buckets_encryption_kms_key_id_check,
+    drop constraint if exists buckets_encryption_check,
+    drop column if exists encryption_kms_key_id,
+    drop column if exists encryption;
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- `encryption` and `encryption_kms_key_id` record the server-side encryption the object was written with.
+-- `encryption_customer_key_md5` is the base64 encoded md5 digest of the `sse-c` customer key, the key itself is never
+-- stored and has to be sent with every request that reads the object
+alter table storage.objects
+    add column if not exists encryption                  text null,
+    add column if not exists encryption_kms_key_id       text null,
+    add column if not exists encryption_customer_key_md5 text null,
+    add constraint objects_encryption_check check ( encryption in ('sse-s3', 'sse-kms', 'sse-c') ),
+    add constraint objects_encryption_customer_key_md5_check check ( (encryption = 'sse-c') = (encryption_customer_key_md5 is not null) );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.objects
+    drop constraint if exists objects_encryption_customer_key_md5_check,
+    drop constraint if exists objects_encryption_check,
+    drop column if exists encryption_customer_key_md5,
+    drop column if exists encryption_kms_key_id,
+    drop column if exists encryption;
+
+-- +goose StatementEnd
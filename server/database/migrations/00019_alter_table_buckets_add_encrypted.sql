@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- `encrypted` buckets have the content of their objects encrypted by the service before it is written to the storage
+-- backend, independent of any server-side encryption of the backend. it is chosen when the bucket is created and
+-- cannot be changed afterwards since the objects of the bucket are not re-encrypted
+alter table storage.buckets
+    add column if not exists encrypted boolean default false not null;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+alter table storage.buckets
+    drop column if exists encrypted;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- `encryption_data_key` is the base64 encoded data key the content of an object of an encrypted bucket is encrypted
+-- with, wrapped with the master key `encryption_master_key_id` of the keyring. the data key is re-wrapped when the
+-- active master key is rotated
+alter table storage.objects
+    add column if not exists encryption_data_key      text null,
+    add column if not exists encryption_master_key_id text null,
+    add constraint objects_encryption_data_key_check check ( (encryption_data_key is null) = (encryption_master_key_id is null) );
+
+create index if not exists objects_encryption_master_key_id_index
+    on storage.objects (encryption_master_key_id, id)
+    where encryption_master_key_id is not null;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists storage.objects_encryption_master_key_id_index;
+
+alter table storage.objects
+    drop constraint if exists objects_encryption_data_key_check,
+    drop column if exists encryption_master_key_id,
+    drop column if exists encryption_data_key;
+
+-- +goose StatementEnd
@@ -14,6 +14,13 @@ type StorageBucket struct {
 	Name                 string
 	AllowedMimeTypes     []string
 	MaxAllowedObjectSize *int64
+	MaxTotalSize         *int64
+	MaxObjectCount       *int64
+	TrashRetentionDays   int32
+	Backend              string
+	Encryption           *string
+	EncryptionKmsKeyID   *string
+	Encrypted            bool
 	Public               bool
 	Disabled             bool
 	Locked               bool
@@ -23,16 +30,73 @@ type StorageBucket struct {
 	UpdatedAt            *time.Time
 }
 
+type StorageBucketLifecycleRule struct {
+	ID                             string
+	Version                        int32
+	BucketID                       string
+	Name                           string
+	Enabled                        bool
+	Prefix                         *string
+	Metadata                       []byte
+	ExpireAfterDays                *int32
+	ExpireAfterDaysSinceLastAccess *int32
+	AbortPendingUploadAfterHours   *int32
+	CreatedAt                      time.Time
+	UpdatedAt                      *time.Time
+	Tags                           []byte
+}
+
+type StorageMultipartUploadSession struct {
+	ObjectID  string
+	UploadID  string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
 type StorageObject struct {
-	ID             string
-	Version        int32
-	BucketID       string
-	Name           string
-	MimeType       string
-	Size           int64
-	Metadata       []byte
-	UploadStatus   string
-	LastAccessedAt *time.Time
-	CreatedAt      time.Time
-	UpdatedAt      *time.Time
+	ID                       string
+	Version                  int32
+	BucketID                 string
+	Name                     string
+	MimeType                 string
+	Size                     int64
+	Metadata                 []byte
+	ChecksumAlgorithm        *string
+	Checksum                 *string
+	DetectedMimeType         *string
+	UploadStatus             string
+	LastAccessedAt           *time.Time
+	CreatedAt                time.Time
+	UpdatedAt                *time.Time
+	DeletedAt                *time.Time
+	Encryption               *string
+	EncryptionKmsKeyID       *string
+	EncryptionCustomerKeyMd5 *string
+	EncryptionDataKey        *string
+	EncryptionMasterKeyID    *string
+}
+
+type StorageObjectBulkDeletion struct {
+	ID           string
+	Version      int32
+	BucketID     string
+	ObjectIds    []string
+	Prefix       *string
+	Tags         []byte
+	Permanent    bool
+	Status       string
+	TotalCount   int64
+	DeletedCount int64
+	FailedCount  int64
+	Failures     []byte
+	LastObjectID *string
+	CreatedAt    time.Time
+	UpdatedAt    *time.Time
+	CompletedAt  *time.Time
+}
+
+type StorageObjectTag struct {
+	ObjectID string
+	Key      string
+	Value    string
 }
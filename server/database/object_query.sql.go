@@ -10,25 +10,89 @@ import (
 	"time"
 )
 
+const objectCompleteUpload = `-- name: ObjectCompleteUpload :exec
+update storage.objects
+set upload_status      = 'completed',
+    detected_mime_type = $1
+where id = $2
+`
+
+type ObjectCompleteUploadParams struct {
+	DetectedMimeType *string
+	ID               string
+}
+
+func (q *Queries) ObjectCompleteUpload(ctx context.Context, arg *ObjectCompleteUploadParams) error {
+	_, err := q.db.Exec(ctx, objectCompleteUpload, arg.DetectedMimeType, arg.ID)
+	return err
+}
+
+const objectCountForBulkDeletion = `-- name: ObjectCountForBulkDeletion :one
+select count(1) as count
+from storage.objects
+where bucket_id = $1
+  and deleted_at is null
+  and upload_status = 'completed'
+  and ($2::text is null or starts_with(name, $2::text))
+  and ($3::jsonb is null or
+       not exists (select
+                   from jsonb_each_text($3::jsonb) as filter
+                   where not exists (select
+                                     from storage.object_tags as tag
+                                     where tag.object_id = objects.id
+                                       and tag.key = filter.key
+                                       and tag.value = filter.value)))
+`
+
+type ObjectCountForBulkDeletionParams struct {
+	BucketID string
+	Prefix   *string
+	Tags     []byte
+}
+
+func (q *Queries) ObjectCountForBulkDeletion(ctx context.Context, arg *ObjectCountForBulkDeletionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, objectCountForBulkDeletion, arg.BucketID, arg.Prefix, arg.Tags)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const objectCreate = `-- name: ObjectCreate :one
 insert into storage.objects
-    (bucket_id, name, mime_type, size, metadata, upload_status)
+    (bucket_id, name, mime_type, size, metadata, checksum_algorithm, checksum, detected_mime_type, upload_status,
+     encryption, encryption_kms_key_id, encryption_customer_key_md5, encryption_data_key, encryption_master_key_id)
 values ($1,
         $2,
         $3,
         $4,
         $5,
-        $6)
+        $6,
+        $7,
+        $8,
+        $9,
+        $10,
+        $11,
+        $12,
+        $13,
+        $14)
 returning id
 `
 
 type ObjectCreateParams struct {
-	BucketID     string
-	Name         string
-	ContentType  *string
-	Size         int64
-	Metadata     []byte
-	UploadStatus string
+	BucketID                 string
+	Name                     string
+	ContentType              *string
+	Size                     int64
+	Metadata                 []byte
+	ChecksumAlgorithm        *string
+	Checksum                 *string
+	DetectedMimeType         *string
+	UploadStatus             string
+	Encryption               *string
+	EncryptionKmsKeyID       *string
+	EncryptionCustomerKeyMd5 *string
+	EncryptionDataKey        *string
+	EncryptionMasterKeyID    *string
 }
 
 func (q *Queries) ObjectCreate(ctx context.Context, arg *ObjectCreateParams) (string, error) {
@@ -38,7 +102,15 @@ func (q *Queries) ObjectCreate(ctx context.Context, arg *ObjectCreateParams) (st
 		arg.ContentType,
 		arg.Size,
 		arg.Metadata,
+		arg.ChecksumAlgorithm,
+		arg.Checksum,
+		arg.DetectedMimeType,
 		arg.UploadStatus,
+		arg.Encryption,
+		arg.EncryptionKmsKeyID,
+		arg.EncryptionCustomerKeyMd5,
+		arg.EncryptionDataKey,
+		arg.EncryptionMasterKeyID,
 	)
 	var id string
 	err := row.Scan(&id)
@@ -56,6 +128,37 @@ func (q *Queries) ObjectDelete(ctx context.Context, id string) error {
 	return err
 }
 
+const objectDeleteMany = `-- name: ObjectDeleteMany :exec
+delete
+from storage.objects
+where id = any ($1::text[])
+`
+
+func (q *Queries) ObjectDeleteMany(ctx context.Context, ids []string) error {
+	_, err := q.db.Exec(ctx, objectDeleteMany, ids)
+	return err
+}
+
+const objectExistsByBucketIdAndName = `-- name: ObjectExistsByBucketIdAndName :one
+select exists(select
+              from storage.objects
+              where bucket_id = $1
+                and name = $2
+                and deleted_at is null) as exists
+`
+
+type ObjectExistsByBucketIdAndNameParams struct {
+	BucketID string
+	Name     string
+}
+
+func (q *Queries) ObjectExistsByBucketIdAndName(ctx context.Context, arg *ObjectExistsByBucketIdAndNameParams) (bool, error) {
+	row := q.db.QueryRow(ctx, objectExistsByBucketIdAndName, arg.BucketID, arg.Name)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const objectGetByBucketIdAndId = `-- name: ObjectGetByBucketIdAndId :one
 select id,
        version,
@@ -64,13 +167,23 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where bucket_id = $1
   and id = $2
+  and deleted_at is null
 limit 1
 `
 
@@ -90,10 +203,80 @@ func (q *Queries) ObjectGetByBucketIdAndId(ctx context.Context, arg *ObjectGetBy
 		&i.MimeType,
 		&i.Size,
 		&i.Metadata,
+		&i.ChecksumAlgorithm,
+		&i.Checksum,
+		&i.DetectedMimeType,
+		&i.UploadStatus,
+		&i.LastAccessedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.EncryptionCustomerKeyMd5,
+		&i.EncryptionDataKey,
+		&i.EncryptionMasterKeyID,
+	)
+	return &i, err
+}
+
+const objectGetByBucketIdAndName = `-- name: ObjectGetByBucketIdAndName :one
+select id,
+       version,
+       bucket_id,
+       name,
+       mime_type,
+       size,
+       metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
+       upload_status,
+       last_accessed_at,
+       created_at,
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where bucket_id = $1
+  and name = $2
+  and deleted_at is null
+limit 1
+`
+
+type ObjectGetByBucketIdAndNameParams struct {
+	BucketID string
+	Name     string
+}
+
+func (q *Queries) ObjectGetByBucketIdAndName(ctx context.Context, arg *ObjectGetByBucketIdAndNameParams) (*StorageObject, error) {
+	row := q.db.QueryRow(ctx, objectGetByBucketIdAndName, arg.BucketID, arg.Name)
+	var i StorageObject
+	err := row.Scan(
+		&i.ID,
+		&i.Version,
+		&i.BucketID,
+		&i.Name,
+		&i.MimeType,
+		&i.Size,
+		&i.Metadata,
+		&i.ChecksumAlgorithm,
+		&i.Checksum,
+		&i.DetectedMimeType,
 		&i.UploadStatus,
 		&i.LastAccessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.EncryptionCustomerKeyMd5,
+		&i.EncryptionDataKey,
+		&i.EncryptionMasterKeyID,
 	)
 	return &i, err
 }
@@ -106,12 +289,22 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where id = $1
+  and deleted_at is null
 limit 1
 `
 
@@ -126,10 +319,19 @@ func (q *Queries) ObjectGetById(ctx context.Context, id string) (*StorageObject,
 		&i.MimeType,
 		&i.Size,
 		&i.Metadata,
+		&i.ChecksumAlgorithm,
+		&i.Checksum,
+		&i.DetectedMimeType,
 		&i.UploadStatus,
 		&i.LastAccessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.EncryptionCustomerKeyMd5,
+		&i.EncryptionDataKey,
+		&i.EncryptionMasterKeyID,
 	)
 	return &i, err
 }
@@ -139,14 +341,24 @@ select object.id,
        object.version,
        object.bucket_id,
        bucket.name as bucket_name,
+       bucket.backend as bucket_backend,
        object.name,
        object.mime_type,
        object.size,
        object.metadata,
+       object.checksum_algorithm,
+       object.checksum,
+       object.detected_mime_type,
        object.upload_status,
        object.last_accessed_at,
        object.created_at,
-       object.updated_at
+       object.updated_at,
+       object.deleted_at,
+       object.encryption,
+       object.encryption_kms_key_id,
+       object.encryption_customer_key_md5,
+       object.encryption_data_key,
+       object.encryption_master_key_id
 from storage.objects as object
          inner join storage.buckets as bucket on object.bucket_id = bucket.id
 where object.id = $1
@@ -154,18 +366,28 @@ limit 1
 `
 
 type ObjectGetByIdWithBucketNameRow struct {
-	ID             string
-	Version        int32
-	BucketID       string
-	BucketName     string
-	Name           string
-	MimeType       string
-	Size           int64
-	Metadata       []byte
-	UploadStatus   string
-	LastAccessedAt *time.Time
-	CreatedAt      time.Time
-	UpdatedAt      *time.Time
+	ID                       string
+	Version                  int32
+	BucketID                 string
+	BucketName               string
+	BucketBackend            string
+	Name                     string
+	MimeType                 string
+	Size                     int64
+	Metadata                 []byte
+	ChecksumAlgorithm        *string
+	Checksum                 *string
+	DetectedMimeType         *string
+	UploadStatus             string
+	LastAccessedAt           *time.Time
+	CreatedAt                time.Time
+	UpdatedAt                *time.Time
+	DeletedAt                *time.Time
+	Encryption               *string
+	EncryptionKmsKeyID       *string
+	EncryptionCustomerKeyMd5 *string
+	EncryptionDataKey        *string
+	EncryptionMasterKeyID    *string
 }
 
 func (q *Queries) ObjectGetByIdWithBucketName(ctx context.Context, id string) (*ObjectGetByIdWithBucketNameRow, error) {
@@ -176,14 +398,24 @@ func (q *Queries) ObjectGetByIdWithBucketName(ctx context.Context, id string) (*
 		&i.Version,
 		&i.BucketID,
 		&i.BucketName,
+		&i.BucketBackend,
 		&i.Name,
 		&i.MimeType,
 		&i.Size,
 		&i.Metadata,
+		&i.ChecksumAlgorithm,
+		&i.Checksum,
+		&i.DetectedMimeType,
 		&i.UploadStatus,
 		&i.LastAccessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.EncryptionCustomerKeyMd5,
+		&i.EncryptionDataKey,
+		&i.EncryptionMasterKeyID,
 	)
 	return &i, err
 }
@@ -196,12 +428,22 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where name = $1
+  and deleted_at is null
 limit 1
 `
 
@@ -216,14 +458,665 @@ func (q *Queries) ObjectGetByName(ctx context.Context, name string) (*StorageObj
 		&i.MimeType,
 		&i.Size,
 		&i.Metadata,
+		&i.ChecksumAlgorithm,
+		&i.Checksum,
+		&i.DetectedMimeType,
+		&i.UploadStatus,
+		&i.LastAccessedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.EncryptionCustomerKeyMd5,
+		&i.EncryptionDataKey,
+		&i.EncryptionMasterKeyID,
+	)
+	return &i, err
+}
+
+const objectGetTrashedByBucketIdAndId = `-- name: ObjectGetTrashedByBucketIdAndId :one
+select id,
+       version,
+       bucket_id,
+       name,
+       mime_type,
+       size,
+       metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
+       upload_status,
+       last_accessed_at,
+       created_at,
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where bucket_id = $1
+  and id = $2
+  and deleted_at is not null
+limit 1
+`
+
+type ObjectGetTrashedByBucketIdAndIdParams struct {
+	BucketID string
+	ID       string
+}
+
+func (q *Queries) ObjectGetTrashedByBucketIdAndId(ctx context.Context, arg *ObjectGetTrashedByBucketIdAndIdParams) (*StorageObject, error) {
+	row := q.db.QueryRow(ctx, objectGetTrashedByBucketIdAndId, arg.BucketID, arg.ID)
+	var i StorageObject
+	err := row.Scan(
+		&i.ID,
+		&i.Version,
+		&i.BucketID,
+		&i.Name,
+		&i.MimeType,
+		&i.Size,
+		&i.Metadata,
+		&i.ChecksumAlgorithm,
+		&i.Checksum,
+		&i.DetectedMimeType,
 		&i.UploadStatus,
 		&i.LastAccessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Encryption,
+		&i.EncryptionKmsKeyID,
+		&i.EncryptionCustomerKeyMd5,
+		&i.EncryptionDataKey,
+		&i.EncryptionMasterKeyID,
 	)
 	return &i, err
 }
 
+const objectListByPrefix = `-- name: ObjectListByPrefix :many
+with recursive entry as ((
+    select case
+               when split.delimiter_position > 0
+                   then left(object.name, length($1::text) + split.delimiter_position +
+                                          length($2::text) - 1)
+               else object.name
+               end                      as key,
+           split.delimiter_position > 0 as is_common_prefix,
+           object.id,
+           object.version,
+           object.bucket_id,
+           object.name,
+           object.mime_type,
+           object.size,
+           object.metadata,
+           object.checksum_algorithm,
+           object.checksum,
+           object.detected_mime_type,
+           object.upload_status,
+           object.last_accessed_at,
+           object.created_at,
+           object.updated_at,
+           object.deleted_at,
+           object.encryption,
+           object.encryption_kms_key_id,
+           object.encryption_customer_key_md5,
+           object.encryption_data_key,
+           object.encryption_master_key_id
+    from storage.objects as object
+             cross join lateral (select case
+                                            when $2::text = '' then 0
+                                            else strpos(substr(object.name, length($1::text) + 1),
+                                                        $2::text)
+                                            end as delimiter_position) as split
+    where object.bucket_id = $3
+      and object.name collate "C" >= $1::text
+      and object.name collate "C" < $4::text
+      and object.name collate "C" > $5::text
+      and object.deleted_at is null
+      and (cardinality($6::text[]) = 0 or
+           (select count(*)
+            from storage.object_tags as tag
+                     join unnest($6::text[], $7::text[]) as filter (key, value)
+                          on tag.key = filter.key and tag.value = filter.value
+            where tag.object_id = object.id) = cardinality($6::text[]))
+    order by object.name collate "C"
+    limit 1)
+    union all
+    select next_entry.*
+    from entry
+             cross join lateral (select case
+                                            when split.delimiter_position > 0
+                                                then left(object.name, length($1::text) + split.delimiter_position +
+                                                                       length($2::text) - 1)
+                                            else object.name
+                                            end                      as key,
+                                        split.delimiter_position > 0 as is_common_prefix,
+                                        object.id,
+                                        object.version,
+                                        object.bucket_id,
+                                        object.name,
+                                        object.mime_type,
+                                        object.size,
+                                        object.metadata,
+                                        object.checksum_algorithm,
+                                        object.checksum,
+                                        object.detected_mime_type,
+                                        object.upload_status,
+                                        object.last_accessed_at,
+                                        object.created_at,
+                                        object.updated_at,
+                                        object.deleted_at,
+                                        object.encryption,
+                                        object.encryption_kms_key_id,
+                                        object.encryption_customer_key_md5,
+                                        object.encryption_data_key,
+                                        object.encryption_master_key_id
+                                 from storage.objects as object
+                                          cross join lateral (select case
+                                                                         when $2::text = '' then 0
+                                                                         else strpos(substr(object.name, length($1::text) + 1),
+                                                                                     $2::text)
+                                                                         end as delimiter_position) as split
+                                 where object.bucket_id = $3
+                                   and object.name collate "C" >= $1::text
+                                   and object.name collate "C" < $4::text
+                                   and object.name collate "C" > case
+                                                                 when entry.is_common_prefix
+                                                                     then entry.key || chr(1114111)
+                                                                 else entry.name
+                                                                 end
+                                   and object.deleted_at is null
+                                   and (cardinality($6::text[]) = 0 or
+                                        (select count(*)
+                                         from storage.object_tags as tag
+                                                  join unnest($6::text[], $7::text[]) as filter (key, value)
+                                                       on tag.key = filter.key and tag.value = filter.value
+                                         where tag.object_id = object.id) = cardinality($6::text[]))
+                                 order by object.name collate "C"
+                                 limit 1) as next_entry)
+select entry.key,
+       entry.is_common_prefix,
+       entry.id,
+       entry.version,
+       entry.bucket_id,
+       entry.name,
+       entry.mime_type,
+       entry.size,
+       entry.metadata,
+       entry.checksum_algorithm,
+       entry.checksum,
+       entry.detected_mime_type,
+       entry.upload_status,
+       entry.last_accessed_at,
+       entry.created_at,
+       entry.updated_at,
+       entry.deleted_at,
+       entry.encryption,
+       entry.encryption_kms_key_id,
+       entry.encryption_customer_key_md5,
+       entry.encryption_data_key,
+       entry.encryption_master_key_id
+from entry
+limit $8;
+`
+
+type ObjectListByPrefixParams struct {
+	Prefix     string
+	Delimiter  string
+	BucketID   string
+	PrefixEnd  string
+	StartAfter string
+	TagKeys    []string
+	TagValues  []string
+	Limit      int32
+}
+
+type ObjectListByPrefixRow struct {
+	Key                      string
+	IsCommonPrefix           bool
+	ID                       string
+	Version                  int32
+	BucketID                 string
+	Name                     string
+	MimeType                 string
+	Size                     int64
+	Metadata                 []byte
+	ChecksumAlgorithm        *string
+	Checksum                 *string
+	DetectedMimeType         *string
+	UploadStatus             string
+	LastAccessedAt           *time.Time
+	CreatedAt                time.Time
+	UpdatedAt                *time.Time
+	DeletedAt                *time.Time
+	Encryption               *string
+	EncryptionKmsKeyID       *string
+	EncryptionCustomerKeyMd5 *string
+	EncryptionDataKey        *string
+	EncryptionMasterKeyID    *string
+}
+
+// the entries are read with a recursive keyset scan that finds the next entry after the previous one in the
+// (bucket_id, name collate "C") index. after a common prefix the scan continues after the last name below it, so
+// a page reads one row per entry instead of every object below the listed common prefixes. an object whose name
+// continues with the delimiter after the prefix is always part of a common prefix, so objects and common prefixes
+// never share a key
+func (q *Queries) ObjectListByPrefix(ctx context.Context, arg *ObjectListByPrefixParams) ([]*ObjectListByPrefixRow, error) {
+	rows, err := q.db.Query(ctx, objectListByPrefix,
+		arg.Prefix,
+		arg.Delimiter,
+		arg.BucketID,
+		arg.PrefixEnd,
+		arg.StartAfter,
+		arg.TagKeys,
+		arg.TagValues,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ObjectListByPrefixRow
+	for rows.Next() {
+		var i ObjectListByPrefixRow
+		if err := rows.Scan(
+			&i.Key,
+			&i.IsCommonPrefix,
+			&i.ID,
+			&i.Version,
+			&i.BucketID,
+			&i.Name,
+			&i.MimeType,
+			&i.Size,
+			&i.Metadata,
+			&i.ChecksumAlgorithm,
+			&i.Checksum,
+			&i.DetectedMimeType,
+			&i.UploadStatus,
+			&i.LastAccessedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.EncryptionCustomerKeyMd5,
+			&i.EncryptionDataKey,
+			&i.EncryptionMasterKeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const objectListForBulkDeletion = `-- name: ObjectListForBulkDeletion :many
+select id,
+       name,
+       encryption,
+       encryption_kms_key_id
+from storage.objects
+where bucket_id = $1
+  and id > $2::text
+  and deleted_at is null
+  and upload_status = 'completed'
+  and ($3::text[] is null or id = any ($3::text[]))
+  and ($4::text is null or starts_with(name, $4::text))
+  and ($5::jsonb is null or
+       not exists (select
+                   from jsonb_each_text($5::jsonb) as filter
+                   where not exists (select
+                                     from storage.object_tags as tag
+                                     where tag.object_id = objects.id
+                                       and tag.key = filter.key
+                                       and tag.value = filter.value)))
+order by id
+limit $6
+`
+
+type ObjectListForBulkDeletionParams struct {
+	BucketID  string
+	Cursor    string
+	ObjectIds []string
+	Prefix    *string
+	Tags      []byte
+	Limit     int32
+}
+
+type ObjectListForBulkDeletionRow struct {
+	ID                 string
+	Name               string
+	Encryption         *string
+	EncryptionKmsKeyID *string
+}
+
+func (q *Queries) ObjectListForBulkDeletion(ctx context.Context, arg *ObjectListForBulkDeletionParams) ([]*ObjectListForBulkDeletionRow, error) {
+	rows, err := q.db.Query(ctx, objectListForBulkDeletion,
+		arg.BucketID,
+		arg.Cursor,
+		arg.ObjectIds,
+		arg.Prefix,
+		arg.Tags,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ObjectListForBulkDeletionRow
+	for rows.Next() {
+		var i ObjectListForBulkDeletionRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const objectListForDataKeyRewrap = `-- name: ObjectListForDataKeyRewrap :many
+select id,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where encryption_master_key_id <> $1::text
+  and id > $2::text
+order by id
+limit $3
+`
+
+type ObjectListForDataKeyRewrapParams struct {
+	MasterKeyID string
+	Cursor      string
+	Limit       int32
+}
+
+type ObjectListForDataKeyRewrapRow struct {
+	ID                    string
+	EncryptionDataKey     *string
+	EncryptionMasterKeyID *string
+}
+
+func (q *Queries) ObjectListForDataKeyRewrap(ctx context.Context, arg *ObjectListForDataKeyRewrapParams) ([]*ObjectListForDataKeyRewrapRow, error) {
+	rows, err := q.db.Query(ctx, objectListForDataKeyRewrap, arg.MasterKeyID, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*ObjectListForDataKeyRewrapRow
+	for rows.Next() {
+		var i ObjectListForDataKeyRewrapRow
+		if err := rows.Scan(&i.ID, &i.EncryptionDataKey, &i.EncryptionMasterKeyID); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const objectListIdsByBucketIdAndIds = `-- name: ObjectListIdsByBucketIdAndIds :many
+select id
+from storage.objects
+where bucket_id = $1
+  and id = any ($2::text[])
+  and deleted_at is null
+  and upload_status = 'completed'
+`
+
+type ObjectListIdsByBucketIdAndIdsParams struct {
+	BucketID string
+	Ids      []string
+}
+
+func (q *Queries) ObjectListIdsByBucketIdAndIds(ctx context.Context, arg *ObjectListIdsByBucketIdAndIdsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, objectListIdsByBucketIdAndIds, arg.BucketID, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const objectListIdsByLifecycleRule = `-- name: ObjectListIdsByLifecycleRule :many
+select id
+from storage.objects
+where bucket_id = $1
+  and id > $2::text
+  and deleted_at is null
+  and ($3::text is null or starts_with(name, $3::text))
+  and ($4::jsonb is null or metadata @> $4::jsonb)
+  and ($5::jsonb is null or
+       not exists (select
+                   from jsonb_each_text($5::jsonb) as filter
+                   where not exists (select
+                                     from storage.object_tags as tag
+                                     where tag.object_id = objects.id
+                                       and tag.key = filter.key
+                                       and tag.value = filter.value)))
+  and ((upload_status = 'completed' and
+        (created_at < now() - make_interval(days => $6::int) or
+         coalesce(last_accessed_at, created_at) <
+         now() - make_interval(days => $7::int))) or
+       (upload_status = 'pending' and
+        created_at < now() - make_interval(hours => $8::int)))
+order by id
+limit $9
+`
+
+type ObjectListIdsByLifecycleRuleParams struct {
+	BucketID                       string
+	Cursor                         string
+	Prefix                         *string
+	Metadata                       []byte
+	Tags                           []byte
+	ExpireAfterDays                *int32
+	ExpireAfterDaysSinceLastAccess *int32
+	AbortPendingUploadAfterHours   *int32
+	Limit                          int32
+}
+
+func (q *Queries) ObjectListIdsByLifecycleRule(ctx context.Context, arg *ObjectListIdsByLifecycleRuleParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, objectListIdsByLifecycleRule,
+		arg.BucketID,
+		arg.Cursor,
+		arg.Prefix,
+		arg.Metadata,
+		arg.Tags,
+		arg.ExpireAfterDays,
+		arg.ExpireAfterDaysSinceLastAccess,
+		arg.AbortPendingUploadAfterHours,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const objectListIdsExpiredInTrash = `-- name: ObjectListIdsExpiredInTrash :many
+select object.id
+from storage.objects as object
+         join storage.buckets as bucket on object.bucket_id = bucket.id
+where object.deleted_at < now() - make_interval(days => bucket.trash_retention_days)
+  and object.id > $1::text
+order by object.id
+limit $2
+`
+
+type ObjectListIdsExpiredInTrashParams struct {
+	Cursor string
+	Limit  int32
+}
+
+func (q *Queries) ObjectListIdsExpiredInTrash(ctx context.Context, arg *ObjectListIdsExpiredInTrashParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, objectListIdsExpiredInTrash, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const objectListTrashedByBucketId = `-- name: ObjectListTrashedByBucketId :many
+select id,
+       version,
+       bucket_id,
+       name,
+       mime_type,
+       size,
+       metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
+       upload_status,
+       last_accessed_at,
+       created_at,
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where bucket_id = $1
+  and deleted_at is not null
+order by deleted_at desc, id
+limit $2 offset $3
+`
+
+type ObjectListTrashedByBucketIdParams struct {
+	BucketID string
+	Limit    int32
+	Offset   int32
+}
+
+func (q *Queries) ObjectListTrashedByBucketId(ctx context.Context, arg *ObjectListTrashedByBucketIdParams) ([]*StorageObject, error) {
+	rows, err := q.db.Query(ctx, objectListTrashedByBucketId, arg.BucketID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*StorageObject
+	for rows.Next() {
+		var i StorageObject
+		if err := rows.Scan(
+			&i.ID,
+			&i.Version,
+			&i.BucketID,
+			&i.Name,
+			&i.MimeType,
+			&i.Size,
+			&i.Metadata,
+			&i.ChecksumAlgorithm,
+			&i.Checksum,
+			&i.DetectedMimeType,
+			&i.UploadStatus,
+			&i.LastAccessedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.EncryptionCustomerKeyMd5,
+			&i.EncryptionDataKey,
+			&i.EncryptionMasterKeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const objectRestore = `-- name: ObjectRestore :execrows
+update storage.objects
+set deleted_at    = null,
+    upload_status = 'pending'
+where id = $1
+  and deleted_at is not null
+`
+
+// the restored object is pending until its content has been copied back from the trash of its bucket
+func (q *Queries) ObjectRestore(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.Exec(ctx, objectRestore, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const objectReturnToTrash = `-- name: ObjectReturnToTrash :exec
+update storage.objects
+set deleted_at    = $1,
+    upload_status = 'completed'
+where id = $2
+`
+
+type ObjectReturnToTrashParams struct {
+	DeletedAt *time.Time
+	ID        string
+}
+
+func (q *Queries) ObjectReturnToTrash(ctx context.Context, arg *ObjectReturnToTrashParams) error {
+	_, err := q.db.Exec(ctx, objectReturnToTrash, arg.DeletedAt, arg.ID)
+	return err
+}
+
 const objectSearchByBucketIdAndObjectPath = `-- name: ObjectSearchByBucketIdAndObjectPath :many
 select object.id,
        object.version,
@@ -232,37 +1125,136 @@ select object.id,
        object.mime_type,
        object.size,
        object.metadata,
+       object.checksum_algorithm,
+       object.checksum,
+       object.detected_mime_type,
        object.upload_status,
        object.last_accessed_at,
        object.created_at,
-       object.updated_at
-from storage.objects as object
-where object.bucket_id = $1
-  and object.name ilike '%' || $2::text || '%'
-limit $4 offset $3
+       object.updated_at,
+       object.deleted_at,
+       object.encryption,
+       object.encryption_kms_key_id,
+       object.encryption_customer_key_md5,
+       object.encryption_data_key,
+       object.encryption_master_key_id,
+       object.score
+from (select object.id,
+             object.version,
+             object.bucket_id,
+             object.name,
+             object.mime_type,
+             object.size,
+             object.metadata,
+             object.checksum_algorithm,
+             object.checksum,
+             object.detected_mime_type,
+             object.upload_status,
+             object.last_accessed_at,
+             object.created_at,
+             object.updated_at,
+             object.deleted_at,
+             object.encryption,
+             object.encryption_kms_key_id,
+             object.encryption_customer_key_md5,
+             object.encryption_data_key,
+             object.encryption_master_key_id,
+             word_similarity($1::text, object.name) as score
+      from storage.objects as object
+      where object.bucket_id = $2
+        and (($3::boolean and $1::text <% object.name) or
+             (not $3::boolean and object.name ilike $4::text))
+        and object.deleted_at is null
+        and (cardinality($5::text[]) = 0 or
+             (select count(*)
+              from storage.object_tags as tag
+                       join unnest($5::text[], $6::text[]) as filter (key, value)
+                            on tag.key = filter.key and tag.value = filter.value
+              where tag.object_id = object.id) = cardinality($5::text[]))
+        and ($7::jsonb is null or object.metadata @> $7::jsonb)
+        and ($8::jsonb is null or object.metadata @> $8::jsonb)
+        and (cardinality($9::text[]) = 0 or object.metadata ?& $9::text[])
+        and ($10::jsonb is null or
+             not exists (select
+                         from jsonb_each($10::jsonb) as metadata_range
+                         where case
+                                   when jsonb_typeof(object.metadata -> metadata_range.key) = 'number' then
+                                       (object.metadata ->> metadata_range.key)::numeric <= (metadata_range.value ->> 'gt')::numeric or
+                                       (object.metadata ->> metadata_range.key)::numeric < (metadata_range.value ->> 'gte')::numeric or
+                                       (object.metadata ->> metadata_range.key)::numeric >= (metadata_range.value ->> 'lt')::numeric or
+                                       (object.metadata ->> metadata_range.key)::numeric > (metadata_range.value ->> 'lte')::numeric
+                                   else true
+                                   end))) as object
+where $11::text = ''
+   or object.score < $12::real
+   or (object.score = $12::real and object.id > $11::text)
+order by object.score desc, object.id
+limit $13
 `
 
 type ObjectSearchByBucketIdAndObjectPathParams struct {
-	BucketID   string
-	ObjectPath string
-	Offset     int32
-	Limit      int32
+	ObjectPath       string
+	BucketID         string
+	Fuzzy            bool
+	Pattern          string
+	TagKeys          []string
+	TagValues        []string
+	MetadataEquals   []byte
+	MetadataContains []byte
+	MetadataExists   []string
+	MetadataRanges   []byte
+	CursorID         string
+	CursorScore      float32
+	Limit            int32
+}
+
+type ObjectSearchByBucketIdAndObjectPathRow struct {
+	ID                       string
+	Version                  int32
+	BucketID                 string
+	Name                     string
+	MimeType                 string
+	Size                     int64
+	Metadata                 []byte
+	ChecksumAlgorithm        *string
+	Checksum                 *string
+	DetectedMimeType         *string
+	UploadStatus             string
+	LastAccessedAt           *time.Time
+	CreatedAt                time.Time
+	UpdatedAt                *time.Time
+	DeletedAt                *time.Time
+	Encryption               *string
+	EncryptionKmsKeyID       *string
+	EncryptionCustomerKeyMd5 *string
+	EncryptionDataKey        *string
+	EncryptionMasterKeyID    *string
+	Score                    float32
 }
 
-func (q *Queries) ObjectSearchByBucketIdAndObjectPath(ctx context.Context, arg *ObjectSearchByBucketIdAndObjectPathParams) ([]*StorageObject, error) {
+func (q *Queries) ObjectSearchByBucketIdAndObjectPath(ctx context.Context, arg *ObjectSearchByBucketIdAndObjectPathParams) ([]*ObjectSearchByBucketIdAndObjectPathRow, error) {
 	rows, err := q.db.Query(ctx, objectSearchByBucketIdAndObjectPath,
-		arg.BucketID,
 		arg.ObjectPath,
-		arg.Offset,
+		arg.BucketID,
+		arg.Fuzzy,
+		arg.Pattern,
+		arg.TagKeys,
+		arg.TagValues,
+		arg.MetadataEquals,
+		arg.MetadataContains,
+		arg.MetadataExists,
+		arg.MetadataRanges,
+		arg.CursorID,
+		arg.CursorScore,
 		arg.Limit,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []*StorageObject
+	var items []*ObjectSearchByBucketIdAndObjectPathRow
 	for rows.Next() {
-		var i StorageObject
+		var i ObjectSearchByBucketIdAndObjectPathRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Version,
@@ -271,10 +1263,20 @@ func (q *Queries) ObjectSearchByBucketIdAndObjectPath(ctx context.Context, arg *
 			&i.MimeType,
 			&i.Size,
 			&i.Metadata,
+			&i.ChecksumAlgorithm,
+			&i.Checksum,
+			&i.DetectedMimeType,
 			&i.UploadStatus,
 			&i.LastAccessedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.EncryptionCustomerKeyMd5,
+			&i.EncryptionDataKey,
+			&i.EncryptionMasterKeyID,
+			&i.Score,
 		); err != nil {
 			return nil, err
 		}
@@ -286,6 +1288,58 @@ func (q *Queries) ObjectSearchByBucketIdAndObjectPath(ctx context.Context, arg *
 	return items, nil
 }
 
+const objectTrash = `-- name: ObjectTrash :execrows
+update storage.objects
+set deleted_at = now()
+where id = $1
+  and bucket_id = $2
+  and name = $3
+  and deleted_at is null
+`
+
+type ObjectTrashParams struct {
+	ID       string
+	BucketID string
+	Name     string
+}
+
+// the bucket and name guard against the object having been renamed or moved since its content was copied to the trash
+func (q *Queries) ObjectTrash(ctx context.Context, arg *ObjectTrashParams) (int64, error) {
+	result, err := q.db.Exec(ctx, objectTrash, arg.ID, arg.BucketID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const objectTrashMany = `-- name: ObjectTrashMany :many
+update storage.objects
+set deleted_at = now()
+where id = any ($1::text[])
+  and deleted_at is null
+returning id
+`
+
+func (q *Queries) ObjectTrashMany(ctx context.Context, ids []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, objectTrashMany, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const objectUpdate = `-- name: ObjectUpdate :exec
 update storage.objects
 set size      = coalesce($1, size),
@@ -311,6 +1365,78 @@ func (q *Queries) ObjectUpdate(ctx context.Context, arg *ObjectUpdateParams) err
 	return err
 }
 
+const objectUpdateBucketIdAndName = `-- name: ObjectUpdateBucketIdAndName :execrows
+update storage.objects
+set bucket_id                   = $1,
+    name                        = $2,
+    encryption                  = $3,
+    encryption_kms_key_id       = $4,
+    encryption_customer_key_md5 = $5,
+    encryption_data_key         = $6,
+    encryption_master_key_id    = $7
+where id = $8
+  and bucket_id = $9
+  and name = $10
+  and deleted_at is null
+`
+
+type ObjectUpdateBucketIdAndNameParams struct {
+	BucketID                 string
+	Name                     string
+	Encryption               *string
+	EncryptionKmsKeyID       *string
+	EncryptionCustomerKeyMd5 *string
+	EncryptionDataKey        *string
+	EncryptionMasterKeyID    *string
+	ID                       string
+	SourceBucketID           string
+	SourceName               string
+}
+
+func (q *Queries) ObjectUpdateBucketIdAndName(ctx context.Context, arg *ObjectUpdateBucketIdAndNameParams) (int64, error) {
+	result, err := q.db.Exec(ctx, objectUpdateBucketIdAndName,
+		arg.BucketID,
+		arg.Name,
+		arg.Encryption,
+		arg.EncryptionKmsKeyID,
+		arg.EncryptionCustomerKeyMd5,
+		arg.EncryptionDataKey,
+		arg.EncryptionMasterKeyID,
+		arg.ID,
+		arg.SourceBucketID,
+		arg.SourceName,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const objectUpdateEncryptionDataKey = `-- name: ObjectUpdateEncryptionDataKey :exec
+update storage.objects
+set encryption_data_key      = $1::text,
+    encryption_master_key_id = $2::text
+where id = $3
+  and encryption_master_key_id = $4::text
+`
+
+type ObjectUpdateEncryptionDataKeyParams struct {
+	EncryptionDataKey     string
+	EncryptionMasterKeyID string
+	ID                    string
+	PreviousMasterKeyID   string
+}
+
+func (q *Queries) ObjectUpdateEncryptionDataKey(ctx context.Context, arg *ObjectUpdateEncryptionDataKeyParams) error {
+	_, err := q.db.Exec(ctx, objectUpdateEncryptionDataKey,
+		arg.EncryptionDataKey,
+		arg.EncryptionMasterKeyID,
+		arg.ID,
+		arg.PreviousMasterKeyID,
+	)
+	return err
+}
+
 const objectUpdateLastAccessedAt = `-- name: ObjectUpdateLastAccessedAt :exec
 update storage.objects
 set last_accessed_at = now()
@@ -345,10 +1471,19 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where bucket_id = $1
 limit $3 offset $2
@@ -361,16 +1496,25 @@ type ObjectsListBucketIdPagedParams struct {
 }
 
 type ObjectsListBucketIdPagedRow struct {
-	ID             string
-	BucketID       string
-	Name           string
-	MimeType       string
-	Size           int64
-	Metadata       []byte
-	UploadStatus   string
-	LastAccessedAt *time.Time
-	CreatedAt      time.Time
-	UpdatedAt      *time.Time
+	ID                       string
+	BucketID                 string
+	Name                     string
+	MimeType                 string
+	Size                     int64
+	Metadata                 []byte
+	ChecksumAlgorithm        *string
+	Checksum                 *string
+	DetectedMimeType         *string
+	UploadStatus             string
+	LastAccessedAt           *time.Time
+	CreatedAt                time.Time
+	UpdatedAt                *time.Time
+	DeletedAt                *time.Time
+	Encryption               *string
+	EncryptionKmsKeyID       *string
+	EncryptionCustomerKeyMd5 *string
+	EncryptionDataKey        *string
+	EncryptionMasterKeyID    *string
 }
 
 func (q *Queries) ObjectsListBucketIdPaged(ctx context.Context, arg *ObjectsListBucketIdPagedParams) ([]*ObjectsListBucketIdPagedRow, error) {
@@ -389,10 +1533,19 @@ func (q *Queries) ObjectsListBucketIdPaged(ctx context.Context, arg *ObjectsList
 			&i.MimeType,
 			&i.Size,
 			&i.Metadata,
+			&i.ChecksumAlgorithm,
+			&i.Checksum,
+			&i.DetectedMimeType,
 			&i.UploadStatus,
 			&i.LastAccessedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Encryption,
+			&i.EncryptionKmsKeyID,
+			&i.EncryptionCustomerKeyMd5,
+			&i.EncryptionDataKey,
+			&i.EncryptionMasterKeyID,
 		); err != nil {
 			return nil, err
 		}
@@ -18,20 +18,61 @@ type Querier interface {
 	BucketGetByName(ctx context.Context, name string) (*StorageBucket, error)
 	BucketGetObjectCountById(ctx context.Context, id string) (*BucketGetObjectCountByIdRow, error)
 	BucketGetSizeById(ctx context.Context, id string) (*BucketGetSizeByIdRow, error)
+	BucketGetUsageById(ctx context.Context, id string) (*BucketGetUsageByIdRow, error)
+	BucketLifecycleRuleCreate(ctx context.Context, arg *BucketLifecycleRuleCreateParams) (string, error)
+	BucketLifecycleRuleDelete(ctx context.Context, id string) error
+	BucketLifecycleRuleGetByBucketIdAndId(ctx context.Context, arg *BucketLifecycleRuleGetByBucketIdAndIdParams) (*StorageBucketLifecycleRule, error)
+	BucketLifecycleRuleListByBucketId(ctx context.Context, bucketID string) ([]*StorageBucketLifecycleRule, error)
+	BucketLifecycleRuleListEnabled(ctx context.Context) ([]*StorageBucketLifecycleRule, error)
+	BucketLifecycleRuleUpdate(ctx context.Context, arg *BucketLifecycleRuleUpdateParams) error
 	BucketListAll(ctx context.Context) ([]*StorageBucket, error)
 	BucketListPaginated(ctx context.Context, arg *BucketListPaginatedParams) ([]*StorageBucket, error)
+	BucketListPaginatedPrevious(ctx context.Context, arg *BucketListPaginatedPreviousParams) ([]*StorageBucket, error)
 	BucketLock(ctx context.Context, arg *BucketLockParams) error
-	BucketSearch(ctx context.Context, name string) ([]*StorageBucket, error)
+	BucketLockQuotaById(ctx context.Context, id string) error
+	BucketSearchPaginated(ctx context.Context, arg *BucketSearchPaginatedParams) ([]*BucketSearchPaginatedRow, error)
+	BucketSearchPaginatedPrevious(ctx context.Context, arg *BucketSearchPaginatedPreviousParams) ([]*BucketSearchPaginatedPreviousRow, error)
 	BucketUnlock(ctx context.Context, id string) error
 	BucketUpdate(ctx context.Context, arg *BucketUpdateParams) error
+	BucketUpdateSettings(ctx context.Context, arg *BucketUpdateSettingsParams) error
+	MultipartUploadSessionCreate(ctx context.Context, arg *MultipartUploadSessionCreateParams) error
+	MultipartUploadSessionDelete(ctx context.Context, objectID string) error
+	MultipartUploadSessionGetByObjectId(ctx context.Context, objectID string) (*StorageMultipartUploadSession, error)
+	ObjectBulkDeletionComplete(ctx context.Context, id string) error
+	ObjectBulkDeletionCreate(ctx context.Context, arg *ObjectBulkDeletionCreateParams) (string, error)
+	ObjectBulkDeletionGetByBucketIdAndId(ctx context.Context, arg *ObjectBulkDeletionGetByBucketIdAndIdParams) (*StorageObjectBulkDeletion, error)
+	ObjectBulkDeletionGetById(ctx context.Context, id string) (*StorageObjectBulkDeletion, error)
+	ObjectBulkDeletionStart(ctx context.Context, id string) error
+	ObjectBulkDeletionUpdateProgress(ctx context.Context, arg *ObjectBulkDeletionUpdateProgressParams) error
+	ObjectCompleteUpload(ctx context.Context, arg *ObjectCompleteUploadParams) error
+	ObjectCountForBulkDeletion(ctx context.Context, arg *ObjectCountForBulkDeletionParams) (int64, error)
 	ObjectCreate(ctx context.Context, arg *ObjectCreateParams) (string, error)
 	ObjectDelete(ctx context.Context, id string) error
+	ObjectDeleteMany(ctx context.Context, ids []string) error
+	ObjectExistsByBucketIdAndName(ctx context.Context, arg *ObjectExistsByBucketIdAndNameParams) (bool, error)
 	ObjectGetByBucketIdAndId(ctx context.Context, arg *ObjectGetByBucketIdAndIdParams) (*StorageObject, error)
+	ObjectGetByBucketIdAndName(ctx context.Context, arg *ObjectGetByBucketIdAndNameParams) (*StorageObject, error)
 	ObjectGetById(ctx context.Context, id string) (*StorageObject, error)
 	ObjectGetByIdWithBucketName(ctx context.Context, id string) (*ObjectGetByIdWithBucketNameRow, error)
 	ObjectGetByName(ctx context.Context, name string) (*StorageObject, error)
-	ObjectSearchByBucketIdAndObjectPath(ctx context.Context, arg *ObjectSearchByBucketIdAndObjectPathParams) ([]*StorageObject, error)
+	ObjectGetTrashedByBucketIdAndId(ctx context.Context, arg *ObjectGetTrashedByBucketIdAndIdParams) (*StorageObject, error)
+	ObjectListByPrefix(ctx context.Context, arg *ObjectListByPrefixParams) ([]*ObjectListByPrefixRow, error)
+	ObjectListForBulkDeletion(ctx context.Context, arg *ObjectListForBulkDeletionParams) ([]*ObjectListForBulkDeletionRow, error)
+	ObjectListIdsByBucketIdAndIds(ctx context.Context, arg *ObjectListIdsByBucketIdAndIdsParams) ([]string, error)
+	ObjectListIdsByLifecycleRule(ctx context.Context, arg *ObjectListIdsByLifecycleRuleParams) ([]string, error)
+	ObjectListIdsExpiredInTrash(ctx context.Context, arg *ObjectListIdsExpiredInTrashParams) ([]string, error)
+	ObjectListTrashedByBucketId(ctx context.Context, arg *ObjectListTrashedByBucketIdParams) ([]*StorageObject, error)
+	ObjectRestore(ctx context.Context, id string) (int64, error)
+	ObjectReturnToTrash(ctx context.Context, arg *ObjectReturnToTrashParams) error
+	ObjectSearchByBucketIdAndObjectPath(ctx context.Context, arg *ObjectSearchByBucketIdAndObjectPathParams) ([]*ObjectSearchByBucketIdAndObjectPathRow, error)
+	ObjectTagCopy(ctx context.Context, arg *ObjectTagCopyParams) error
+	ObjectTagCreateMany(ctx context.Context, arg *ObjectTagCreateManyParams) error
+	ObjectTagDeleteByObjectId(ctx context.Context, objectID string) error
+	ObjectTagListByObjectIds(ctx context.Context, objectIds []string) ([]*StorageObjectTag, error)
+	ObjectTrash(ctx context.Context, arg *ObjectTrashParams) (int64, error)
+	ObjectTrashMany(ctx context.Context, ids []string) ([]string, error)
 	ObjectUpdate(ctx context.Context, arg *ObjectUpdateParams) error
+	ObjectUpdateBucketIdAndName(ctx context.Context, arg *ObjectUpdateBucketIdAndNameParams) (int64, error)
 	ObjectUpdateLastAccessedAt(ctx context.Context, id string) error
 	ObjectUpdateUploadStatus(ctx context.Context, arg *ObjectUpdateUploadStatusParams) error
 	ObjectsListBucketIdPaged(ctx context.Context, arg *ObjectsListBucketIdPagedParams) ([]*ObjectsListBucketIdPagedRow, error)
@@ -0,0 +1,87 @@
+-- name: BucketLifecycleRuleCreate :one
+insert into storage.bucket_lifecycle_rules
+(bucket_id, name, enabled, prefix, metadata, expire_after_days, expire_after_days_since_last_access,
+ abort_pending_upload_after_hours, tags)
+values (sqlc.arg('bucket_id'),
+        sqlc.arg('name'),
+        sqlc.arg('enabled'),
+        sqlc.narg('prefix'),
+        sqlc.narg('metadata'),
+        sqlc.narg('expire_after_days'),
+        sqlc.narg('expire_after_days_since_last_access'),
+        sqlc.narg('abort_pending_upload_after_hours'),
+        sqlc.narg('tags'))
+returning id;
+
+-- name: BucketLifecycleRuleUpdate :exec
+update storage.bucket_lifecycle_rules
+set name                                = sqlc.arg('name'),
+    enabled                             = sqlc.arg('enabled'),
+    prefix                              = sqlc.narg('prefix'),
+    metadata                            = sqlc.narg('metadata'),
+    expire_after_days                   = sqlc.narg('expire_after_days'),
+    expire_after_days_since_last_access = sqlc.narg('expire_after_days_since_last_access'),
+    abort_pending_upload_after_hours    = sqlc.narg('abort_pending_upload_after_hours'),
+    tags                                = sqlc.narg('tags')
+where id = sqlc.arg('id');
+
+-- name: BucketLifecycleRuleDelete :exec
+delete
+from storage.bucket_lifecycle_rules
+where id = sqlc.arg('id');
+
+-- name: BucketLifecycleRuleGetByBucketIdAndId :one
+select id,
+       version,
+       bucket_id,
+       name,
+       enabled,
+       prefix,
+       metadata,
+       expire_after_days,
+       expire_after_days_since_last_access,
+       abort_pending_upload_after_hours,
+       created_at,
+       updated_at,
+       tags
+from storage.bucket_lifecycle_rules
+where bucket_id = sqlc.arg('bucket_id')
+  and id = sqlc.arg('id')
+limit 1;
+
+-- name: BucketLifecycleRuleListByBucketId :many
+select id,
+       version,
+       bucket_id,
+       name,
+       enabled,
+       prefix,
+       metadata,
+       expire_after_days,
+       expire_after_days_since_last_access,
+       abort_pending_upload_after_hours,
+       created_at,
+       updated_at,
+       tags
+from storage.bucket_lifecycle_rules
+where bucket_id = sqlc.arg('bucket_id')
+order by id;
+
+-- name: BucketLifecycleRuleListEnabled :many
+select id,
+       version,
+       bucket_id,
+       name,
+       enabled,
+       prefix,
+       metadata,
+       expire_after_days,
+       expire_after_days_since_last_access,
+       abort_pending_upload_after_hours,
+       created_at,
+       updated_at,
+       tags
+from storage.bucket_lifecycle_rules
+where enabled = true
+  and bucket_id in (select id from storage.buckets where locked = false)
+order by id;
@@ -1,19 +1,41 @@
 -- name: BucketCreate :one
 insert into storage.buckets
-    (name, allowed_mime_types, max_allowed_object_size, public)
+    (name, allowed_mime_types, max_allowed_object_size, max_total_size, max_object_count, trash_retention_days, backend,
+     encryption, encryption_kms_key_id, encrypted, public)
 values (sqlc.arg('name'),
         sqlc.narg('allowed_mime_types'),
         sqlc.narg('max_allowed_object_size'),
+        sqlc.narg('max_total_size'),
+        sqlc.narg('max_object_count'),
+        sqlc.arg('trash_retention_days'),
+        sqlc.arg('backend'),
+        sqlc.narg('encryption'),
+        sqlc.narg('encryption_kms_key_id'),
+        sqlc.arg('encrypted'),
         sqlc.arg('public'))
 returning id;
 
 -- name: BucketUpdate :exec
+-- the limits are set as given, so that null removes them. the update is merged with the bucket by the caller
 update storage.buckets
-set max_allowed_object_size = coalesce(sqlc.narg('max_allowed_object_size'), max_allowed_object_size),
+set max_allowed_object_size = sqlc.narg('max_allowed_object_size'),
+    max_total_size          = sqlc.narg('max_total_size'),
+    max_object_count        = sqlc.narg('max_object_count'),
+    trash_retention_days    = coalesce(sqlc.narg('trash_retention_days'), trash_retention_days),
     public                  = coalesce(sqlc.narg('public'), public),
     allowed_mime_types      = coalesce(sqlc.narg('allowed_mime_types'), allowed_mime_types)
 where id = sqlc.arg('id');
 
+-- name: BucketUpdateSettings :exec
+update storage.buckets
+set allowed_mime_types      = sqlc.arg('allowed_mime_types'),
+    max_allowed_object_size = sqlc.narg('max_allowed_object_size'),
+    max_total_size          = sqlc.narg('max_total_size'),
+    max_object_count        = sqlc.narg('max_object_count'),
+    trash_retention_days    = sqlc.arg('trash_retention_days'),
+    public                  = sqlc.arg('public')
+where id = sqlc.arg('id');
+
 -- name: BucketDisable :exec
 update storage.buckets
 set disabled = true
@@ -50,6 +72,13 @@ select id,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -67,6 +96,13 @@ select id,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -84,6 +120,13 @@ select id,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -99,6 +142,13 @@ select id,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -107,15 +157,23 @@ select id,
        created_at,
        updated_at
 from storage.buckets
-where id >= sqlc.arg('cursor')
+where id > sqlc.arg('cursor')
+order by id
 limit sqlc.arg('limit');
 
--- name: BucketSearch :many
+-- name: BucketListPaginatedPrevious :many
 select id,
        version,
        name,
        allowed_mime_types,
        max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
        public,
        disabled,
        locked,
@@ -124,7 +182,108 @@ select id,
        created_at,
        updated_at
 from storage.buckets
-where name ilike '%' || sqlc.arg('name')::text || '%';
+where id < sqlc.arg('cursor')
+order by id desc
+limit sqlc.arg('limit');
+
+-- name: BucketSearchPaginated :many
+select id,
+       version,
+       name,
+       allowed_mime_types,
+       max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
+       public,
+       disabled,
+       locked,
+       lock_reason,
+       locked_at,
+       created_at,
+       updated_at,
+       score
+from (select id,
+             version,
+             name,
+             allowed_mime_types,
+             max_allowed_object_size,
+             max_total_size,
+             max_object_count,
+             trash_retention_days,
+             backend,
+             encryption,
+             encryption_kms_key_id,
+             encrypted,
+             public,
+             disabled,
+             locked,
+             lock_reason,
+             locked_at,
+             created_at,
+             updated_at,
+             word_similarity(sqlc.arg('name')::text, name) as score
+      from storage.buckets
+      where (sqlc.arg('fuzzy')::boolean and sqlc.arg('name')::text <% name)
+         or (not sqlc.arg('fuzzy')::boolean and name ilike sqlc.arg('pattern')::text)) as bucket
+where sqlc.arg('cursor_id')::text = ''
+   or score < sqlc.arg('cursor_score')::real
+   or (score = sqlc.arg('cursor_score')::real and id > sqlc.arg('cursor_id')::text)
+order by score desc, id
+limit sqlc.arg('limit');
+
+-- name: BucketSearchPaginatedPrevious :many
+select id,
+       version,
+       name,
+       allowed_mime_types,
+       max_allowed_object_size,
+       max_total_size,
+       max_object_count,
+       trash_retention_days,
+       backend,
+       encryption,
+       encryption_kms_key_id,
+       encrypted,
+       public,
+       disabled,
+       locked,
+       lock_reason,
+       locked_at,
+       created_at,
+       updated_at,
+       score
+from (select id,
+             version,
+             name,
+             allowed_mime_types,
+             max_allowed_object_size,
+             max_total_size,
+             max_object_count,
+             trash_retention_days,
+             backend,
+             encryption,
+             encryption_kms_key_id,
+             encrypted,
+             public,
+             disabled,
+             locked,
+             lock_reason,
+             locked_at,
+             created_at,
+             updated_at,
+             word_similarity(sqlc.arg('name')::text, name) as score
+      from storage.buckets
+      where (sqlc.arg('fuzzy')::boolean and sqlc.arg('name')::text <% name)
+         or (not sqlc.arg('fuzzy')::boolean and name ilike sqlc.arg('pattern')::text)) as bucket
+where score > sqlc.arg('cursor_score')::real
+   or (score = sqlc.arg('cursor_score')::real and id < sqlc.arg('cursor_id')::text)
+order by score, id desc
+limit sqlc.arg('limit');
 
 -- name: BucketCount :one
 select count(1) as count
@@ -137,6 +296,16 @@ from storage.objects as object
 where object.bucket_id = sqlc.arg('id')
 group by object.bucket_id, bucket.name;
 
+-- name: BucketGetUsageById :one
+select count(1)::bigint               as object_count,
+       coalesce(sum(size), 0)::bigint as size
+from storage.objects
+where bucket_id = sqlc.arg('id')
+  and deleted_at is null;
+
+-- name: BucketLockQuotaById :exec
+select pg_advisory_xact_lock(hashtextextended(sqlc.arg('id')::text, 0));
+
 -- name: BucketGetObjectCountById :one
 select bucket_id as id, count(1) as count
 from storage.objects
@@ -0,0 +1,20 @@
+-- name: MultipartUploadSessionCreate :exec
+insert into storage.multipart_upload_sessions
+    (object_id, upload_id, expires_at)
+values (sqlc.arg('object_id'),
+        sqlc.arg('upload_id'),
+        sqlc.arg('expires_at'));
+
+-- name: MultipartUploadSessionGetByObjectId :one
+select object_id,
+       upload_id,
+       expires_at,
+       created_at
+from storage.multipart_upload_sessions
+where object_id = sqlc.arg('object_id')
+limit 1;
+
+-- name: MultipartUploadSessionDelete :exec
+delete
+from storage.multipart_upload_sessions
+where object_id = sqlc.arg('object_id');
@@ -0,0 +1,75 @@
+-- name: ObjectBulkDeletionCreate :one
+insert into storage.object_bulk_deletions
+    (bucket_id, object_ids, prefix, tags, permanent, total_count, failed_count, failures)
+values (sqlc.arg('bucket_id'),
+        sqlc.narg('object_ids'),
+        sqlc.narg('prefix'),
+        sqlc.narg('tags'),
+        sqlc.arg('permanent'),
+        sqlc.arg('total_count'),
+        sqlc.arg('failed_count'),
+        sqlc.arg('failures'))
+returning id;
+
+-- name: ObjectBulkDeletionGetById :one
+select id,
+       version,
+       bucket_id,
+       object_ids,
+       prefix,
+       tags,
+       permanent,
+       status,
+       total_count,
+       deleted_count,
+       failed_count,
+       failures,
+       last_object_id,
+       created_at,
+       updated_at,
+       completed_at
+from storage.object_bulk_deletions
+where id = sqlc.arg('id')
+limit 1;
+
+-- name: ObjectBulkDeletionGetByBucketIdAndId :one
+select id,
+       version,
+       bucket_id,
+       object_ids,
+       prefix,
+       tags,
+       permanent,
+       status,
+       total_count,
+       deleted_count,
+       failed_count,
+       failures,
+       last_object_id,
+       created_at,
+       updated_at,
+       completed_at
+from storage.object_bulk_deletions
+where bucket_id = sqlc.arg('bucket_id')
+  and id = sqlc.arg('id')
+limit 1;
+
+-- name: ObjectBulkDeletionStart :exec
+update storage.object_bulk_deletions
+set status = 'running'
+where id = sqlc.arg('id')
+  and status = 'pending';
+
+-- name: ObjectBulkDeletionUpdateProgress :exec
+update storage.object_bulk_deletions
+set deleted_count  = deleted_count + sqlc.arg('deleted_count')::bigint,
+    failed_count   = failed_count + sqlc.arg('failed_count')::bigint,
+    failures       = failures || sqlc.arg('failures')::jsonb,
+    last_object_id = sqlc.arg('last_object_id')
+where id = sqlc.arg('id');
+
+-- name: ObjectBulkDeletionComplete :exec
+update storage.object_bulk_deletions
+set status       = 'completed',
+    completed_at = now()
+where id = sqlc.arg('id');
@@ -1,12 +1,21 @@
 -- name: ObjectCreate :one
 insert into storage.objects
-    (bucket_id, name, mime_type, size, metadata, upload_status)
+    (bucket_id, name, mime_type, size, metadata, checksum_algorithm, checksum, detected_mime_type, upload_status,
+     encryption, encryption_kms_key_id, encryption_customer_key_md5, encryption_data_key, encryption_master_key_id)
 values (sqlc.arg('bucket_id'),
         sqlc.arg('name'),
         sqlc.narg('content_type'),
         sqlc.arg('size'),
         sqlc.arg('metadata'),
-        sqlc.arg('upload_status'))
+        sqlc.narg('checksum_algorithm'),
+        sqlc.narg('checksum'),
+        sqlc.narg('detected_mime_type'),
+        sqlc.arg('upload_status'),
+        sqlc.narg('encryption'),
+        sqlc.narg('encryption_kms_key_id'),
+        sqlc.narg('encryption_customer_key_md5'),
+        sqlc.narg('encryption_data_key'),
+        sqlc.narg('encryption_master_key_id'))
 returning id;
 
 -- name: ObjectUpdateUploadStatus :exec
@@ -14,6 +23,19 @@ update storage.objects
 set upload_status = sqlc.arg('upload_status')
 where id = sqlc.arg('id');
 
+-- name: ObjectCompleteUpload :exec
+update storage.objects
+set upload_status      = 'completed',
+    detected_mime_type = sqlc.narg('detected_mime_type')
+where id = sqlc.arg('id');
+
+-- name: ObjectUpdateEncryptionDataKey :exec
+update storage.objects
+set encryption_data_key      = sqlc.arg('encryption_data_key')::text,
+    encryption_master_key_id = sqlc.arg('encryption_master_key_id')::text
+where id = sqlc.arg('id')
+  and encryption_master_key_id = sqlc.arg('previous_master_key_id')::text;
+
 -- name: ObjectUpdateLastAccessedAt :exec
 update storage.objects
 set last_accessed_at = now()
@@ -26,11 +48,30 @@ set size      = coalesce(sqlc.narg('size'), size),
     metadata  = coalesce(sqlc.narg('metadata'), metadata)
 where id = sqlc.arg('id');
 
+-- name: ObjectUpdateBucketIdAndName :execrows
+update storage.objects
+set bucket_id                   = sqlc.arg('bucket_id'),
+    name                        = sqlc.arg('name'),
+    encryption                  = sqlc.narg('encryption'),
+    encryption_kms_key_id       = sqlc.narg('encryption_kms_key_id'),
+    encryption_customer_key_md5 = sqlc.narg('encryption_customer_key_md5'),
+    encryption_data_key         = sqlc.narg('encryption_data_key'),
+    encryption_master_key_id    = sqlc.narg('encryption_master_key_id')
+where id = sqlc.arg('id')
+  and bucket_id = sqlc.arg('source_bucket_id')
+  and name = sqlc.arg('source_name')
+  and deleted_at is null;
+
 -- name: ObjectDelete :exec
 delete
 from storage.objects
 where id = sqlc.arg('id');
 
+-- name: ObjectDeleteMany :exec
+delete
+from storage.objects
+where id = any (sqlc.arg('ids')::text[]);
+
 -- name: ObjectGetById :one
 select id,
        version,
@@ -39,12 +80,22 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where id = sqlc.arg('id')
+  and deleted_at is null
 limit 1;
 
 -- name: ObjectGetByIdWithBucketName :one
@@ -52,14 +103,24 @@ select object.id,
        object.version,
        object.bucket_id,
        bucket.name as bucket_name,
+       bucket.backend as bucket_backend,
        object.name,
        object.mime_type,
        object.size,
        object.metadata,
+       object.checksum_algorithm,
+       object.checksum,
+       object.detected_mime_type,
        object.upload_status,
        object.last_accessed_at,
        object.created_at,
-       object.updated_at
+       object.updated_at,
+       object.deleted_at,
+       object.encryption,
+       object.encryption_kms_key_id,
+       object.encryption_customer_key_md5,
+       object.encryption_data_key,
+       object.encryption_master_key_id
 from storage.objects as object
          inner join storage.buckets as bucket on object.bucket_id = bucket.id
 where object.id = sqlc.arg('id')
@@ -73,14 +134,58 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where name = sqlc.arg('name')
+  and deleted_at is null
+limit 1;
+
+-- name: ObjectGetByBucketIdAndName :one
+select id,
+       version,
+       bucket_id,
+       name,
+       mime_type,
+       size,
+       metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
+       upload_status,
+       last_accessed_at,
+       created_at,
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where bucket_id = sqlc.arg('bucket_id')
+  and name = sqlc.arg('name')
+  and deleted_at is null
 limit 1;
 
+-- name: ObjectExistsByBucketIdAndName :one
+select exists(select
+              from storage.objects
+              where bucket_id = sqlc.arg('bucket_id')
+                and name = sqlc.arg('name')
+                and deleted_at is null) as exists;
+
 -- name: ObjectGetByBucketIdAndId :one
 select id,
        version,
@@ -89,13 +194,23 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where bucket_id = sqlc.arg('bucket_id')
   and id = sqlc.arg('id')
+  and deleted_at is null
 limit 1;
 
 -- name: ObjectsListBucketIdPaged :many
@@ -105,10 +220,19 @@ select id,
        mime_type,
        size,
        metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
        upload_status,
        last_accessed_at,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
 from storage.objects
 where bucket_id = sqlc.arg('bucket_id')
 limit sqlc.arg('limit') offset sqlc.arg('offset');
@@ -121,11 +245,374 @@ select object.id,
        object.mime_type,
        object.size,
        object.metadata,
+       object.checksum_algorithm,
+       object.checksum,
+       object.detected_mime_type,
        object.upload_status,
        object.last_accessed_at,
        object.created_at,
-       object.updated_at
+       object.updated_at,
+       object.deleted_at,
+       object.encryption,
+       object.encryption_kms_key_id,
+       object.encryption_customer_key_md5,
+       object.encryption_data_key,
+       object.encryption_master_key_id,
+       object.score
+from (select object.id,
+             object.version,
+             object.bucket_id,
+             object.name,
+             object.mime_type,
+             object.size,
+             object.metadata,
+             object.checksum_algorithm,
+             object.checksum,
+             object.detected_mime_type,
+             object.upload_status,
+             object.last_accessed_at,
+             object.created_at,
+             object.updated_at,
+             object.deleted_at,
+             object.encryption,
+             object.encryption_kms_key_id,
+             object.encryption_customer_key_md5,
+             object.encryption_data_key,
+             object.encryption_master_key_id,
+             word_similarity(sqlc.arg('object_path')::text, object.name) as score
+      from storage.objects as object
+      where object.bucket_id = sqlc.arg('bucket_id')
+        and ((sqlc.arg('fuzzy')::boolean and sqlc.arg('object_path')::text <% object.name) or
+             (not sqlc.arg('fuzzy')::boolean and object.name ilike sqlc.arg('pattern')::text))
+        and object.deleted_at is null
+        and (cardinality(sqlc.arg('tag_keys')::text[]) = 0 or
+             (select count(*)
+              from storage.object_tags as tag
+                       join unnest(sqlc.arg('tag_keys')::text[], sqlc.arg('tag_values')::text[]) as filter (key, value)
+                            on tag.key = filter.key and tag.value = filter.value
+              where tag.object_id = object.id) = cardinality(sqlc.arg('tag_keys')::text[]))
+        and (sqlc.narg('metadata_equals')::jsonb is null or object.metadata @> sqlc.narg('metadata_equals')::jsonb)
+        and (sqlc.narg('metadata_contains')::jsonb is null or object.metadata @> sqlc.narg('metadata_contains')::jsonb)
+        and (cardinality(sqlc.arg('metadata_exists')::text[]) = 0 or object.metadata ?& sqlc.arg('metadata_exists')::text[])
+        and (sqlc.narg('metadata_ranges')::jsonb is null or
+             not exists (select
+                         from jsonb_each(sqlc.narg('metadata_ranges')::jsonb) as metadata_range
+                         where case
+                                   when jsonb_typeof(object.metadata -> metadata_range.key) = 'number' then
+                                       (object.metadata ->> metadata_range.key)::numeric <= (metadata_range.value ->> 'gt')::numeric or
+                                       (object.metadata ->> metadata_range.key)::numeric < (metadata_range.value ->> 'gte')::numeric or
+                                       (object.metadata ->> metadata_range.key)::numeric >= (metadata_range.value ->> 'lt')::numeric or
+                                       (object.metadata ->> metadata_range.key)::numeric > (metadata_range.value ->> 'lte')::numeric
+                                   else true
+                                   end))) as object
+where sqlc.arg('cursor_id')::text = ''
+   or object.score < sqlc.arg('cursor_score')::real
+   or (object.score = sqlc.arg('cursor_score')::real and object.id > sqlc.arg('cursor_id')::text)
+order by object.score desc, object.id
+limit sqlc.arg('limit');
+
+-- name: ObjectListByPrefix :many
+-- the entries are read with a recursive keyset scan that finds the next entry after the previous one in the
+-- (bucket_id, name collate "C") index. after a common prefix the scan continues after the last name below it, so
+-- a page reads one row per entry instead of every object below the listed common prefixes. an object whose name
+-- continues with the delimiter after the prefix is always part of a common prefix, so objects and common prefixes
+-- never share a key
+with recursive entry as ((
+    select case
+               when split.delimiter_position > 0
+                   then left(object.name, length(sqlc.arg('prefix')::text) + split.delimiter_position +
+                                          length(sqlc.arg('delimiter')::text) - 1)
+               else object.name
+               end                      as key,
+           split.delimiter_position > 0 as is_common_prefix,
+           object.id,
+           object.version,
+           object.bucket_id,
+           object.name,
+           object.mime_type,
+           object.size,
+           object.metadata,
+           object.checksum_algorithm,
+           object.checksum,
+           object.detected_mime_type,
+           object.upload_status,
+           object.last_accessed_at,
+           object.created_at,
+           object.updated_at,
+           object.deleted_at,
+           object.encryption,
+           object.encryption_kms_key_id,
+           object.encryption_customer_key_md5,
+           object.encryption_data_key,
+           object.encryption_master_key_id
+    from storage.objects as object
+             cross join lateral (select case
+                                            when sqlc.arg('delimiter')::text = '' then 0
+                                            else strpos(substr(object.name, length(sqlc.arg('prefix')::text) + 1),
+                                                        sqlc.arg('delimiter')::text)
+                                            end as delimiter_position) as split
+    where object.bucket_id = sqlc.arg('bucket_id')
+      and object.name collate "C" >= sqlc.arg('prefix')::text
+      and object.name collate "C" < sqlc.arg('prefix_end')::text
+      and object.name collate "C" > sqlc.arg('start_after')::text
+      and object.deleted_at is null
+      and (cardinality(sqlc.arg('tag_keys')::text[]) = 0 or
+           (select count(*)
+            from storage.object_tags as tag
+                     join unnest(sqlc.arg('tag_keys')::text[], sqlc.arg('tag_values')::text[]) as filter (key, value)
+                          on tag.key = filter.key and tag.value = filter.value
+            where tag.object_id = object.id) = cardinality(sqlc.arg('tag_keys')::text[]))
+    order by object.name collate "C"
+    limit 1)
+    union all
+    select next_entry.*
+    from entry
+             cross join lateral (select case
+                                            when split.delimiter_position > 0
+                                                then left(object.name, length(sqlc.arg('prefix')::text) + split.delimiter_position +
+                                                                       length(sqlc.arg('delimiter')::text) - 1)
+                                            else object.name
+                                            end                      as key,
+                                        split.delimiter_position > 0 as is_common_prefix,
+                                        object.id,
+                                        object.version,
+                                        object.bucket_id,
+                                        object.name,
+                                        object.mime_type,
+                                        object.size,
+                                        object.metadata,
+                                        object.checksum_algorithm,
+                                        object.checksum,
+                                        object.detected_mime_type,
+                                        object.upload_status,
+                                        object.last_accessed_at,
+                                        object.created_at,
+                                        object.updated_at,
+                                        object.deleted_at,
+                                        object.encryption,
+                                        object.encryption_kms_key_id,
+                                        object.encryption_customer_key_md5,
+                                        object.encryption_data_key,
+                                        object.encryption_master_key_id
+                                 from storage.objects as object
+                                          cross join lateral (select case
+                                                                         when sqlc.arg('delimiter')::text = '' then 0
+                                                                         else strpos(substr(object.name, length(sqlc.arg('prefix')::text) + 1),
+                                                                                     sqlc.arg('delimiter')::text)
+                                                                         end as delimiter_position) as split
+                                 where object.bucket_id = sqlc.arg('bucket_id')
+                                   and object.name collate "C" >= sqlc.arg('prefix')::text
+                                   and object.name collate "C" < sqlc.arg('prefix_end')::text
+                                   and object.name collate "C" > case
+                                                                 when entry.is_common_prefix
+                                                                     then entry.key || chr(1114111)
+                                                                 else entry.name
+                                                                 end
+                                   and object.deleted_at is null
+                                   and (cardinality(sqlc.arg('tag_keys')::text[]) = 0 or
+                                        (select count(*)
+                                         from storage.object_tags as tag
+                                                  join unnest(sqlc.arg('tag_keys')::text[], sqlc.arg('tag_values')::text[]) as filter (key, value)
+                                                       on tag.key = filter.key and tag.value = filter.value
+                                         where tag.object_id = object.id) = cardinality(sqlc.arg('tag_keys')::text[]))
+                                 order by object.name collate "C"
+                                 limit 1) as next_entry)
+select entry.key,
+       entry.is_common_prefix,
+       entry.id,
+       entry.version,
+       entry.bucket_id,
+       entry.name,
+       entry.mime_type,
+       entry.size,
+       entry.metadata,
+       entry.checksum_algorithm,
+       entry.checksum,
+       entry.detected_mime_type,
+       entry.upload_status,
+       entry.last_accessed_at,
+       entry.created_at,
+       entry.updated_at,
+       entry.deleted_at,
+       entry.encryption,
+       entry.encryption_kms_key_id,
+       entry.encryption_customer_key_md5,
+       entry.encryption_data_key,
+       entry.encryption_master_key_id
+from entry
+limit sqlc.arg('limit');
+
+-- name: ObjectListIdsByLifecycleRule :many
+select id
+from storage.objects
+where bucket_id = sqlc.arg('bucket_id')
+  and id > sqlc.arg('cursor')::text
+  and deleted_at is null
+  and (sqlc.narg('prefix')::text is null or starts_with(name, sqlc.narg('prefix')::text))
+  and (sqlc.narg('metadata')::jsonb is null or metadata @> sqlc.narg('metadata')::jsonb)
+  and (sqlc.narg('tags')::jsonb is null or
+       not exists (select
+                   from jsonb_each_text(sqlc.narg('tags')::jsonb) as filter
+                   where not exists (select
+                                     from storage.object_tags as tag
+                                     where tag.object_id = objects.id
+                                       and tag.key = filter.key
+                                       and tag.value = filter.value)))
+  and ((upload_status = 'completed' and
+        (created_at < now() - make_interval(days => sqlc.narg('expire_after_days')::int) or
+         coalesce(last_accessed_at, created_at) <
+         now() - make_interval(days => sqlc.narg('expire_after_days_since_last_access')::int))) or
+       (upload_status = 'pending' and
+        created_at < now() - make_interval(hours => sqlc.narg('abort_pending_upload_after_hours')::int)))
+order by id
+limit sqlc.arg('limit');
+
+-- name: ObjectTrash :execrows
+-- the bucket and name guard against the object having been renamed or moved since its content was copied to the trash
+update storage.objects
+set deleted_at = now()
+where id = sqlc.arg('id')
+  and bucket_id = sqlc.arg('bucket_id')
+  and name = sqlc.arg('name')
+  and deleted_at is null;
+
+-- name: ObjectTrashMany :many
+update storage.objects
+set deleted_at = now()
+where id = any (sqlc.arg('ids')::text[])
+  and deleted_at is null
+returning id;
+
+-- name: ObjectRestore :execrows
+-- the restored object is pending until its content has been copied back from the trash of its bucket
+update storage.objects
+set deleted_at    = null,
+    upload_status = 'pending'
+where id = sqlc.arg('id')
+  and deleted_at is not null;
+
+-- name: ObjectReturnToTrash :exec
+update storage.objects
+set deleted_at    = sqlc.arg('deleted_at'),
+    upload_status = 'completed'
+where id = sqlc.arg('id');
+
+-- name: ObjectGetTrashedByBucketIdAndId :one
+select id,
+       version,
+       bucket_id,
+       name,
+       mime_type,
+       size,
+       metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
+       upload_status,
+       last_accessed_at,
+       created_at,
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where bucket_id = sqlc.arg('bucket_id')
+  and id = sqlc.arg('id')
+  and deleted_at is not null
+limit 1;
+
+-- name: ObjectListTrashedByBucketId :many
+select id,
+       version,
+       bucket_id,
+       name,
+       mime_type,
+       size,
+       metadata,
+       checksum_algorithm,
+       checksum,
+       detected_mime_type,
+       upload_status,
+       last_accessed_at,
+       created_at,
+       updated_at,
+       deleted_at,
+       encryption,
+       encryption_kms_key_id,
+       encryption_customer_key_md5,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where bucket_id = sqlc.arg('bucket_id')
+  and deleted_at is not null
+order by deleted_at desc, id
+limit sqlc.arg('limit') offset sqlc.arg('offset');
+
+-- name: ObjectListIdsExpiredInTrash :many
+select object.id
 from storage.objects as object
-where object.bucket_id = sqlc.arg('bucket_id')
-  and object.name ilike '%' || sqlc.arg('object_path')::text || '%'
-limit sqlc.arg('limit') offset sqlc.arg('offset');
\ No newline at end of file
+         join storage.buckets as bucket on object.bucket_id = bucket.id
+where object.deleted_at < now() - make_interval(days => bucket.trash_retention_days)
+  and object.id > sqlc.arg('cursor')::text
+order by object.id
+limit sqlc.arg('limit');
+
+-- name: ObjectListIdsByBucketIdAndIds :many
+select id
+from storage.objects
+where bucket_id = sqlc.arg('bucket_id')
+  and id = any (sqlc.arg('ids')::text[])
+  and deleted_at is null
+  and upload_status = 'completed';
+
+-- name: ObjectCountForBulkDeletion :one
+select count(1) as count
+from storage.objects
+where bucket_id = sqlc.arg('bucket_id')
+  and deleted_at is null
+  and upload_status = 'completed'
+  and (sqlc.narg('prefix')::text is null or starts_with(name, sqlc.narg('prefix')::text))
+  and (sqlc.narg('tags')::jsonb is null or
+       not exists (select
+                   from jsonb_each_text(sqlc.narg('tags')::jsonb) as filter
+                   where not exists (select
+                                     from storage.object_tags as tag
+                                     where tag.object_id = objects.id
+                                       and tag.key = filter.key
+                                       and tag.value = filter.value)));
+
+-- name: ObjectListForBulkDeletion :many
+select id,
+       name,
+       encryption,
+       encryption_kms_key_id
+from storage.objects
+where bucket_id = sqlc.arg('bucket_id')
+  and id > sqlc.arg('cursor')::text
+  and deleted_at is null
+  and upload_status = 'completed'
+  and (sqlc.narg('object_ids')::text[] is null or id = any (sqlc.narg('object_ids')::text[]))
+  and (sqlc.narg('prefix')::text is null or starts_with(name, sqlc.narg('prefix')::text))
+  and (sqlc.narg('tags')::jsonb is null or
+       not exists (select
+                   from jsonb_each_text(sqlc.narg('tags')::jsonb) as filter
+                   where not exists (select
+                                     from storage.object_tags as tag
+                                     where tag.object_id = objects.id
+                                       and tag.key = filter.key
+                                       and tag.value = filter.value)))
+order by id
+limit sqlc.arg('limit');
+
+-- name: ObjectListForDataKeyRewrap :many
+select id,
+       encryption_data_key,
+       encryption_master_key_id
+from storage.objects
+where encryption_master_key_id <> sqlc.arg('master_key_id')::text
+  and id > sqlc.arg('cursor')::text
+order by id
+limit sqlc.arg('limit');
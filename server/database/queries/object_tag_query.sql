@@ -0,0 +1,28 @@
+-- name: ObjectTagCreateMany :exec
+insert into storage.object_tags
+    (object_id, key, value)
+select sqlc.arg('object_id'),
+       unnest(sqlc.arg('keys')::text[]),
+       unnest(sqlc.arg('values')::text[]);
+
+-- name: ObjectTagDeleteByObjectId :exec
+delete
+from storage.object_tags
+where object_id = sqlc.arg('object_id');
+
+-- name: ObjectTagCopy :exec
+insert into storage.object_tags
+    (object_id, key, value)
+select sqlc.arg('destination_object_id'),
+       key,
+       value
+from storage.object_tags
+where object_id = sqlc.arg('source_object_id');
+
+-- name: ObjectTagListByObjectIds :many
+select object_id,
+       key,
+       value
+from storage.object_tags
+where object_id = any (sqlc.arg('object_ids')::text[])
+order by object_id, key;
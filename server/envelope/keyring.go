@@ -0,0 +1,179 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/driftdev/storage/server/config"
+)
+
+// keySize is the size of the aes-256 master keys and data keys
+const keySize = 32
+
+var (
+	ErrKeyringNotConfigured = errors.New("encryption master key is not configured")
+	ErrMasterKeyNotFound    = errors.New("encryption master key not found")
+	ErrInvalidMasterKey     = errors.New("encryption master key must be a base64 encoded 256-bit key")
+	ErrInvalidDataKey       = errors.New("wrapped data key is not valid")
+)
+
+// keyringFile is the format of the local keyring file. `keys` holds every master key by id, data keys are wrapped
+// with the key `active_key_id` and keys that are no longer active are kept until their data keys have been re-wrapped
+type keyringFile struct {
+	ActiveKeyId string            `json:"active_key_id"`
+	Keys        map[string]string `json:"keys"`
+}
+
+// Keyring holds the master keys the data keys of objects of encrypted buckets are wrapped with. new data keys are
+// always wrapped with the active key, the other keys are only used to unwrap data keys until they are re-wrapped
+type Keyring struct {
+	activeKeyId string
+	keys        map[string]cipher.AEAD
+}
+
+// NewKeyring creates the keyring from the master key of the config and the keys of the keyring file. the active key
+// of the keyring file takes precedence over the master key of the config, so that a deployment can rotate away from
+// it. the keyring is empty when neither is configured
+func NewKeyring(cfg *config.Config) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]cipher.AEAD)}
+
+	if cfg.EncryptionMasterKey != "" {
+		if err := keyring.addKey(cfg.EncryptionMasterKeyId, cfg.EncryptionMasterKey); err != nil {
+			return nil, fmt.Errorf("encryption_master_key: %w", err)
+		}
+		keyring.activeKeyId = cfg.EncryptionMasterKeyId
+	}
+
+	if cfg.EncryptionKeyringFile != "" {
+		content, err := os.ReadFile(cfg.EncryptionKeyringFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption keyring file: %w", err)
+		}
+
+		var file keyringFile
+		if err = json.Unmarshal(content, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse encryption keyring file: %w", err)
+		}
+
+		for keyId, key := range file.Keys {
+			if err = keyring.addKey(keyId, key); err != nil {
+				return nil, fmt.Errorf("encryption keyring file key %s: %w", keyId, err)
+			}
+		}
+
+		if _, ok := keyring.keys[file.ActiveKeyId]; !ok {
+			return nil, fmt.Errorf("encryption keyring file active key %s: %w", file.ActiveKeyId, ErrMasterKeyNotFound)
+		}
+		keyring.activeKeyId = file.ActiveKeyId
+	}
+
+	return keyring, nil
+}
+
+func (k *Keyring) addKey(keyId string, key string) error {
+	if keyId == "" {
+		return errors.New("encryption master key id cannot be empty")
+	}
+
+	decodedKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decodedKey) != keySize {
+		return ErrInvalidMasterKey
+	}
+
+	aead, err := newAEAD(decodedKey)
+	if err != nil {
+		return err
+	}
+
+	k.keys[keyId] = aead
+	return nil
+}
+
+// Enabled reports whether a master key is configured, encrypted buckets can only be created when it is
+func (k *Keyring) Enabled() bool {
+	return k.activeKeyId != ""
+}
+
+// ActiveKeyId returns the id of the master key new data keys are wrapped with
+func (k *Keyring) ActiveKeyId() string {
+	return k.activeKeyId
+}
+
+// NewDataKey generates a data key for an object and returns it together with the data key wrapped with the active
+// master key and the id of the master key
+func (k *Keyring) NewDataKey() ([]byte, string, string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, "", "", err
+	}
+
+	wrappedDataKey, keyId, err := k.WrapDataKey(dataKey)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return dataKey, wrappedDataKey, keyId, nil
+}
+
+// WrapDataKey wraps a data key with the active master key. the id of the master key is authenticated together with
+// the data key so that a wrapped data key cannot be recorded against another master key
+func (k *Keyring) WrapDataKey(dataKey []byte) (string, string, error) {
+	aead, ok := k.keys[k.activeKeyId]
+	if !ok {
+		return "", "", ErrKeyringNotConfigured
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", err
+	}
+
+	wrappedDataKey := aead.Seal(nonce, nonce, dataKey, []byte(k.activeKeyId))
+
+	return base64.StdEncoding.EncodeToString(wrappedDataKey), k.activeKeyId, nil
+}
+
+// UnwrapDataKey unwraps a data key that was wrapped with the master key of the given id
+func (k *Keyring) UnwrapDataKey(wrappedDataKey string, keyId string) ([]byte, error) {
+	aead, ok := k.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMasterKeyNotFound, keyId)
+	}
+
+	decodedDataKey, err := base64.StdEncoding.DecodeString(wrappedDataKey)
+	if err != nil || len(decodedDataKey) < aead.NonceSize() {
+		return nil, ErrInvalidDataKey
+	}
+
+	nonceSize := aead.NonceSize()
+	dataKey, err := aead.Open(nil, decodedDataKey[:nonceSize], decodedDataKey[nonceSize:], []byte(keyId))
+	if err != nil {
+		return nil, ErrInvalidDataKey
+	}
+
+	return dataKey, nil
+}
+
+// RewrapDataKey unwraps a data key with the master key it was wrapped with and wraps it with the active master key
+func (k *Keyring) RewrapDataKey(wrappedDataKey string, keyId string) (string, string, error) {
+	dataKey, err := k.UnwrapDataKey(wrappedDataKey, keyId)
+	if err != nil {
+		return "", "", err
+	}
+
+	return k.WrapDataKey(dataKey)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,86 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testMasterKey        = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("m", keySize)))
+	testRotatedMasterKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("r", keySize)))
+)
+
+func writeKeyringFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestNewKeyring(t *testing.T) {
+	keyring, err := NewKeyring(&config.Config{})
+	require.NoError(t, err)
+	assert.False(t, keyring.Enabled())
+
+	_, _, _, err = keyring.NewDataKey()
+	assert.ErrorIs(t, err, ErrKeyringNotConfigured)
+
+	_, err = NewKeyring(&config.Config{EncryptionMasterKey: "c2hvcnQ=", EncryptionMasterKeyId: "default"})
+	assert.ErrorIs(t, err, ErrInvalidMasterKey)
+
+	_, err = NewKeyring(&config.Config{EncryptionKeyringFile: writeKeyringFile(t, `{"active_key_id": "2024-06", "keys": {"2024-01": "`+testMasterKey+`"}}`)})
+	assert.ErrorIs(t, err, ErrMasterKeyNotFound)
+
+	keyring, err = NewKeyring(&config.Config{
+		EncryptionMasterKey:   testMasterKey,
+		EncryptionMasterKeyId: "default",
+		EncryptionKeyringFile: writeKeyringFile(t, `{"active_key_id": "2024-06", "keys": {"2024-06": "`+testRotatedMasterKey+`"}}`),
+	})
+	require.NoError(t, err)
+	assert.True(t, keyring.Enabled())
+	assert.Equal(t, "2024-06", keyring.ActiveKeyId())
+}
+
+func TestKeyring_DataKeys(t *testing.T) {
+	keyring, err := NewKeyring(&config.Config{EncryptionMasterKey: testMasterKey, EncryptionMasterKeyId: "default"})
+	require.NoError(t, err)
+
+	dataKey, wrappedDataKey, keyId, err := keyring.NewDataKey()
+	require.NoError(t, err)
+	assert.Len(t, dataKey, keySize)
+	assert.Equal(t, "default", keyId)
+
+	unwrappedDataKey, err := keyring.UnwrapDataKey(wrappedDataKey, keyId)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrappedDataKey)
+
+	_, err = keyring.UnwrapDataKey(wrappedDataKey, "2024-06")
+	assert.ErrorIs(t, err, ErrMasterKeyNotFound)
+
+	_, err = keyring.UnwrapDataKey(base64.StdEncoding.EncodeToString([]byte("tampered data key")), keyId)
+	assert.ErrorIs(t, err, ErrInvalidDataKey)
+
+	rotatedKeyring, err := NewKeyring(&config.Config{
+		EncryptionMasterKey:   testMasterKey,
+		EncryptionMasterKeyId: "default",
+		EncryptionKeyringFile: writeKeyringFile(t, `{"active_key_id": "2024-06", "keys": {"2024-06": "`+testRotatedMasterKey+`"}}`),
+	})
+	require.NoError(t, err)
+
+	rewrappedDataKey, rewrappedKeyId, err := rotatedKeyring.RewrapDataKey(wrappedDataKey, keyId)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", rewrappedKeyId)
+
+	unwrappedDataKey, err = rotatedKeyring.UnwrapDataKey(rewrappedDataKey, rewrappedKeyId)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrappedDataKey)
+
+	_, err = rotatedKeyring.UnwrapDataKey(rewrappedDataKey, keyId)
+	assert.ErrorIs(t, err, ErrInvalidDataKey)
+}
@@ -0,0 +1,243 @@
+package envelope
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ChunkSize is the size of the plaintext chunks content is encrypted in. every chunk is sealed on its own so that
+// content can be encrypted and decrypted while it is streamed, and ranges can be decrypted without the whole content
+const ChunkSize = 64 * 1024
+
+// tagSize is the size of the authentication tag aes-gcm appends to every chunk
+const tagSize = 16
+
+// encryptedChunkSize is the size of a sealed chunk in the encrypted content
+const encryptedChunkSize = ChunkSize + tagSize
+
+var (
+	ErrInvalidCiphertext = errors.New("encrypted content is not valid")
+	ErrDataKeyReused     = errors.New("data key can not encrypt more than one content")
+)
+
+// Cipher encrypts and decrypts the content of an object with its data key.
+//
+// the content is split in chunks of ChunkSize bytes that are sealed with aes-gcm. the nonce of a chunk is its index
+// and a flag marking the final chunk, which is only unique as long as a data key encrypts a single content. copies
+// between encrypted buckets share the data key and the encrypted content of the object they were copied from, so
+// only the cipher of a newly generated data key can encrypt, and only once. the index prevents chunks from being
+// reordered and the flag prevents the content from being truncated at a chunk boundary
+type Cipher struct {
+	aead cipher.AEAD
+	// encryptable is set for the cipher of a new data key until it has encrypted content
+	encryptable atomic.Bool
+}
+
+// NewCipher creates the cipher of the data key of existing content. it only decrypts, since the data key can be
+// shared with copies of the content
+func NewCipher(dataKey []byte) (*Cipher, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// NewEncryptingCipher creates the cipher of a newly generated data key, which can encrypt one content
+func NewEncryptingCipher(dataKey []byte) (*Cipher, error) {
+	c, err := NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	c.encryptable.Store(true)
+	return c, nil
+}
+
+// EncryptedSize returns the size of the encrypted content of the given plaintext size. empty content is encrypted
+// as a single empty chunk
+func EncryptedSize(size int64) int64 {
+	return size + chunkCount(size)*tagSize
+}
+
+// PlaintextSize returns the plaintext size of encrypted content of the given size
+func PlaintextSize(encryptedSize int64) (int64, error) {
+	chunks := (encryptedSize + encryptedChunkSize - 1) / encryptedChunkSize
+	if chunks == 0 || encryptedSize-chunks*tagSize < 0 {
+		return 0, ErrInvalidCiphertext
+	}
+	return encryptedSize - chunks*tagSize, nil
+}
+
+// EncryptedRange returns the byte range of the encrypted content that holds the chunks of the plaintext range
+// from start to end inclusive, which has to be read to decrypt it
+func EncryptedRange(size int64, start int64, end int64) (int64, int64) {
+	firstChunk := start / ChunkSize
+	lastChunk := end / ChunkSize
+
+	return firstChunk * encryptedChunkSize, min((lastChunk+1)*encryptedChunkSize, EncryptedSize(size)) - 1
+}
+
+// EncryptReader returns a reader of the encrypted content of the plaintext source. errors of the source are
+// returned as they are. reading fails with ErrDataKeyReused when the cipher is not the cipher of a new data key or
+// has already encrypted content
+func (c *Cipher) EncryptReader(source io.Reader) io.Reader {
+	if !c.encryptable.CompareAndSwap(true, false) {
+		return &encryptReader{err: ErrDataKeyReused}
+	}
+	return &encryptReader{
+		aead:   c.aead,
+		source: source,
+		chunk:  make([]byte, ChunkSize+1),
+	}
+}
+
+// DecryptReader returns a reader of the plaintext range from start to end inclusive of content of the given
+// plaintext size. the source is the encrypted range returned by EncryptedRange
+func (c *Cipher) DecryptReader(source io.Reader, size int64, start int64, end int64) io.Reader {
+	return &decryptReader{
+		aead:      c.aead,
+		source:    source,
+		index:     uint64(start / ChunkSize),
+		lastIndex: uint64(chunkCount(size) - 1),
+		skip:      start % ChunkSize,
+		remaining: end - start + 1,
+		chunk:     make([]byte, encryptedChunkSize),
+	}
+}
+
+type encryptReader struct {
+	aead   cipher.AEAD
+	source io.Reader
+	// chunk holds a chunk and the first byte of the next one, which is read ahead to know whether a chunk is final
+	chunk     []byte
+	readAhead []byte
+	sealed    []byte
+	out       []byte
+	index     uint64
+	done      bool
+	err       error
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) sealChunk() error {
+	carried := copy(r.chunk, r.readAhead)
+
+	n, err := io.ReadFull(r.source, r.chunk[carried:])
+	n += carried
+
+	final := false
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+		r.readAhead = nil
+	case err != nil:
+		return err
+	default:
+		r.readAhead = []byte{r.chunk[ChunkSize]}
+		n = ChunkSize
+	}
+
+	r.sealed = r.aead.Seal(r.sealed[:0], chunkNonce(r.index, final), r.chunk[:n], nil)
+	r.out = r.sealed
+	r.index++
+	r.done = final
+
+	return nil
+}
+
+type decryptReader struct {
+	aead      cipher.AEAD
+	source    io.Reader
+	index     uint64
+	lastIndex uint64
+	skip      int64
+	remaining int64
+	chunk     []byte
+	out       []byte
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.remaining <= 0 {
+			return 0, io.EOF
+		}
+		if err := r.openChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) openChunk() error {
+	if r.index > r.lastIndex {
+		return ErrInvalidCiphertext
+	}
+
+	final := r.index == r.lastIndex
+
+	n, err := io.ReadFull(r.source, r.chunk)
+	if err != nil && !(final && errors.Is(err, io.ErrUnexpectedEOF)) {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrInvalidCiphertext
+		}
+		return err
+	}
+
+	plaintext, err := r.aead.Open(r.chunk[:0], chunkNonce(r.index, final), r.chunk[:n], nil)
+	if err != nil {
+		return ErrInvalidCiphertext
+	}
+	r.index++
+
+	if r.skip > int64(len(plaintext)) {
+		return ErrInvalidCiphertext
+	}
+	plaintext = plaintext[r.skip:]
+	r.skip = 0
+
+	if int64(len(plaintext)) > r.remaining {
+		plaintext = plaintext[:r.remaining]
+	}
+	r.remaining -= int64(len(plaintext))
+	r.out = plaintext
+
+	return nil
+}
+
+// chunkCount returns the number of chunks content of the given plaintext size is encrypted in
+func chunkCount(size int64) int64 {
+	return max(1, (size+ChunkSize-1)/ChunkSize)
+}
+
+// chunkNonce returns the nonce of the chunk at the given index
+func chunkNonce(index uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, index)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
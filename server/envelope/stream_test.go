@@ -0,0 +1,115 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCipher(t *testing.T) *Cipher {
+	dataKey := make([]byte, keySize)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+
+	c, err := NewCipher(dataKey)
+	require.NoError(t, err)
+	return c
+}
+
+func encrypt(t *testing.T, c *Cipher, plaintext []byte) []byte {
+	// the test cipher only decrypts, the content is encrypted with an encrypting cipher of the same data key
+	encryptingCipher := &Cipher{aead: c.aead}
+	encryptingCipher.encryptable.Store(true)
+
+	// one byte reads make sure that chunks do not depend on how the source is read
+	encrypted, err := io.ReadAll(encryptingCipher.EncryptReader(iotest.OneByteReader(bytes.NewReader(plaintext))))
+	require.NoError(t, err)
+	return encrypted
+}
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	c := newTestCipher(t)
+
+	for _, size := range []int64{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 100} {
+		plaintext := make([]byte, size)
+		_, err := rand.Read(plaintext)
+		require.NoError(t, err)
+
+		encrypted := encrypt(t, c, plaintext)
+		assert.Equal(t, EncryptedSize(size), int64(len(encrypted)))
+
+		plaintextSize, err := PlaintextSize(int64(len(encrypted)))
+		require.NoError(t, err)
+		assert.Equal(t, size, plaintextSize)
+
+		decrypted, err := io.ReadAll(c.DecryptReader(bytes.NewReader(encrypted), size, 0, size-1))
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestCipher_DecryptRange(t *testing.T) {
+	c := newTestCipher(t)
+
+	size := int64(3*ChunkSize + 100)
+	plaintext := make([]byte, size)
+	_, err := rand.Read(plaintext)
+	require.NoError(t, err)
+
+	encrypted := encrypt(t, c, plaintext)
+
+	for _, r := range [][2]int64{{0, 9}, {ChunkSize - 5, ChunkSize + 5}, {ChunkSize, 2*ChunkSize - 1}, {3 * ChunkSize, size - 1}, {10, size - 1}} {
+		encryptedStart, encryptedEnd := EncryptedRange(size, r[0], r[1])
+
+		decrypted, err := io.ReadAll(c.DecryptReader(bytes.NewReader(encrypted[encryptedStart:encryptedEnd+1]), size, r[0], r[1]))
+		require.NoError(t, err)
+		assert.Equal(t, plaintext[r[0]:r[1]+1], decrypted)
+	}
+}
+
+func TestCipher_DecryptTampered(t *testing.T) {
+	c := newTestCipher(t)
+
+	size := int64(2*ChunkSize + 100)
+	encrypted := encrypt(t, c, make([]byte, size))
+
+	tampered := bytes.Clone(encrypted)
+	tampered[ChunkSize+tagSize+1] ^= 1
+	_, err := io.ReadAll(c.DecryptReader(bytes.NewReader(tampered), size, 0, size-1))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	truncated := encrypted[:2*encryptedChunkSize]
+	_, err = io.ReadAll(c.DecryptReader(bytes.NewReader(truncated), size, 0, size-1))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	reordered := append(bytes.Clone(encrypted[encryptedChunkSize:2*encryptedChunkSize]), encrypted[:encryptedChunkSize]...)
+	reordered = append(reordered, encrypted[2*encryptedChunkSize:]...)
+	_, err = io.ReadAll(c.DecryptReader(bytes.NewReader(reordered), size, 0, size-1))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = io.ReadAll(newTestCipher(t).DecryptReader(bytes.NewReader(encrypted), size, 0, size-1))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestCipher_EncryptOnce(t *testing.T) {
+	dataKey := make([]byte, keySize)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+
+	c, err := NewCipher(dataKey)
+	require.NoError(t, err)
+	_, err = io.ReadAll(c.EncryptReader(bytes.NewReader([]byte("hello world"))))
+	assert.ErrorIs(t, err, ErrDataKeyReused)
+
+	c, err = NewEncryptingCipher(dataKey)
+	require.NoError(t, err)
+	_, err = io.ReadAll(c.EncryptReader(bytes.NewReader([]byte("hello world"))))
+	require.NoError(t, err)
+	_, err = io.ReadAll(c.EncryptReader(bytes.NewReader([]byte("hello there"))))
+	assert.ErrorIs(t, err, ErrDataKeyReused)
+}
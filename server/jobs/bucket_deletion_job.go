@@ -20,7 +20,7 @@ func (BucketDeletion) Kind() string {
 
 type BucketDeletionWorker struct {
 	queries *database.Queries
-	storage *storage.Storage
+	storage *storage.Registry
 	logger  *zap.Logger
 	river.WorkerDefaults[BucketDeletion]
 }
@@ -39,6 +39,17 @@ func (w *BucketDeletionWorker) Work(ctx context.Context, bucketDeletion *river.J
 		return err
 	}
 
+	backend, err := w.storage.Backend(bucket.Backend)
+	if err != nil {
+		w.logger.Error(
+			"failed to get storage backend of bucket",
+			zap.String("bucket_id", bucket.ID),
+			zapfield.Operation(op),
+			zap.Error(err),
+		)
+		return err
+	}
+
 	limit := int32(100)
 	offset := int32(0)
 
@@ -62,15 +73,22 @@ func (w *BucketDeletionWorker) Work(ctx context.Context, bucketDeletion *river.J
 		}
 
 		for _, object := range objects {
-			err = w.storage.DeleteObject(ctx, &storage.ObjectDelete{
+			objectDelete := &storage.ObjectDelete{
 				Bucket: bucket.Name,
 				Name:   object.Name,
-			})
+			}
+			if object.DeletedAt != nil {
+				objectDelete = &storage.ObjectDelete{
+					Bucket: storage.TrashBucket(bucket.Name),
+					Name:   object.ID,
+				}
+			}
+			err = backend.DeleteObject(ctx, objectDelete)
 			if err != nil {
 				w.logger.Error(
 					"failed to delete object from storage",
-					zap.String("bucket_name", bucket.Name),
-					zap.String("object_name", object.Name),
+					zap.String("bucket_name", objectDelete.Bucket),
+					zap.String("object_name", objectDelete.Name),
 					zapfield.Operation(op),
 					zap.Error(err),
 				)
@@ -108,7 +126,7 @@ func (w *BucketDeletionWorker) Work(ctx context.Context, bucketDeletion *river.J
 	return nil
 }
 
-func NewBucketDeletionWorker(db *pgxpool.Pool, storage *storage.Storage, logger *zap.Logger) *BucketDeletionWorker {
+func NewBucketDeletionWorker(db *pgxpool.Pool, storage *storage.Registry, logger *zap.Logger) *BucketDeletionWorker {
 	return &BucketDeletionWorker{
 		queries: database.New(db),
 		storage: storage,
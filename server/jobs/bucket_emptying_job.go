@@ -20,7 +20,7 @@ func (BucketEmptying) Kind() string {
 
 type BucketEmptyingWorker struct {
 	queries *database.Queries
-	storage *storage.Storage
+	storage *storage.Registry
 	logger  *zap.Logger
 	river.WorkerDefaults[BucketEmptying]
 }
@@ -39,6 +39,17 @@ func (w *BucketEmptyingWorker) Work(ctx context.Context, bucketEmpty *river.Job[
 		return err
 	}
 
+	backend, err := w.storage.Backend(bucket.Backend)
+	if err != nil {
+		w.logger.Error(
+			"failed to get storage backend of bucket",
+			zap.String("bucket_id", bucket.ID),
+			zapfield.Operation(op),
+			zap.Error(err),
+		)
+		return err
+	}
+
 	limit := int32(100)
 	offset := int32(0)
 
@@ -62,15 +73,22 @@ func (w *BucketEmptyingWorker) Work(ctx context.Context, bucketEmpty *river.Job[
 		}
 
 		for _, object := range objects {
-			err = w.storage.DeleteObject(ctx, &storage.ObjectDelete{
+			objectDelete := &storage.ObjectDelete{
 				Bucket: bucket.Name,
 				Name:   object.Name,
-			})
+			}
+			if object.DeletedAt != nil {
+				objectDelete = &storage.ObjectDelete{
+					Bucket: storage.TrashBucket(bucket.Name),
+					Name:   object.ID,
+				}
+			}
+			err = backend.DeleteObject(ctx, objectDelete)
 			if err != nil {
 				w.logger.Error(
 					"failed to delete object from storage",
-					zap.String("bucket_name", bucket.Name),
-					zap.String("object_name", object.Name),
+					zap.String("bucket_name", objectDelete.Bucket),
+					zap.String("object_name", objectDelete.Name),
 					zapfield.Operation(op),
 					zap.Error(err),
 				)
@@ -105,7 +123,7 @@ func (w *BucketEmptyingWorker) Work(ctx context.Context, bucketEmpty *river.Job[
 	return nil
 }
 
-func NewBucketEmptyingWorker(db *pgxpool.Pool, storage *storage.Storage, logger *zap.Logger) *BucketEmptyingWorker {
+func NewBucketEmptyingWorker(db *pgxpool.Pool, storage *storage.Registry, logger *zap.Logger) *BucketEmptyingWorker {
 	return &BucketEmptyingWorker{
 		queries: database.New(db),
 		storage: storage,
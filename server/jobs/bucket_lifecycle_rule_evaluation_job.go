@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"go.uber.org/zap"
+)
+
+// objectDeletionUniqueOpts skips the deletion of an object while another deletion of it has not finished
+var objectDeletionUniqueOpts = river.UniqueOpts{
+	ByArgs: true,
+	ByState: []rivertype.JobState{
+		rivertype.JobStateAvailable,
+		rivertype.JobStateRunning,
+		rivertype.JobStateRetryable,
+		rivertype.JobStateScheduled,
+	},
+}
+
+// BucketLifecycleRuleEvaluation is inserted periodically to enqueue the deletion of the objects matched by the
+// enabled lifecycle rules of all buckets
+type BucketLifecycleRuleEvaluation struct{}
+
+func (BucketLifecycleRuleEvaluation) Kind() string {
+	return "bucket.lifecycle.rule.evaluation"
+}
+
+type BucketLifecycleRuleEvaluationWorker struct {
+	queries *database.Queries
+	logger  *zap.Logger
+	river.WorkerDefaults[BucketLifecycleRuleEvaluation]
+}
+
+func (w *BucketLifecycleRuleEvaluationWorker) Work(ctx context.Context, _ *river.Job[BucketLifecycleRuleEvaluation]) error {
+	const op = "BucketLifecycleRuleEvaluationWorker.Work"
+
+	lifecycleRules, err := w.queries.BucketLifecycleRuleListEnabled(ctx)
+	if err != nil {
+		w.logger.Error(
+			"failed to list enabled lifecycle rules",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+		return err
+	}
+
+	riverClient := river.ClientFromContext[pgx.Tx](ctx)
+
+	limit := int32(100)
+
+	for _, lifecycleRule := range lifecycleRules {
+		cursor := ""
+		matched := int64(0)
+
+		for {
+			objectIds, err := w.queries.ObjectListIdsByLifecycleRule(ctx, &database.ObjectListIdsByLifecycleRuleParams{
+				BucketID:                       lifecycleRule.BucketID,
+				Cursor:                         cursor,
+				Prefix:                         lifecycleRule.Prefix,
+				Metadata:                       lifecycleRule.Metadata,
+				Tags:                           lifecycleRule.Tags,
+				ExpireAfterDays:                lifecycleRule.ExpireAfterDays,
+				ExpireAfterDaysSinceLastAccess: lifecycleRule.ExpireAfterDaysSinceLastAccess,
+				AbortPendingUploadAfterHours:   lifecycleRule.AbortPendingUploadAfterHours,
+				Limit:                          limit,
+			})
+			if err != nil {
+				w.logger.Error(
+					"failed to list objects matching lifecycle rule",
+					zap.Error(err),
+					zapfield.Operation(op),
+					zap.String("lifecycle_rule_id", lifecycleRule.ID),
+				)
+				return err
+			}
+			if len(objectIds) == 0 {
+				break
+			}
+
+			// objects stay matched until their deletion has run, unique inserts keep the runs in between from
+			// enqueueing another deletion. river does not support unique options for batch inserts
+			for _, objectId := range objectIds {
+				_, err = riverClient.Insert(ctx, ObjectDeletion{
+					ObjectId: objectId,
+				}, &river.InsertOpts{
+					UniqueOpts: objectDeletionUniqueOpts,
+				})
+				if err != nil {
+					w.logger.Error(
+						"failed to create object deletion job",
+						zap.Error(err),
+						zapfield.Operation(op),
+						zap.String("lifecycle_rule_id", lifecycleRule.ID),
+						zap.String("object_id", objectId),
+					)
+					return err
+				}
+			}
+			matched += int64(len(objectIds))
+
+			cursor = objectIds[len(objectIds)-1]
+		}
+
+		if matched > 0 {
+			w.logger.Info(
+				"enqueued deletion of objects matching lifecycle rule",
+				zapfield.Operation(op),
+				zap.String("bucket_id", lifecycleRule.BucketID),
+				zap.String("lifecycle_rule_id", lifecycleRule.ID),
+				zap.Int64("object_count", matched),
+			)
+		}
+	}
+
+	return nil
+}
+
+func NewBucketLifecycleRuleEvaluationWorker(db *pgxpool.Pool, logger *zap.Logger) *BucketLifecycleRuleEvaluationWorker {
+	return &BucketLifecycleRuleEvaluationWorker{
+		queries: database.New(db),
+		logger:  logger,
+	}
+}
@@ -0,0 +1,359 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/models"
+	"github.com/driftdev/storage/server/storage"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"time"
+)
+
+// objectBulkDeletionBatchSize is the number of objects deleted per batch, the most s3 deletes with a single call
+const objectBulkDeletionBatchSize = 1000
+
+// objectBulkDeletionTimeout lets a bulk deletion run longer than the default job timeout. a bulk deletion that times
+// out is retried and resumes after the last batch it recorded
+const objectBulkDeletionTimeout = 30 * time.Minute
+
+// ObjectBulkDeletion deletes the objects selected by a bulk deletion in batches and records the progress of the bulk
+// deletion after every batch. the objects are moved to the trash of their bucket unless the bulk deletion is
+// permanent, in which case they are deleted from storage. trashed objects are copied to the trash of their bucket
+// before they are marked deleted, and their names are deleted from storage by ObjectSourceDeletion jobs
+type ObjectBulkDeletion struct {
+	BulkDeletionId string `json:"bulk_deletion_id"`
+}
+
+func (ObjectBulkDeletion) Kind() string {
+	return "object.bulk.deletion"
+}
+
+type ObjectBulkDeletionWorker struct {
+	queries     *database.Queries
+	transaction *database.Transaction
+	storage     *storage.Registry
+	logger      *zap.Logger
+	river.WorkerDefaults[ObjectBulkDeletion]
+}
+
+func (w *ObjectBulkDeletionWorker) Timeout(*river.Job[ObjectBulkDeletion]) time.Duration {
+	return objectBulkDeletionTimeout
+}
+
+func (w *ObjectBulkDeletionWorker) Work(ctx context.Context, objectBulkDeletion *river.Job[ObjectBulkDeletion]) error {
+	const op = "ObjectBulkDeletionWorker.Work"
+
+	bulkDeletion, err := w.queries.ObjectBulkDeletionGetById(ctx, objectBulkDeletion.Args.BulkDeletionId)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil
+		}
+		w.logger.Error(
+			"failed to get bulk deletion",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bulk_deletion_id", objectBulkDeletion.Args.BulkDeletionId),
+		)
+		return err
+	}
+
+	if bulkDeletion.Status == models.ObjectBulkDeletionStatusCompleted {
+		return nil
+	}
+
+	bucket, err := w.queries.BucketGetById(ctx, bulkDeletion.BucketID)
+	if err != nil {
+		w.logger.Error(
+			"failed to get bucket",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_id", bulkDeletion.BucketID),
+		)
+		return err
+	}
+
+	backend, err := w.storage.Backend(bucket.Backend)
+	if err != nil {
+		w.logger.Error(
+			"failed to get storage backend of bucket",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_id", bucket.ID),
+		)
+		return err
+	}
+
+	riverClient := river.ClientFromContext[pgx.Tx](ctx)
+
+	err = w.queries.ObjectBulkDeletionStart(ctx, bulkDeletion.ID)
+	if err != nil {
+		w.logger.Error(
+			"failed to start bulk deletion",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bulk_deletion_id", bulkDeletion.ID),
+		)
+		return err
+	}
+
+	var reportedFailures []*models.ObjectBulkDeletionFailure
+	_ = json.Unmarshal(bulkDeletion.Failures, &reportedFailures)
+	reportedCount := len(reportedFailures)
+
+	cursor := lo.FromPtr(bulkDeletion.LastObjectID)
+
+	for {
+		objects, err := w.queries.ObjectListForBulkDeletion(ctx, &database.ObjectListForBulkDeletionParams{
+			BucketID:  bucket.ID,
+			Cursor:    cursor,
+			ObjectIds: bulkDeletion.ObjectIds,
+			Prefix:    bulkDeletion.Prefix,
+			Tags:      bulkDeletion.Tags,
+			Limit:     objectBulkDeletionBatchSize,
+		})
+		if err != nil {
+			w.logger.Error(
+				"failed to list objects of bulk deletion",
+				zap.Error(err),
+				zapfield.Operation(op),
+				zap.String("bulk_deletion_id", bulkDeletion.ID),
+			)
+			return err
+		}
+		if len(objects) == 0 {
+			break
+		}
+
+		var deletedIds []string
+		failures := make([]*models.ObjectBulkDeletionFailure, 0)
+		failedCount := int64(0)
+
+		if bulkDeletion.Permanent {
+			deletedIds, failures, failedCount, err = w.deleteObjects(ctx, backend, bucket.Name, objects, &reportedCount)
+			if err != nil {
+				w.logger.Error(
+					"failed to delete objects from storage",
+					zap.Error(err),
+					zapfield.Operation(op),
+					zap.String("bulk_deletion_id", bulkDeletion.ID),
+				)
+				return err
+			}
+		} else {
+			deletedIds, failures, failedCount = w.trashObjects(ctx, backend, bucket.Name, objects, &reportedCount)
+		}
+
+		failuresBytes, err := json.Marshal(failures)
+		if err != nil {
+			return err
+		}
+
+		lastObjectId := objects[len(objects)-1].ID
+
+		var untrashedIds []string
+
+		err = w.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+			if bulkDeletion.Permanent {
+				err := w.queries.WithTx(tx).ObjectDeleteMany(ctx, deletedIds)
+				if err != nil {
+					return err
+				}
+			} else {
+				trashedIds, err := w.queries.WithTx(tx).ObjectTrashMany(ctx, deletedIds)
+				if err != nil {
+					return err
+				}
+
+				// objects deleted or trashed since they were listed keep their rows as they are, so their copies in
+				// the trash are not referenced by any object
+				untrashedIds, _ = lo.Difference(deletedIds, trashedIds)
+
+				if err = w.insertSourceDeletions(ctx, riverClient, tx, bucket, objects, trashedIds); err != nil {
+					return err
+				}
+			}
+
+			return w.queries.WithTx(tx).ObjectBulkDeletionUpdateProgress(ctx, &database.ObjectBulkDeletionUpdateProgressParams{
+				DeletedCount: int64(len(deletedIds)),
+				FailedCount:  failedCount,
+				Failures:     failuresBytes,
+				LastObjectID: &lastObjectId,
+				ID:           bulkDeletion.ID,
+			})
+		})
+		if err != nil {
+			w.logger.Error(
+				"failed to record bulk deletion progress",
+				zap.Error(err),
+				zapfield.Operation(op),
+				zap.String("bulk_deletion_id", bulkDeletion.ID),
+			)
+			return err
+		}
+
+		for _, objectId := range untrashedIds {
+			err = backend.DeleteObject(ctx, &storage.ObjectDelete{
+				Bucket: storage.TrashBucket(bucket.Name),
+				Name:   objectId,
+			})
+			if err != nil {
+				w.logger.Error(
+					"failed to delete object from trash in storage",
+					zap.Error(err),
+					zapfield.Operation(op),
+					zap.String("object_id", objectId),
+				)
+			}
+		}
+
+		cursor = lastObjectId
+	}
+
+	err = w.queries.ObjectBulkDeletionComplete(ctx, bulkDeletion.ID)
+	if err != nil {
+		w.logger.Error(
+			"failed to complete bulk deletion",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bulk_deletion_id", bulkDeletion.ID),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// deleteObjects deletes a batch of objects from storage and returns the ids of the deleted objects together with the
+// failures to report and the number of objects that failed to delete. objects that failed to delete keep their rows
+// and are reported until the bulk deletion has reported ObjectBulkDeletionMaxReportedFailures failures
+func (w *ObjectBulkDeletionWorker) deleteObjects(ctx context.Context, backend storage.Backend, bucketName string, objects []*database.ObjectListForBulkDeletionRow, reportedCount *int) ([]string, []*models.ObjectBulkDeletionFailure, int64, error) {
+	deleteErrors, err := backend.DeleteObjects(ctx, &storage.ObjectsDelete{
+		Bucket: bucketName,
+		Names: lo.Map(objects, func(object *database.ObjectListForBulkDeletionRow, _ int) string {
+			return object.Name
+		}),
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	deleteErrorsByName := lo.KeyBy(deleteErrors, func(deleteError *storage.ObjectDeleteError) string {
+		return deleteError.Name
+	})
+
+	deletedIds := make([]string, 0, len(objects))
+	failures := make([]*models.ObjectBulkDeletionFailure, 0)
+	failedCount := int64(0)
+
+	for _, object := range objects {
+		deleteError, failed := deleteErrorsByName[object.Name]
+		if !failed {
+			deletedIds = append(deletedIds, object.ID)
+			continue
+		}
+
+		failedCount++
+		if *reportedCount < models.ObjectBulkDeletionMaxReportedFailures {
+			failures = append(failures, &models.ObjectBulkDeletionFailure{
+				ObjectId: object.ID,
+				Name:     lo.ToPtr(object.Name),
+				Error:    fmt.Sprintf("%s: %s", deleteError.Code, deleteError.Message),
+			})
+			*reportedCount++
+		}
+	}
+
+	return deletedIds, failures, failedCount, nil
+}
+
+// trashObjects copies a batch of objects to the trash of their bucket under their ids and returns the ids of the copied
+// objects together with the failures to report and the number of objects that failed to copy. sse-c objects cannot be
+// copied without the customer key of the client, so they fail and have to be deleted one by one
+func (w *ObjectBulkDeletionWorker) trashObjects(ctx context.Context, backend storage.Backend, bucketName string, objects []*database.ObjectListForBulkDeletionRow, reportedCount *int) ([]string, []*models.ObjectBulkDeletionFailure, int64) {
+	trashedIds := make([]string, 0, len(objects))
+	failures := make([]*models.ObjectBulkDeletionFailure, 0)
+	failedCount := int64(0)
+
+	for _, object := range objects {
+		var err error
+		if lo.FromPtr(object.Encryption) == models.BucketEncryptionSSEC {
+			err = errors.New("object is encrypted with sse-c and can only be deleted with its customer key")
+		} else {
+			var encryption *storage.Encryption
+			if object.Encryption != nil {
+				encryption = &storage.Encryption{
+					Algorithm: *object.Encryption,
+					KmsKeyId:  object.EncryptionKmsKeyID,
+				}
+			}
+
+			err = backend.CopyObject(ctx, &storage.ObjectCopy{
+				SourceBucket:      bucketName,
+				SourceName:        object.Name,
+				DestinationBucket: storage.TrashBucket(bucketName),
+				DestinationName:   object.ID,
+				Encryption:        encryption,
+			})
+		}
+		if err == nil {
+			trashedIds = append(trashedIds, object.ID)
+			continue
+		}
+
+		failedCount++
+		if *reportedCount < models.ObjectBulkDeletionMaxReportedFailures {
+			failures = append(failures, &models.ObjectBulkDeletionFailure{
+				ObjectId: object.ID,
+				Name:     lo.ToPtr(object.Name),
+				Error:    err.Error(),
+			})
+			*reportedCount++
+		}
+	}
+
+	return trashedIds, failures, failedCount
+}
+
+// insertSourceDeletions enqueues the deletion of the names of the trashed objects of a batch from storage in the
+// transaction that marks them deleted
+func (w *ObjectBulkDeletionWorker) insertSourceDeletions(ctx context.Context, riverClient *river.Client[pgx.Tx], tx pgx.Tx, bucket *database.StorageBucket, objects []*database.ObjectListForBulkDeletionRow, trashedIds []string) error {
+	if len(trashedIds) == 0 {
+		return nil
+	}
+
+	objectsById := lo.KeyBy(objects, func(object *database.ObjectListForBulkDeletionRow) string {
+		return object.ID
+	})
+
+	sourceDeletions := make([]river.InsertManyParams, 0, len(trashedIds))
+	for _, objectId := range trashedIds {
+		sourceDeletions = append(sourceDeletions, river.InsertManyParams{
+			Args: ObjectSourceDeletion{
+				BucketId:      bucket.ID,
+				BucketName:    bucket.Name,
+				BucketBackend: bucket.Backend,
+				ObjectName:    objectsById[objectId].Name,
+			},
+		})
+	}
+
+	_, err := riverClient.InsertManyTx(ctx, tx, sourceDeletions)
+	return err
+}
+
+func NewObjectBulkDeletionWorker(db *pgxpool.Pool, storage *storage.Registry, logger *zap.Logger) *ObjectBulkDeletionWorker {
+	return &ObjectBulkDeletionWorker{
+		queries:     database.New(db),
+		transaction: database.NewTransaction(db),
+		storage:     storage,
+		logger:      logger,
+	}
+}
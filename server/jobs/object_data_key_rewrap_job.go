@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/envelope"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"time"
+)
+
+// objectDataKeyRewrapBatchSize is the number of objects whose data keys are re-wrapped per batch
+const objectDataKeyRewrapBatchSize = 500
+
+// objectDataKeyRewrapTimeout lets a re-wrap run longer than the default job timeout, a re-wrap that times out
+// continues with the objects that are still wrapped with another master key the next time it runs
+const objectDataKeyRewrapTimeout = 30 * time.Minute
+
+// ObjectDataKeyRewrap is inserted periodically to re-wrap the data keys of objects of encrypted buckets that are
+// wrapped with a master key other than the active key of the keyring, so that a rotated master key can be retired
+// once no data key is wrapped with it anymore
+type ObjectDataKeyRewrap struct{}
+
+func (ObjectDataKeyRewrap) Kind() string {
+	return "object.data_key.rewrap"
+}
+
+type ObjectDataKeyRewrapWorker struct {
+	queries *database.Queries
+	keyring *envelope.Keyring
+	logger  *zap.Logger
+	river.WorkerDefaults[ObjectDataKeyRewrap]
+}
+
+func (w *ObjectDataKeyRewrapWorker) Timeout(*river.Job[ObjectDataKeyRewrap]) time.Duration {
+	return objectDataKeyRewrapTimeout
+}
+
+func (w *ObjectDataKeyRewrapWorker) Work(ctx context.Context, _ *river.Job[ObjectDataKeyRewrap]) error {
+	const op = "ObjectDataKeyRewrapWorker.Work"
+
+	if !w.keyring.Enabled() {
+		return nil
+	}
+
+	activeKeyId := w.keyring.ActiveKeyId()
+	cursor := ""
+	rewrapped := int64(0)
+	failed := int64(0)
+
+	for {
+		objects, err := w.queries.ObjectListForDataKeyRewrap(ctx, &database.ObjectListForDataKeyRewrapParams{
+			MasterKeyID: activeKeyId,
+			Cursor:      cursor,
+			Limit:       objectDataKeyRewrapBatchSize,
+		})
+		if err != nil {
+			w.logger.Error(
+				"failed to list objects for data key rewrap",
+				zap.Error(err),
+				zapfield.Operation(op),
+			)
+			return err
+		}
+		if len(objects) == 0 {
+			break
+		}
+
+		for _, object := range objects {
+			masterKeyId := lo.FromPtr(object.EncryptionMasterKeyID)
+
+			// a data key that cannot be unwrapped, like one wrapped with a master key that was removed from the
+			// keyring, is reported and skipped so that it does not hold back the other objects
+			wrappedDataKey, _, err := w.keyring.RewrapDataKey(lo.FromPtr(object.EncryptionDataKey), masterKeyId)
+			if err != nil {
+				w.logger.Error(
+					"failed to rewrap object data key",
+					zap.Error(err),
+					zapfield.Operation(op),
+					zap.String("object_id", object.ID),
+					zap.String("master_key_id", masterKeyId),
+				)
+				failed++
+				continue
+			}
+
+			// the update only applies while the object is still wrapped with the master key it was listed with
+			err = w.queries.ObjectUpdateEncryptionDataKey(ctx, &database.ObjectUpdateEncryptionDataKeyParams{
+				EncryptionDataKey:     wrappedDataKey,
+				EncryptionMasterKeyID: activeKeyId,
+				ID:                    object.ID,
+				PreviousMasterKeyID:   masterKeyId,
+			})
+			if err != nil {
+				w.logger.Error(
+					"failed to update object data key",
+					zap.Error(err),
+					zapfield.Operation(op),
+					zap.String("object_id", object.ID),
+				)
+				return err
+			}
+			rewrapped++
+		}
+
+		cursor = objects[len(objects)-1].ID
+	}
+
+	if rewrapped > 0 || failed > 0 {
+		w.logger.Info(
+			"rewrapped object data keys with the active master key",
+			zapfield.Operation(op),
+			zap.String("master_key_id", activeKeyId),
+			zap.Int64("object_count", rewrapped),
+			zap.Int64("failed_object_count", failed),
+		)
+	}
+
+	return nil
+}
+
+func NewObjectDataKeyRewrapWorker(db *pgxpool.Pool, keyring *envelope.Keyring, logger *zap.Logger) *ObjectDataKeyRewrapWorker {
+	return &ObjectDataKeyRewrapWorker{
+		queries: database.New(db),
+		keyring: keyring,
+		logger:  logger,
+	}
+}
@@ -10,8 +10,11 @@ import (
 	"go.uber.org/zap"
 )
 
+// ObjectDeletion deletes an object permanently. `Trashed` is set when the object is purged from the trash of its
+// bucket, so that an object restored in the meantime is kept
 type ObjectDeletion struct {
 	ObjectId string `json:"object_id"`
+	Trashed  bool   `json:"trashed,omitempty"`
 }
 
 func (ObjectDeletion) Kind() string {
@@ -20,7 +23,7 @@ func (ObjectDeletion) Kind() string {
 
 type ObjectDeletionWorker struct {
 	queries *database.Queries
-	storage *storage.Storage
+	storage *storage.Registry
 	logger  *zap.Logger
 	river.WorkerDefaults[ObjectDeletion]
 }
@@ -42,17 +45,69 @@ func (w *ObjectDeletionWorker) Work(ctx context.Context, objectDeletion *river.J
 		return err
 	}
 
-	err = w.storage.DeleteObject(ctx, &storage.ObjectDelete{
+	backend, err := w.storage.Backend(object.BucketBackend)
+	if err != nil {
+		w.logger.Error(
+			"failed to get storage backend of bucket",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_id", object.BucketID),
+		)
+		return err
+	}
+
+	if objectDeletion.Args.Trashed && object.DeletedAt == nil {
+		return nil
+	}
+
+	// pending uploads aborted by a lifecycle rule can still have a multipart upload in progress
+	multipartUploadSession, err := w.queries.MultipartUploadSessionGetByObjectId(ctx, object.ID)
+	if err != nil && !database.IsNotFoundError(err) {
+		w.logger.Error(
+			"failed to get multipart upload session",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("object_id", object.ID),
+		)
+		return err
+	}
+	if err == nil {
+		err = backend.AbortMultipartUpload(ctx, &storage.MultipartUploadAbort{
+			Bucket:   object.BucketName,
+			Name:     object.Name,
+			UploadId: multipartUploadSession.UploadID,
+		})
+		if err != nil {
+			w.logger.Error(
+				"failed to abort multipart upload",
+				zap.Error(err),
+				zapfield.Operation(op),
+				zap.String("bucket_name", object.BucketName),
+				zap.String("object_name", object.Name),
+			)
+			return err
+		}
+	}
+
+	objectDelete := &storage.ObjectDelete{
 		Bucket: object.BucketName,
 		Name:   object.Name,
-	})
+	}
+	if object.DeletedAt != nil {
+		objectDelete = &storage.ObjectDelete{
+			Bucket: storage.TrashBucket(object.BucketName),
+			Name:   object.ID,
+		}
+	}
+
+	err = backend.DeleteObject(ctx, objectDelete)
 	if err != nil {
 		w.logger.Error(
 			"failed to delete object",
 			zap.Error(err),
 			zapfield.Operation(op),
-			zap.String("bucket_name", object.BucketName),
-			zap.String("object_name", object.Name),
+			zap.String("bucket_name", objectDelete.Bucket),
+			zap.String("object_name", objectDelete.Name),
 		)
 		return err
 	}
@@ -71,7 +126,7 @@ func (w *ObjectDeletionWorker) Work(ctx context.Context, objectDeletion *river.J
 	return nil
 }
 
-func NewObjectDeletionWorker(db *pgxpool.Pool, storage *storage.Storage, logger *zap.Logger) *ObjectDeletionWorker {
+func NewObjectDeletionWorker(db *pgxpool.Pool, storage *storage.Registry, logger *zap.Logger) *ObjectDeletionWorker {
 	return &ObjectDeletionWorker{
 		queries: database.New(db),
 		storage: storage,
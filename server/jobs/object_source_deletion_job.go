@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"github.com/driftdev/storage/server/config"
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/storage"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// ObjectSourceDeletion deletes the source of a renamed, moved or trashed object from storage once it has been copied
+// to its new location. the object row no longer references the source, so only the bucket and name are known.
+// `BucketBackend` is empty for jobs that were enqueued before buckets had a storage backend, which kept their objects
+// in the default backend
+type ObjectSourceDeletion struct {
+	BucketId      string `json:"bucket_id"`
+	BucketName    string `json:"bucket_name"`
+	BucketBackend string `json:"bucket_backend,omitempty"`
+	ObjectName    string `json:"object_name"`
+}
+
+func (ObjectSourceDeletion) Kind() string {
+	return "object.source_deletion"
+}
+
+type ObjectSourceDeletionWorker struct {
+	queries *database.Queries
+	storage *storage.Registry
+	logger  *zap.Logger
+	river.WorkerDefaults[ObjectSourceDeletion]
+}
+
+func (w *ObjectSourceDeletionWorker) Work(ctx context.Context, objectSourceDeletion *river.Job[ObjectSourceDeletion]) error {
+	const op = "ObjectSourceDeletionWorker.Work"
+
+	// a new object may have been created with the name of the source before this job ran, in which case the key in
+	// storage belongs to the new object and must be kept. a trashed object has moved its content to the trash of its
+	// bucket, so only objects that are not in the trash hold the key
+	exists, err := w.queries.ObjectExistsByBucketIdAndName(ctx, &database.ObjectExistsByBucketIdAndNameParams{
+		BucketID: objectSourceDeletion.Args.BucketId,
+		Name:     objectSourceDeletion.Args.ObjectName,
+	})
+	if err != nil {
+		w.logger.Error(
+			"failed to check if object exists",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_id", objectSourceDeletion.Args.BucketId),
+			zap.String("object_name", objectSourceDeletion.Args.ObjectName),
+		)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	backend, err := w.storage.Backend(lo.Ternary(objectSourceDeletion.Args.BucketBackend != "", objectSourceDeletion.Args.BucketBackend, config.DefaultStorageBackend))
+	if err != nil {
+		w.logger.Error(
+			"failed to get storage backend of bucket",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_id", objectSourceDeletion.Args.BucketId),
+		)
+		return err
+	}
+
+	err = backend.DeleteObject(ctx, &storage.ObjectDelete{
+		Bucket: objectSourceDeletion.Args.BucketName,
+		Name:   objectSourceDeletion.Args.ObjectName,
+	})
+	if err != nil {
+		w.logger.Error(
+			"failed to delete object source",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_name", objectSourceDeletion.Args.BucketName),
+			zap.String("object_name", objectSourceDeletion.Args.ObjectName),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func NewObjectSourceDeletionWorker(db *pgxpool.Pool, storage *storage.Registry, logger *zap.Logger) *ObjectSourceDeletionWorker {
+	return &ObjectSourceDeletionWorker{
+		queries: database.New(db),
+		storage: storage,
+		logger:  logger,
+	}
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/riverqueue/river"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDB answers every query with the same row, which is enough for workers that only check whether an object exists
+type fakeDB struct {
+	row []any
+}
+
+func (db *fakeDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+
+func (db *fakeDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, pgx.ErrNoRows
+}
+
+func (db *fakeDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return &fakeRow{values: db.row}
+}
+
+type fakeRow struct {
+	values []any
+}
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if len(r.values) == 0 {
+		return pgx.ErrNoRows
+	}
+	for i, value := range r.values {
+		*dest[i].(*bool) = value.(bool)
+	}
+	return nil
+}
+
+func TestObjectSourceDeletionWorker_Work(t *testing.T) {
+	ctx := context.Background()
+
+	backend := storage.NewMemoryBackend(&config.Config{})
+	for _, name := range []string{"avatar.png", "banner.png"} {
+		err := backend.UploadObject(ctx, &storage.ObjectUpload{Bucket: "avatars", Name: name, ContentType: "image/png", Content: strings.NewReader("image")})
+		require.NoError(t, err)
+	}
+
+	db := &fakeDB{}
+	worker := &ObjectSourceDeletionWorker{
+		queries: database.New(db),
+		storage: storage.NewRegistryOf(map[string]storage.Backend{config.DefaultStorageBackend: backend}),
+		logger:  zap.NewNop(),
+	}
+
+	db.row = []any{false}
+	err := worker.Work(ctx, &river.Job[ObjectSourceDeletion]{Args: ObjectSourceDeletion{BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375", BucketName: "avatars", ObjectName: "avatar.png"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"banner.png"}, backend.ObjectNames("avatars"))
+
+	db.row = []any{true}
+	err = worker.Work(ctx, &river.Job[ObjectSourceDeletion]{Args: ObjectSourceDeletion{BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375", BucketName: "avatars", ObjectName: "banner.png"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"banner.png"}, backend.ObjectNames("avatars"), "the source is kept when an object has been created with its name")
+}
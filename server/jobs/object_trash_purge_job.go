@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"go.uber.org/zap"
+)
+
+// ObjectTrashPurge is inserted periodically to enqueue the permanent deletion of the objects that have been in the
+// trash of their bucket for longer than the trash retention of the bucket
+type ObjectTrashPurge struct{}
+
+func (ObjectTrashPurge) Kind() string {
+	return "object.trash.purge"
+}
+
+type ObjectTrashPurgeWorker struct {
+	queries *database.Queries
+	logger  *zap.Logger
+	river.WorkerDefaults[ObjectTrashPurge]
+}
+
+func (w *ObjectTrashPurgeWorker) Work(ctx context.Context, _ *river.Job[ObjectTrashPurge]) error {
+	const op = "ObjectTrashPurgeWorker.Work"
+
+	riverClient := river.ClientFromContext[pgx.Tx](ctx)
+
+	limit := int32(100)
+	cursor := ""
+	enqueued := int64(0)
+
+	for {
+		objectIds, err := w.queries.ObjectListIdsExpiredInTrash(ctx, &database.ObjectListIdsExpiredInTrashParams{
+			Cursor: cursor,
+			Limit:  limit,
+		})
+		if err != nil {
+			w.logger.Error(
+				"failed to list objects expired in trash",
+				zap.Error(err),
+				zapfield.Operation(op),
+			)
+			return err
+		}
+		if len(objectIds) == 0 {
+			break
+		}
+
+		// an object stays expired in the trash until its deletion has run, so a deletion enqueued by an earlier purge
+		// that has not finished yet is skipped
+		for _, objectId := range objectIds {
+			_, err = riverClient.Insert(ctx, ObjectDeletion{
+				ObjectId: objectId,
+				Trashed:  true,
+			}, &river.InsertOpts{
+				UniqueOpts: objectDeletionUniqueOpts,
+			})
+			if err != nil {
+				w.logger.Error(
+					"failed to create object deletion job",
+					zap.Error(err),
+					zapfield.Operation(op),
+					zap.String("object_id", objectId),
+				)
+				return err
+			}
+		}
+		enqueued += int64(len(objectIds))
+
+		cursor = objectIds[len(objectIds)-1]
+	}
+
+	if enqueued > 0 {
+		w.logger.Info(
+			"enqueued deletion of objects expired in trash",
+			zapfield.Operation(op),
+			zap.Int64("object_count", enqueued),
+		)
+	}
+
+	return nil
+}
+
+func NewObjectTrashPurgeWorker(db *pgxpool.Pool, logger *zap.Logger) *ObjectTrashPurgeWorker {
+	return &ObjectTrashPurgeWorker{
+		queries: database.New(db),
+		logger:  logger,
+	}
+}
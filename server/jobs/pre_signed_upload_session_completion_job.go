@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"github.com/driftdev/storage/server/database"
 	"github.com/driftdev/storage/server/models"
 	"github.com/driftdev/storage/server/storage"
@@ -21,7 +22,7 @@ func (PreSignedUploadSessionCompletion) Kind() string {
 
 type PreSignedUploadSessionCompletionWorker struct {
 	queries *database.Queries
-	storage *storage.Storage
+	storage *storage.Registry
 	logger  *zap.Logger
 	river.WorkerDefaults[PreSignedUploadSessionCompletion]
 }
@@ -42,9 +43,72 @@ func (w *PreSignedUploadSessionCompletionWorker) Work(ctx context.Context, preSi
 		)
 		return err
 	}
-	objectExists, err := w.storage.CheckIfObjectExists(ctx, &storage.ObjectExistsCheck{
-		Bucket: object.BucketName,
-		Name:   object.Name,
+
+	backend, err := w.storage.Backend(object.BucketBackend)
+	if err != nil {
+		w.logger.Error(
+			"failed to get storage backend of bucket",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_id", object.BucketID),
+		)
+		return err
+	}
+
+	// an expired multipart upload session is aborted so that s3 discards the parts uploaded so far
+	multipartUploadSession, err := w.queries.MultipartUploadSessionGetByObjectId(ctx, object.ID)
+	if err != nil && !database.IsNotFoundError(err) {
+		w.logger.Error(
+			"failed to get multipart upload session",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("object_id", object.ID),
+		)
+		return err
+	}
+	if err == nil {
+		err = backend.AbortMultipartUpload(ctx, &storage.MultipartUploadAbort{
+			Bucket:   object.BucketName,
+			Name:     object.Name,
+			UploadId: multipartUploadSession.UploadID,
+		})
+		if err != nil {
+			w.logger.Error(
+				"failed to abort multipart upload",
+				zap.Error(err),
+				zapfield.Operation(op),
+				zap.String("bucket_name", object.BucketName),
+				zap.String("object_name", object.Name),
+			)
+			return err
+		}
+		err = w.queries.MultipartUploadSessionDelete(ctx, object.ID)
+		if err != nil {
+			w.logger.Error(
+				"failed to delete multipart upload session from database",
+				zap.Error(err),
+				zapfield.Operation(op),
+				zap.String("object_id", object.ID),
+			)
+			return err
+		}
+	}
+
+	// the content of sse-c objects can only be read with the customer key, which is only known to the requests of
+	// the client. a pending sse-c upload that the client did not complete itself cannot be verified and is discarded
+	if object.Encryption != nil && *object.Encryption == storage.EncryptionSSEC {
+		if object.UploadStatus == models.ObjectUploadStatusCompleted {
+			return nil
+		}
+		return w.deleteObject(ctx, backend, object, op)
+	}
+
+	encryption := objectEncryption(object)
+
+	objectExists, err := backend.CheckIfObjectExists(ctx, &storage.ObjectExistsCheck{
+		Bucket:     object.BucketName,
+		Name:       object.Name,
+		Encryption: encryption,
 	})
 	if err != nil {
 		w.logger.Error(
@@ -57,11 +121,21 @@ func (w *PreSignedUploadSessionCompletionWorker) Work(ctx context.Context, preSi
 		return err
 	}
 
+	// pending content that does not match the checksum declared for the upload session or that is detected
+	// as a mime type the bucket does not allow is handled like a missing upload
+	var detectedMimeType string
+	if objectExists && object.UploadStatus != models.ObjectUploadStatusCompleted {
+		objectExists, detectedMimeType, err = w.verifyObjectContent(ctx, backend, object, encryption, op)
+		if err != nil {
+			return err
+		}
+	}
+
 	if objectExists {
 		if object.UploadStatus != models.ObjectUploadStatusCompleted {
-			err = w.queries.ObjectUpdateUploadStatus(ctx, &database.ObjectUpdateUploadStatusParams{
-				ID:           object.ID,
-				UploadStatus: models.ObjectUploadStatusCompleted,
+			err = w.queries.ObjectCompleteUpload(ctx, &database.ObjectCompleteUploadParams{
+				ID:               object.ID,
+				DetectedMimeType: &detectedMimeType,
 			})
 			if err != nil {
 				w.logger.Error(
@@ -74,36 +148,125 @@ func (w *PreSignedUploadSessionCompletionWorker) Work(ctx context.Context, preSi
 			}
 		}
 	} else {
-		err = w.storage.DeleteObject(ctx, &storage.ObjectDelete{
-			Bucket: object.BucketName,
-			Name:   object.Name,
+		return w.deleteObject(ctx, backend, object, op)
+	}
+
+	return nil
+}
+
+// deleteObject deletes an object whose upload was not completed from storage and the database
+func (w *PreSignedUploadSessionCompletionWorker) deleteObject(ctx context.Context, backend storage.Backend, object *database.ObjectGetByIdWithBucketNameRow, op string) error {
+	err := backend.DeleteObject(ctx, &storage.ObjectDelete{
+		Bucket: object.BucketName,
+		Name:   object.Name,
+	})
+	if err != nil {
+		w.logger.Error(
+			"failed to delete object from storage",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_name", object.BucketName),
+			zap.String("object_name", object.Name),
+		)
+		return err
+	}
+	err = w.queries.ObjectDelete(ctx, object.ID)
+	if err != nil {
+		w.logger.Error(
+			"failed to delete object from database",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("object_id", object.ID),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// objectEncryption returns the sse-s3 or sse-kms encryption the object was written with
+func objectEncryption(object *database.ObjectGetByIdWithBucketNameRow) *storage.Encryption {
+	if object.Encryption == nil {
+		return nil
+	}
+	return &storage.Encryption{
+		Algorithm: *object.Encryption,
+		KmsKeyId:  object.EncryptionKmsKeyID,
+	}
+}
+
+// verifyObjectContent checks the uploaded content of a pending object against the checksum declared for its upload
+// session and the mime types allowed by its bucket, and returns the mime type detected from the content
+func (w *PreSignedUploadSessionCompletionWorker) verifyObjectContent(ctx context.Context, backend storage.Backend, object *database.ObjectGetByIdWithBucketNameRow, encryption *storage.Encryption, op string) (bool, string, error) {
+	if object.ChecksumAlgorithm != nil {
+		objectInfo, err := backend.HeadObject(ctx, &storage.ObjectHead{
+			Bucket:     object.BucketName,
+			Name:       object.Name,
+			Encryption: encryption,
 		})
 		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotFound) {
+				return false, "", nil
+			}
 			w.logger.Error(
-				"failed to delete object from storage",
+				"failed to head object",
 				zap.Error(err),
 				zapfield.Operation(op),
 				zap.String("bucket_name", object.BucketName),
 				zap.String("object_name", object.Name),
 			)
-			return err
+			return false, "", err
 		}
-		err = w.queries.ObjectDelete(ctx, object.ID)
-		if err != nil {
-			w.logger.Error(
-				"failed to delete object from database",
-				zap.Error(err),
-				zapfield.Operation(op),
-				zap.String("object_id", object.ID),
-			)
-			return err
+		if !objectInfo.MatchesChecksum(*object.ChecksumAlgorithm, *object.Checksum) {
+			return false, "", nil
 		}
 	}
 
-	return nil
+	bucket, err := w.queries.BucketGetById(ctx, object.BucketID)
+	if err != nil {
+		w.logger.Error(
+			"failed to get bucket",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_id", object.BucketID),
+		)
+		return false, "", err
+	}
+
+	detectedMimeType, err := backend.DetectContentType(ctx, &storage.ObjectContentTypeDetection{
+		Bucket:     object.BucketName,
+		Name:       object.Name,
+		Encryption: encryption,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return false, "", nil
+		}
+		w.logger.Error(
+			"failed to detect object content type",
+			zap.Error(err),
+			zapfield.Operation(op),
+			zap.String("bucket_name", object.BucketName),
+			zap.String("object_name", object.Name),
+		)
+		return false, "", err
+	}
+
+	if !models.IsDetectedMimeTypeAllowed(bucket.AllowedMimeTypes, object.MimeType, detectedMimeType) {
+		w.logger.Warn(
+			"rejected uploaded object with a detected mime type that is not allowed",
+			zapfield.Operation(op),
+			zap.String("object_id", object.ID),
+			zap.String("mime_type", object.MimeType),
+			zap.String("detected_mime_type", detectedMimeType),
+		)
+		return false, "", nil
+	}
+
+	return true, detectedMimeType, nil
 }
 
-func NewPreSignedUploadSessionCompletionWorker(db *pgxpool.Pool, storage *storage.Storage, logger *zap.Logger) *PreSignedUploadSessionCompletionWorker {
+func NewPreSignedUploadSessionCompletionWorker(db *pgxpool.Pool, storage *storage.Registry, logger *zap.Logger) *PreSignedUploadSessionCompletionWorker {
 	return &PreSignedUploadSessionCompletionWorker{
 		queries: database.New(db),
 		storage: storage,
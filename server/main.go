@@ -2,13 +2,10 @@ package main
 
 import (
 	"context"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/driftdev/storage/server/config"
 	"github.com/driftdev/storage/server/controllers"
 	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/envelope"
 	"github.com/driftdev/storage/server/jobs"
 	"github.com/driftdev/storage/server/logger"
 	"github.com/driftdev/storage/server/middleware"
@@ -23,6 +20,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -39,6 +37,7 @@ func main() {
 		Immutable:                true,
 		EnablePrintRoutes:        true,
 		EnableSplittingOnParsers: true,
+		StreamRequestBody:        true,
 	})
 
 	server.Use(middleware.Logger(newLogger))
@@ -47,6 +46,8 @@ func main() {
 
 	server.Use(middleware.KeyAuth(newConfig))
 
+	server.Use(middleware.EncryptionCustomerKey())
+
 	pgxPoolConfig, err := pgxpool.ParseConfig(newConfig.PostgresUrl)
 	if err != nil {
 		newLogger.Fatal("error parsing postgres url",
@@ -65,30 +66,21 @@ func main() {
 		)
 	}
 
-	s3Config, err := awsConfig.LoadDefaultConfig(
-		context.Background(),
-		awsConfig.WithRegion(newConfig.S3Region),
-		awsConfig.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(newConfig.S3AccessKeyId, newConfig.S3SecretAccessKey, ""),
-		),
-	)
+	newStorage, err := storage.NewRegistry(newConfig, newLogger)
 	if err != nil {
-		newLogger.Fatal("error loading aws s3 config",
+		newLogger.Fatal("error creating storage backends",
 			zap.Error(err),
 			zapfield.Operation(op),
 		)
 	}
 
-	s3Client := s3.NewFromConfig(
-		s3Config,
-		func(o *s3.Options) {
-			o.BaseEndpoint = aws.String(newConfig.S3Endpoint)
-			o.UsePathStyle = newConfig.S3ForcePathStyle
-			o.EndpointOptions.DisableHTTPS = newConfig.S3DisableSSL
-		},
-	)
-
-	newStorage := storage.NewStorage(s3Client, newConfig, newLogger)
+	newKeyring, err := envelope.NewKeyring(newConfig)
+	if err != nil {
+		newLogger.Fatal("error creating encryption keyring",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+	}
 
 	riverPgx := riverpgxv5.New(pgxPool)
 
@@ -132,11 +124,69 @@ func main() {
 		)
 	}
 
+	if err = river.AddWorkerSafely[jobs.ObjectSourceDeletion](workers, jobs.NewObjectSourceDeletionWorker(pgxPool, newStorage, newLogger)); err != nil {
+		newLogger.Fatal("error adding object source deletion worker",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+	}
+
+	if err = river.AddWorkerSafely[jobs.ObjectBulkDeletion](workers, jobs.NewObjectBulkDeletionWorker(pgxPool, newStorage, newLogger)); err != nil {
+		newLogger.Fatal("error adding object bulk deletion worker",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+	}
+
+	if err = river.AddWorkerSafely[jobs.BucketLifecycleRuleEvaluation](workers, jobs.NewBucketLifecycleRuleEvaluationWorker(pgxPool, newLogger)); err != nil {
+		newLogger.Fatal("error adding bucket lifecycle rule evaluation worker",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+	}
+
+	if err = river.AddWorkerSafely[jobs.ObjectTrashPurge](workers, jobs.NewObjectTrashPurgeWorker(pgxPool, newLogger)); err != nil {
+		newLogger.Fatal("error adding object trash purge worker",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+	}
+
+	if err = river.AddWorkerSafely[jobs.ObjectDataKeyRewrap](workers, jobs.NewObjectDataKeyRewrapWorker(pgxPool, newKeyring, newLogger)); err != nil {
+		newLogger.Fatal("error adding object data key rewrap worker",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+	}
+
 	riverClient, err := river.NewClient[pgx.Tx](riverPgx, &river.Config{
 		Queues: map[string]river.QueueConfig{
 			river.QueueDefault: {MaxWorkers: 100},
 		},
 		Workers: workers,
+		PeriodicJobs: []*river.PeriodicJob{
+			river.NewPeriodicJob(
+				river.PeriodicInterval(time.Duration(newConfig.LifecycleRuleEvaluationInterval)*time.Second),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return jobs.BucketLifecycleRuleEvaluation{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(time.Duration(newConfig.TrashPurgeInterval)*time.Second),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return jobs.ObjectTrashPurge{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(time.Duration(newConfig.EncryptionKeyRewrapInterval)*time.Second),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return jobs.ObjectDataKeyRewrap{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+		},
 	})
 	if err != nil {
 		newLogger.Fatal("error creating river client",
@@ -145,12 +195,26 @@ func main() {
 		)
 	}
 
-	bucketService := services.NewBucketService(pgxPool, riverClient, newLogger)
+	bucketService := services.NewBucketService(pgxPool, newStorage, newKeyring, riverClient, newLogger)
+
+	err = bucketService.ProvisionDefaultBuckets(context.Background(), newConfig.DefaultBuckets)
+	if err != nil {
+		newLogger.Fatal("error provisioning default buckets",
+			zap.Error(err),
+			zapfield.Operation(op),
+		)
+	}
+
 	controllers.NewBucketController(bucketService).RegisterBucketRoutes(server)
 
-	objectService := services.NewObjectService(pgxPool, newStorage, riverClient, newConfig, newLogger)
+	objectService := services.NewObjectService(pgxPool, newStorage, newKeyring, riverClient, newConfig, newLogger)
 	controllers.NewObjectController(objectService).RegisterObjectRoutes(server)
 
+	if newStorage.HasFilesystemBackend() {
+		signedUrlService := services.NewSignedUrlService(pgxPool, newStorage, newLogger)
+		controllers.NewSignedUrlController(signedUrlService).RegisterSignedUrlRoutes(server)
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// EncryptionCustomerKeyHeader is the header the base64 encoded customer key of buckets with `sse-c` encryption is sent in
+const EncryptionCustomerKeyHeader = "X-Encryption-Customer-Key"
+
+// EncryptionCustomerKey keeps the customer key sent with a request in the request context, where services read it
+// with utils.EncryptionCustomerKey. the key is only forwarded to s3 and never stored or logged
+func EncryptionCustomerKey() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if customerKey := ctx.Get(EncryptionCustomerKeyHeader); customerKey != "" {
+			ctx.Locals("encryption_customer_key", customerKey)
+		}
+		return ctx.Next()
+	}
+}
@@ -49,6 +49,8 @@ func ErrorHandler(ctx *fiber.Ctx, err error) error {
 				httpError.StatusCode = fiber.StatusBadRequest
 			case errors.Is(srvError.ErrorCode, srverr.ForbiddenError):
 				httpError.StatusCode = fiber.StatusForbidden
+			case errors.Is(srvError.ErrorCode, srverr.RangeNotSatisfiableError):
+				httpError.StatusCode = fiber.StatusRequestedRangeNotSatisfiable
 			case errors.Is(srvError.ErrorCode, srverr.UnknownError):
 				httpError.StatusCode = fiber.StatusInternalServerError
 			}
@@ -5,10 +5,21 @@ import (
 	"github.com/driftdev/storage/server/config"
 	"github.com/driftdev/storage/server/utils"
 	"github.com/gofiber/fiber/v2/middleware/keyauth"
+	"strings"
 )
 
+// PublicRoutesPrefix is the path prefix of routes that serve objects of public buckets without an api key
+const PublicRoutesPrefix = "/public/"
+
+// SignedRoutesPrefix is the path prefix of routes that serve the pre-signed urls of the filesystem storage backend.
+// they are authorized by the signature of the url instead of an api key
+const SignedRoutesPrefix = "/signed/"
+
 func KeyAuth(config *config.Config) fiber.Handler {
 	return keyauth.New(keyauth.Config{
+		Next: func(ctx *fiber.Ctx) bool {
+			return strings.HasPrefix(ctx.Path(), PublicRoutesPrefix) || strings.HasPrefix(ctx.Path(), SignedRoutesPrefix)
+		},
 		ErrorHandler: func(ctx *fiber.Ctx, err error) error {
 			if errors.Is(err, keyauth.ErrMissingOrMalformedAPIKey) {
 				return ctx.Status(fiber.StatusUnauthorized).JSON(&HttpError{
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type BucketLifecycleRule struct {
+	Id                             string            `json:"id" example:"lifecycle_rule_01HPG4GN5JY2Z6S0638ERSG375"`
+	Version                        int32             `json:"version" example:"0"`
+	BucketId                       string            `json:"bucket_id" example:"bucket_01HPG4GN5JY2Z6S0638ERSG375"`
+	Name                           string            `json:"name" example:"expire-scratch-exports"`
+	Enabled                        bool              `json:"enabled" example:"true"`
+	Prefix                         *string           `json:"prefix" example:"exports/scratch/" extensions:"x-nullable"`
+	Metadata                       map[string]any    `json:"metadata" extensions:"x-nullable"`
+	Tags                           map[string]string `json:"tags" example:"retention:short" extensions:"x-nullable"`
+	ExpireAfterDays                *int32            `json:"expire_after_days" example:"7" extensions:"x-nullable"`
+	ExpireAfterDaysSinceLastAccess *int32            `json:"expire_after_days_since_last_access" example:"90" extensions:"x-nullable"`
+	AbortPendingUploadAfterHours   *int32            `json:"abort_pending_upload_after_hours" example:"24" extensions:"x-nullable"`
+	CreatedAt                      time.Time         `json:"created_at" default:"2024-02-13T08:14:49.952238+05:30"`
+	UpdatedAt                      *time.Time        `json:"updated_at" default:"2024-02-13T08:18:21.47635+05:30" extensions:"x-nullable"`
+}
+
+type BucketLifecycleRuleCreate struct {
+	BucketId string `json:"-" params:"bucket_id" example:"bucket_01HPG4GN5JY2Z6S0638ERSG375"`
+	//	`name` identifies the rule within its bucket and is required to create a lifecycle rule
+	Name string `json:"name" example:"expire-scratch-exports"`
+	//	`enabled` can be set to `false` to keep a rule without applying it. if set to `null` defaults to `true`
+	Enabled *bool `json:"enabled" default:"true" example:"true" extensions:"x-nullable"`
+	/*
+		`prefix`, `metadata` and `tags` filter the objects the rule applies to. only objects whose name starts with
+		`prefix`, whose metadata contains all the key value pairs of `metadata` and that have all of `tags` are
+		matched. if all of them are `null` the rule applies to every object of the bucket
+	*/
+	Prefix   *string           `json:"prefix" example:"exports/scratch/" extensions:"x-nullable"`
+	Metadata map[string]any    `json:"metadata" extensions:"x-nullable"`
+	Tags     map[string]string `json:"tags" example:"retention:short" extensions:"x-nullable"`
+	/*
+		`expire_after_days` deletes uploaded objects the given number of days after they were created and
+		`expire_after_days_since_last_access` deletes uploaded objects that have not been downloaded for the given
+		number of days. objects that were never downloaded are counted from their creation.
+		`abort_pending_upload_after_hours` aborts uploads that are still pending the given number of hours after they
+		were started. at least one of them is required to create a lifecycle rule
+	*/
+	ExpireAfterDays                *int32 `json:"expire_after_days" example:"7" extensions:"x-nullable"`
+	ExpireAfterDaysSinceLastAccess *int32 `json:"expire_after_days_since_last_access" example:"90" extensions:"x-nullable"`
+	AbortPendingUploadAfterHours   *int32 `json:"abort_pending_upload_after_hours" example:"24" extensions:"x-nullable"`
+}
+
+func (b *BucketLifecycleRuleCreate) IsValid() error {
+	if !IsNotEmptyTrimmedString(b.BucketId) {
+		return fmt.Errorf("bucket id cannot be empty. bucket id is required to create lifecycle rule")
+	}
+
+	return isValidBucketLifecycleRule(b.Name, b.Prefix, b.Tags, b.ExpireAfterDays, b.ExpireAfterDaysSinceLastAccess, b.AbortPendingUploadAfterHours)
+}
+
+func (b *BucketLifecycleRuleCreate) PreSave() {
+	b.Name = strings.TrimSpace(b.Name)
+
+	if b.Enabled == nil {
+		enabled := true
+		b.Enabled = &enabled
+	}
+}
+
+// BucketLifecycleRuleUpdate replaces every setting of a lifecycle rule, filters and actions set to `null` are removed
+type BucketLifecycleRuleUpdate struct {
+	Id       string `json:"-" params:"lifecycle_rule_id" example:"lifecycle_rule_01HPG4GN5JY2Z6S0638ERSG375"`
+	BucketId string `json:"-" params:"bucket_id" example:"bucket_01HPG4GN5JY2Z6S0638ERSG375"`
+	//	`name` identifies the rule within its bucket and is required to update a lifecycle rule
+	Name string `json:"name" example:"expire-scratch-exports"`
+	//	`enabled` can be set to `false` to keep a rule without applying it. if set to `null` defaults to `true`
+	Enabled *bool `json:"enabled" default:"true" example:"true" extensions:"x-nullable"`
+	/*
+		`prefix`, `metadata` and `tags` filter the objects the rule applies to. only objects whose name starts with
+		`prefix`, whose metadata contains all the key value pairs of `metadata` and that have all of `tags` are
+		matched. if all of them are `null` the rule applies to every object of the bucket
+	*/
+	Prefix   *string           `json:"prefix" example:"exports/scratch/" extensions:"x-nullable"`
+	Metadata map[string]any    `json:"metadata" extensions:"x-nullable"`
+	Tags     map[string]string `json:"tags" example:"retention:short" extensions:"x-nullable"`
+	/*
+		`expire_after_days` deletes uploaded objects the given number of days after they were created and
+		`expire_after_days_since_last_access` deletes uploaded objects that have not been downloaded for the given
+		number of days. objects that were never downloaded are counted from their creation.
+		`abort_pending_upload_after_hours` aborts uploads that are still pending the given number of hours after they
+		were started. at least one of them is required to update a lifecycle rule
+	*/
+	ExpireAfterDays                *int32 `json:"expire_after_days" example:"7" extensions:"x-nullable"`
+	ExpireAfterDaysSinceLastAccess *int32 `json:"expire_after_days_since_last_access" example:"90" extensions:"x-nullable"`
+	AbortPendingUploadAfterHours   *int32 `json:"abort_pending_upload_after_hours" example:"24" extensions:"x-nullable"`
+}
+
+func (b *BucketLifecycleRuleUpdate) IsValid() error {
+	if !IsNotEmptyTrimmedString(b.Id) {
+		return fmt.Errorf("lifecycle rule id cannot be empty. lifecycle rule id is required to update lifecycle rule")
+	}
+
+	if !IsNotEmptyTrimmedString(b.BucketId) {
+		return fmt.Errorf("bucket id cannot be empty. bucket id is required to update lifecycle rule")
+	}
+
+	return isValidBucketLifecycleRule(b.Name, b.Prefix, b.Tags, b.ExpireAfterDays, b.ExpireAfterDaysSinceLastAccess, b.AbortPendingUploadAfterHours)
+}
+
+func (b *BucketLifecycleRuleUpdate) PreSave() {
+	b.Name = strings.TrimSpace(b.Name)
+
+	if b.Enabled == nil {
+		enabled := true
+		b.Enabled = &enabled
+	}
+}
+
+func isValidBucketLifecycleRule(name string, prefix *string, tags map[string]string, expireAfterDays *int32, expireAfterDaysSinceLastAccess *int32, abortPendingUploadAfterHours *int32) error {
+	if !IsNotEmptyTrimmedString(name) {
+		return fmt.Errorf("lifecycle rule name cannot be empty. lifecycle rule name is required")
+	}
+
+	if prefix != nil && *prefix == "" {
+		return fmt.Errorf("lifecycle rule prefix cannot be empty. set prefix to null to match objects with any name")
+	}
+
+	if err := IsValidObjectTags(tags); err != nil {
+		return err
+	}
+
+	if expireAfterDays == nil && expireAfterDaysSinceLastAccess == nil && abortPendingUploadAfterHours == nil {
+		return fmt.Errorf("lifecycle rule has no action. expire_after_days, expire_after_days_since_last_access or abort_pending_upload_after_hours is required")
+	}
+
+	if expireAfterDays != nil && *expireAfterDays <= 0 {
+		return fmt.Errorf("lifecycle rule expire_after_days must be greater than 0")
+	}
+
+	if expireAfterDaysSinceLastAccess != nil && *expireAfterDaysSinceLastAccess <= 0 {
+		return fmt.Errorf("lifecycle rule expire_after_days_since_last_access must be greater than 0")
+	}
+
+	if abortPendingUploadAfterHours != nil && *abortPendingUploadAfterHours <= 0 {
+		return fmt.Errorf("lifecycle rule abort_pending_upload_after_hours must be greater than 0")
+	}
+
+	return nil
+}
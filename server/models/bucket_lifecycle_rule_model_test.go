@@ -0,0 +1,198 @@
+package models
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBucketLifecycleRuleCreate_IsValid(t *testing.T) {
+	tests := []struct {
+		name          string
+		lifecycleRule *BucketLifecycleRuleCreate
+		expected      error
+	}{
+		{
+			name: "Valid BucketLifecycleRuleCreate",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "expire-scratch-exports",
+				Prefix: func() *string {
+					v := "exports/scratch/"
+					return &v
+				}(),
+				ExpireAfterDays: func() *int32 {
+					v := int32(7)
+					return &v
+				}(),
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid BucketLifecycleRuleCreate (Metadata Filter)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "expire-cold-files",
+				Metadata: map[string]any{"tier": "cold"},
+				ExpireAfterDaysSinceLastAccess: func() *int32 {
+					v := int32(90)
+					return &v
+				}(),
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (Empty Bucket Id)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				Name: "abort-pending-uploads",
+				AbortPendingUploadAfterHours: func() *int32 {
+					v := int32(24)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("bucket id cannot be empty. bucket id is required to create lifecycle rule"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (Empty Name)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     " ",
+				AbortPendingUploadAfterHours: func() *int32 {
+					v := int32(24)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("lifecycle rule name cannot be empty. lifecycle rule name is required"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (Empty Prefix)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "expire-scratch-exports",
+				Prefix: func() *string {
+					v := ""
+					return &v
+				}(),
+				ExpireAfterDays: func() *int32 {
+					v := int32(7)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("lifecycle rule prefix cannot be empty. set prefix to null to match objects with any name"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (Invalid Tag Key)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "expire-scratch-exports",
+				Tags:     map[string]string{"retention=short": "true"},
+				ExpireAfterDays: func() *int32 {
+					v := int32(7)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("invalid tag key 'retention=short'. tag key must be at most 128 characters of letters, numbers, spaces and '+ - . _ : / @'"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (No Action)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "expire-scratch-exports",
+			},
+			expected: fmt.Errorf("lifecycle rule has no action. expire_after_days, expire_after_days_since_last_access or abort_pending_upload_after_hours is required"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (Zero Expire After Days)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "expire-scratch-exports",
+				ExpireAfterDays: func() *int32 {
+					v := int32(0)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("lifecycle rule expire_after_days must be greater than 0"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (Negative Expire After Days Since Last Access)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "expire-cold-files",
+				ExpireAfterDaysSinceLastAccess: func() *int32 {
+					v := int32(-1)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("lifecycle rule expire_after_days_since_last_access must be greater than 0"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleCreate (Zero Abort Pending Upload After Hours)",
+			lifecycleRule: &BucketLifecycleRuleCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "abort-pending-uploads",
+				AbortPendingUploadAfterHours: func() *int32 {
+					v := int32(0)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("lifecycle rule abort_pending_upload_after_hours must be greater than 0"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.lifecycleRule.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestBucketLifecycleRuleUpdate_IsValid(t *testing.T) {
+	tests := []struct {
+		name          string
+		lifecycleRule *BucketLifecycleRuleUpdate
+		expected      error
+	}{
+		{
+			name: "Valid BucketLifecycleRuleUpdate",
+			lifecycleRule: &BucketLifecycleRuleUpdate{
+				Id:       "lifecycle_rule_01HPG4GN5JY2Z6S0638ERSG375",
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "abort-pending-uploads",
+				AbortPendingUploadAfterHours: func() *int32 {
+					v := int32(24)
+					return &v
+				}(),
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid BucketLifecycleRuleUpdate (Empty Id)",
+			lifecycleRule: &BucketLifecycleRuleUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "abort-pending-uploads",
+				AbortPendingUploadAfterHours: func() *int32 {
+					v := int32(24)
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("lifecycle rule id cannot be empty. lifecycle rule id is required to update lifecycle rule"),
+		},
+		{
+			name: "Invalid BucketLifecycleRuleUpdate (No Action)",
+			lifecycleRule: &BucketLifecycleRuleUpdate{
+				Id:       "lifecycle_rule_01HPG4GN5JY2Z6S0638ERSG375",
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "abort-pending-uploads",
+			},
+			expected: fmt.Errorf("lifecycle rule has no action. expire_after_days, expire_after_days_since_last_access or abort_pending_upload_after_hours is required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.lifecycleRule.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
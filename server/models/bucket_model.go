@@ -12,6 +12,14 @@ const (
 	BucketLockedReasonBucketEmptying = "bucket.emptying"
 
 	BucketAllowedMimeTypesWildcard = "*/*"
+
+	BucketDefaultTrashRetentionDays = 7
+
+	BucketDefaultBackend = "default"
+
+	BucketEncryptionSSES3  = "sse-s3"
+	BucketEncryptionSSEKMS = "sse-kms"
+	BucketEncryptionSSEC   = "sse-c"
 )
 
 type Bucket struct {
@@ -20,6 +28,13 @@ type Bucket struct {
 	Name                 string     `json:"name" example:"avatar"`
 	AllowedMimeTypes     []string   `json:"allowed_mime_types" example:"image/jpeg, image/png, video/mp4, audio/wav"`
 	MaxAllowedObjectSize *int64     `json:"max_allowed_object_size" example:"10485760" extensions:"x-nullable"`
+	MaxTotalSize         *int64     `json:"max_total_size" example:"10737418240" extensions:"x-nullable"`
+	MaxObjectCount       *int64     `json:"max_object_count" example:"10000" extensions:"x-nullable"`
+	TrashRetentionDays   int32      `json:"trash_retention_days" example:"7"`
+	Backend              string     `json:"backend" example:"default"`
+	Encryption           *string    `json:"encryption" enum:"sse-s3,sse-kms,sse-c" example:"sse-kms" extensions:"x-nullable"`
+	EncryptionKmsKeyId   *string    `json:"encryption_kms_key_id" example:"arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab" extensions:"x-nullable"`
+	Encrypted            bool       `json:"encrypted" example:"false"`
 	Public               bool       `json:"public" example:"false"`
 	Disabled             bool       `json:"disabled" example:"false"`
 	Locked               bool       `json:"locked" example:"false"`
@@ -29,10 +44,15 @@ type Bucket struct {
 	UpdatedAt            *time.Time `json:"updated_at" default:"2024-02-13T08:18:21.47635+05:30" extensions:"x-nullable"`
 }
 
+// BucketSize is the usage of a bucket against its quotas. pending uploads are counted as they reserve their declared size,
+// objects in the trash are not counted
 type BucketSize struct {
-	Id   string `json:"id" example:"bucket_01HPG4GN5JY2Z6S0638ERSG375"`
-	Name string `json:"name" example:"avatar"`
-	Size int64  `json:"size" example:"10737418240"`
+	Id             string `json:"id" example:"bucket_01HPG4GN5JY2Z6S0638ERSG375"`
+	Name           string `json:"name" example:"avatar"`
+	Size           int64  `json:"size" example:"5368709120"`
+	MaxTotalSize   *int64 `json:"max_total_size" example:"10737418240" extensions:"x-nullable"`
+	ObjectCount    int64  `json:"object_count" example:"2500"`
+	MaxObjectCount *int64 `json:"max_object_count" example:"10000" extensions:"x-nullable"`
 }
 
 type BucketCreate struct {
@@ -48,13 +68,48 @@ type BucketCreate struct {
 	//	if a list mime types are being sent all of them should be valid.
 	//	also if allowed content types are being set it can't include wild card with all other content types like `["*/*", "video/mp4", "audio/wav"]`
 	// 	this will be invalid if wild card is going to be set it should only be used by itself like `["*/*"]`
-	AllowedMimeTypes []string `json:"allowed_mime_types" example:"image/jpeg, image/png, video/mp4, audio/wav" extensions:"x-nullable"`
+	//	`allowed_mime_types` can contain wildcards like `image/*` for a type family or `application/*+json` for a structured syntax suffix
+	//	binary content whose type cannot be detected from its content is only accepted when `application/octet-stream` is allowed
+	AllowedMimeTypes []string `json:"allowed_mime_types" example:"image/*, video/mp4, application/*+json" extensions:"x-nullable"`
 	/*
 		`max_allowed_object_size` should be the max size of an object allowed to be uploaded into a bucket.
 		the max allowed size should be defined in `bytes`. if it's set to `null` the system will infer this as there
 		is no upper limit to the object size that can be uploaded
 	*/
 	MaxAllowedObjectSize *int64 `json:"max_allowed_object_size" example:"10485760" extensions:"x-nullable"`
+	/*
+		`max_total_size` and `max_object_count` are quotas for the total size in `bytes` and the number of objects
+		of a bucket. uploads that would exceed a quota are rejected. if set to `null` there is no quota
+	*/
+	MaxTotalSize   *int64 `json:"max_total_size" example:"10737418240" extensions:"x-nullable"`
+	MaxObjectCount *int64 `json:"max_object_count" example:"10000" extensions:"x-nullable"`
+	/*
+		`trash_retention_days` is the number of days deleted objects are kept in the trash of the bucket, where they can
+		be restored, before they are purged. if set to 0 objects are deleted right away. if set to `null` defaults to `7`
+	*/
+	TrashRetentionDays *int32 `json:"trash_retention_days" example:"7" extensions:"x-nullable"`
+	/*
+		`backend` is the name of the storage backend declared in the config the objects of the bucket are kept in,
+		like an s3 bucket in another region. it cannot be changed once the bucket is created. if set to `null`
+		defaults to `default`
+	*/
+	Backend *string `json:"backend" example:"eu" extensions:"x-nullable"`
+	/*
+		`encryption` is the server-side encryption applied to every object of the bucket, one of `sse-s3`, `sse-kms`
+		or `sse-c`. with `sse-c` every request that uploads or downloads an object has to send the customer key in the
+		`X-Encryption-Customer-Key` header. it is only supported by s3 backends and cannot be changed once the bucket is
+		created. if set to `null` objects are left to the default encryption of the s3 bucket
+	*/
+	Encryption *string `json:"encryption" enum:"sse-s3,sse-kms,sse-c" example:"sse-kms" extensions:"x-nullable"`
+	//	`encryption_kms_key_id` is the kms key used with `sse-kms` encryption. if set to `null` the aws managed key is used
+	EncryptionKmsKeyId *string `json:"encryption_kms_key_id" example:"arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab" extensions:"x-nullable"`
+	/*
+		`encrypted` can be true or false. if encrypted is true the content of every object is encrypted by the service
+		with a data key of its own before it is written to the storage backend, so objects can only be uploaded and
+		downloaded through the server and not with pre-signed urls. it requires an encryption master key in the config
+		and cannot be changed once the bucket is created. if set to `null` defaults to `false`
+	*/
+	Encrypted bool `json:"encrypted" default:"false" example:"false" extensions:"x-nullable"`
 	/*
 		`public` can be true or false. if public is true the bucket will accessible publicly without authentication.
 		if public is false the bucket will only accessible with authentication. if set to `null` defaults to `false`
@@ -80,13 +135,13 @@ func (b *BucketCreate) IsValid() error {
 
 		var invalidMimeTypes []string
 		for _, allowedMimeType := range b.AllowedMimeTypes {
-			if !IsValidMimeType(allowedMimeType) {
+			if !IsValidMimeTypePattern(allowedMimeType) {
 				invalidMimeTypes = append(invalidMimeTypes, allowedMimeType)
 			}
 		}
 
 		if len(invalidMimeTypes) > 0 {
-			return fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [%s]. allowed mime types must be in the format 'type/subtype'", strings.Join(invalidMimeTypes, ", "))
+			return fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [%s]. allowed mime types must be in the format 'type/subtype', 'type/*' or 'type/*+suffix'", strings.Join(invalidMimeTypes, ", "))
 		}
 	}
 
@@ -96,6 +151,46 @@ func (b *BucketCreate) IsValid() error {
 		}
 	}
 
+	if b.MaxTotalSize != nil {
+		if *b.MaxTotalSize <= 0 {
+			return fmt.Errorf("bucket max_total_size must be greater than 0")
+		}
+	}
+
+	if b.MaxObjectCount != nil {
+		if *b.MaxObjectCount <= 0 {
+			return fmt.Errorf("bucket max_object_count must be greater than 0")
+		}
+	}
+
+	if b.TrashRetentionDays != nil {
+		if *b.TrashRetentionDays < 0 {
+			return fmt.Errorf("bucket trash_retention_days cannot be negative")
+		}
+	}
+
+	if b.Backend != nil {
+		if !IsNotEmptyTrimmedString(*b.Backend) {
+			return fmt.Errorf("bucket backend cannot be empty")
+		}
+	}
+
+	if b.Encryption != nil {
+		if !lo.Contains([]string{BucketEncryptionSSES3, BucketEncryptionSSEKMS, BucketEncryptionSSEC}, strings.ToLower(strings.TrimSpace(*b.Encryption))) {
+			return fmt.Errorf("bucket encryption is not valid. it must be one of 'sse-s3', 'sse-kms' or 'sse-c'")
+		}
+	}
+
+	if b.EncryptionKmsKeyId != nil {
+		if !IsNotEmptyTrimmedString(*b.EncryptionKmsKeyId) {
+			return fmt.Errorf("bucket encryption_kms_key_id cannot be empty")
+		}
+
+		if b.Encryption == nil || strings.ToLower(strings.TrimSpace(*b.Encryption)) != BucketEncryptionSSEKMS {
+			return fmt.Errorf("bucket encryption_kms_key_id can only be set with 'sse-kms' encryption")
+		}
+	}
+
 	return nil
 }
 
@@ -105,6 +200,26 @@ func (b *BucketCreate) PreSave() {
 	if b.AllowedMimeTypes == nil {
 		b.AllowedMimeTypes = []string{BucketAllowedMimeTypesWildcard}
 	}
+
+	if b.TrashRetentionDays == nil {
+		trashRetentionDays := int32(BucketDefaultTrashRetentionDays)
+		b.TrashRetentionDays = &trashRetentionDays
+	}
+
+	// backend names are case insensitive, the names declared in the config are lowercased when it is read
+	if b.Backend == nil {
+		b.Backend = lo.ToPtr(BucketDefaultBackend)
+	} else {
+		b.Backend = lo.ToPtr(strings.ToLower(strings.TrimSpace(*b.Backend)))
+	}
+
+	if b.Encryption != nil {
+		b.Encryption = lo.ToPtr(strings.ToLower(strings.TrimSpace(*b.Encryption)))
+	}
+
+	if b.EncryptionKmsKeyId != nil {
+		b.EncryptionKmsKeyId = lo.ToPtr(strings.TrimSpace(*b.EncryptionKmsKeyId))
+	}
 }
 
 type BucketUpdate struct {
@@ -115,13 +230,34 @@ type BucketUpdate struct {
 	//	also if allowed content types are being set it can't include wild card with all other content types like `["*/*", "video/mp4", "audio/wav"]`
 	// 	this will be invalid if wild card is going to be set it should only be used by itself like `["*/*"]`. if the new allowed_content_types are valid
 	//  they will replace the previously defined allowed_mime_types
-	AllowedMimeTypes []string `json:"allowed_mime_types" example:"image/jpeg, image/png, video/mp4, audio/wav" extensions:"x-nullable"`
+	//	`allowed_mime_types` can contain wildcards like `image/*` for a type family or `application/*+json` for a structured syntax suffix
+	//	binary content whose type cannot be detected from its content is only accepted when `application/octet-stream` is allowed
+	AllowedMimeTypes []string `json:"allowed_mime_types" example:"image/*, video/mp4, application/*+json" extensions:"x-nullable"`
 	/*
 		`max_allowed_object_size` should be the max size of an object allowed to be uploaded into a bucket.
-		the max allowed size should be defined in `bytes`. if it's set to `null` the system will infer this as there
-		is no upper limit to the object size that can be uploaded
+		the max allowed size should be defined in `bytes`. if it's set to `null` the max allowed size is left unchanged,
+		set `clear_max_allowed_object_size` to remove the upper limit to the object size that can be uploaded
 	*/
 	MaxAllowedObjectSize *int64 `json:"max_allowed_object_size" example:"10485760" extensions:"x-nullable"`
+	/*
+		`max_total_size` and `max_object_count` are quotas for the total size in `bytes` and the number of objects
+		of a bucket. uploads that would exceed a quota are rejected. if set to `null` the quota is left unchanged,
+		set `clear_max_total_size` or `clear_max_object_count` to remove the quota
+	*/
+	MaxTotalSize   *int64 `json:"max_total_size" example:"10737418240" extensions:"x-nullable"`
+	MaxObjectCount *int64 `json:"max_object_count" example:"10000" extensions:"x-nullable"`
+	/*
+		`clear_max_allowed_object_size`, `clear_max_total_size` and `clear_max_object_count` remove the limit, which
+		cannot be set in the same update
+	*/
+	ClearMaxAllowedObjectSize bool `json:"clear_max_allowed_object_size" example:"false"`
+	ClearMaxTotalSize         bool `json:"clear_max_total_size" example:"false"`
+	ClearMaxObjectCount       bool `json:"clear_max_object_count" example:"false"`
+	/*
+		`trash_retention_days` is the number of days deleted objects are kept in the trash of the bucket, where they can
+		be restored, before they are purged. if set to 0 objects are deleted right away. if set to `null` the retention is left unchanged
+	*/
+	TrashRetentionDays *int32 `json:"trash_retention_days" example:"7" extensions:"x-nullable"`
 	/*
 		`public` can be true or false. if public is true the bucket will accessible publicly without authentication.
 		if public is false the bucket will only accessible with authentication. if set to `null` defaults to `false`
@@ -143,13 +279,13 @@ func (b *BucketUpdate) IsValid() error {
 
 		var invalidMimeTypes []string
 		for _, allowedMimeType := range b.AllowedMimeTypes {
-			if !IsValidMimeType(allowedMimeType) {
+			if !IsValidMimeTypePattern(allowedMimeType) {
 				invalidMimeTypes = append(invalidMimeTypes, allowedMimeType)
 			}
 		}
 
 		if len(invalidMimeTypes) > 0 {
-			return fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [%s], allowed mime types must be in the format 'type/subtype'", strings.Join(invalidMimeTypes, ", "))
+			return fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [%s], allowed mime types must be in the format 'type/subtype', 'type/*' or 'type/*+suffix'", strings.Join(invalidMimeTypes, ", "))
 		}
 	}
 
@@ -159,5 +295,35 @@ func (b *BucketUpdate) IsValid() error {
 		}
 	}
 
+	if b.MaxTotalSize != nil {
+		if *b.MaxTotalSize <= 0 {
+			return fmt.Errorf("bucket max_total_size must be greater than 0")
+		}
+	}
+
+	if b.MaxObjectCount != nil {
+		if *b.MaxObjectCount <= 0 {
+			return fmt.Errorf("bucket max_object_count must be greater than 0")
+		}
+	}
+
+	if b.ClearMaxAllowedObjectSize && b.MaxAllowedObjectSize != nil {
+		return fmt.Errorf("bucket max_allowed_object_size cannot be set and cleared at the same time")
+	}
+
+	if b.ClearMaxTotalSize && b.MaxTotalSize != nil {
+		return fmt.Errorf("bucket max_total_size cannot be set and cleared at the same time")
+	}
+
+	if b.ClearMaxObjectCount && b.MaxObjectCount != nil {
+		return fmt.Errorf("bucket max_object_count cannot be set and cleared at the same time")
+	}
+
+	if b.TrashRetentionDays != nil {
+		if *b.TrashRetentionDays < 0 {
+			return fmt.Errorf("bucket trash_retention_days cannot be negative")
+		}
+	}
+
 	return nil
 }
@@ -45,7 +45,7 @@ func TestBucketCreate_IsValid(t *testing.T) {
 				Name:             "avatar",
 				AllowedMimeTypes: []string{"invalid-mime-type"},
 			},
-			expected: fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [invalid-mime-type]. allowed mime types must be in the format 'type/subtype'"),
+			expected: fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [invalid-mime-type]. allowed mime types must be in the format 'type/subtype', 'type/*' or 'type/*+suffix'"),
 		},
 		{
 			name: "Invalid BucketCreate (Invalid MIME Type with Wildcard)",
@@ -73,6 +73,72 @@ func TestBucketCreate_IsValid(t *testing.T) {
 			},
 			expected: fmt.Errorf("bucket max_allowed_object_size must be greater than 0"),
 		},
+		{
+			name: "Invalid BucketCreate (Zero Max Total Size)",
+			bucket: &BucketCreate{
+				Name:         "avatar",
+				MaxTotalSize: func() *int64 { v := int64(0); return &v }(),
+			},
+			expected: fmt.Errorf("bucket max_total_size must be greater than 0"),
+		},
+		{
+			name: "Invalid BucketCreate (Negative Max Object Count)",
+			bucket: &BucketCreate{
+				Name:           "avatar",
+				MaxObjectCount: func() *int64 { v := int64(-1); return &v }(),
+			},
+			expected: fmt.Errorf("bucket max_object_count must be greater than 0"),
+		},
+		{
+			name: "Invalid BucketCreate (Negative Trash Retention Days)",
+			bucket: &BucketCreate{
+				Name:               "avatar",
+				TrashRetentionDays: func() *int32 { v := int32(-1); return &v }(),
+			},
+			expected: fmt.Errorf("bucket trash_retention_days cannot be negative"),
+		},
+		{
+			name: "Invalid BucketCreate (Empty Backend)",
+			bucket: &BucketCreate{
+				Name:    "avatar",
+				Backend: func() *string { v := " "; return &v }(),
+			},
+			expected: fmt.Errorf("bucket backend cannot be empty"),
+		},
+		{
+			name: "Invalid BucketCreate (Unknown Encryption)",
+			bucket: &BucketCreate{
+				Name:       "avatar",
+				Encryption: func() *string { v := "aes"; return &v }(),
+			},
+			expected: fmt.Errorf("bucket encryption is not valid. it must be one of 'sse-s3', 'sse-kms' or 'sse-c'"),
+		},
+		{
+			name: "Invalid BucketCreate (KMS Key Id Without SSE-KMS)",
+			bucket: &BucketCreate{
+				Name:               "avatar",
+				Encryption:         func() *string { v := "sse-s3"; return &v }(),
+				EncryptionKmsKeyId: func() *string { v := "alias/storage"; return &v }(),
+			},
+			expected: fmt.Errorf("bucket encryption_kms_key_id can only be set with 'sse-kms' encryption"),
+		},
+		{
+			name: "Valid BucketCreate (SSE-KMS With Key Id)",
+			bucket: &BucketCreate{
+				Name:               "avatar",
+				Encryption:         func() *string { v := "SSE-KMS"; return &v }(),
+				EncryptionKmsKeyId: func() *string { v := "alias/storage"; return &v }(),
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid BucketCreate (Zero Trash Retention Days)",
+			bucket: &BucketCreate{
+				Name:               "avatar",
+				TrashRetentionDays: func() *int32 { v := int32(0); return &v }(),
+			},
+			expected: nil,
+		},
 		{
 			name: "Valid BucketCreate (Null Max Allowed Object Size)",
 			bucket: &BucketCreate{
@@ -139,7 +205,7 @@ func TestBucketUpdate_IsValid(t *testing.T) {
 				Id:               "bucket_01HPG4GN5JY2Z6S0638ERSG375",
 				AllowedMimeTypes: []string{"invalid-mime-type"},
 			},
-			expected: fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [invalid-mime-type], allowed mime types must be in the format 'type/subtype'"),
+			expected: fmt.Errorf("bucket allowed_mime_types is not valid. invalid mime types: [invalid-mime-type], allowed mime types must be in the format 'type/subtype', 'type/*' or 'type/*+suffix'"),
 		},
 		{
 			name: "Invalid BucketUpdate (Invalid MIME Type with Wildcard)",
@@ -167,6 +233,41 @@ func TestBucketUpdate_IsValid(t *testing.T) {
 			},
 			expected: fmt.Errorf("bucket max_allowed_object_size must be greater than 0"),
 		},
+		{
+			name: "Invalid BucketUpdate (Zero Max Object Count)",
+			bucket: &BucketUpdate{
+				Id:             "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MaxObjectCount: func() *int64 { v := int64(0); return &v }(),
+			},
+			expected: fmt.Errorf("bucket max_object_count must be greater than 0"),
+		},
+		{
+			name: "Invalid BucketUpdate (Negative Trash Retention Days)",
+			bucket: &BucketUpdate{
+				Id:                 "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				TrashRetentionDays: func() *int32 { v := int32(-1); return &v }(),
+			},
+			expected: fmt.Errorf("bucket trash_retention_days cannot be negative"),
+		},
+		{
+			name: "Valid BucketUpdate (Clear Quotas)",
+			bucket: &BucketUpdate{
+				Id:                        "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ClearMaxAllowedObjectSize: true,
+				ClearMaxTotalSize:         true,
+				ClearMaxObjectCount:       true,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid BucketUpdate (Set And Clear Max Total Size)",
+			bucket: &BucketUpdate{
+				Id:                "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MaxTotalSize:      func() *int64 { v := int64(10737418240); return &v }(),
+				ClearMaxTotalSize: true,
+			},
+			expected: fmt.Errorf("bucket max_total_size cannot be set and cleared at the same time"),
+		},
 		{
 			name: "Valid BucketUpdate (Null Max Allowed Object Size)",
 			bucket: &BucketUpdate{
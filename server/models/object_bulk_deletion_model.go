@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"time"
+)
+
+const (
+	ObjectBulkDeletionStatusPending   = "pending"
+	ObjectBulkDeletionStatusRunning   = "running"
+	ObjectBulkDeletionStatusCompleted = "completed"
+
+	// ObjectBulkDeletionMaxObjectIds is the maximum number of object ids a single bulk deletion accepts
+	ObjectBulkDeletionMaxObjectIds = 10000
+	// ObjectBulkDeletionMaxReportedFailures is the maximum number of failures kept on a bulk deletion, further
+	// failures are only counted in `failed_count`
+	ObjectBulkDeletionMaxReportedFailures = 1000
+)
+
+// ObjectBulkDeletion tracks the progress of a bulk deletion. `total_count` is the number of objects selected when the
+// bulk deletion was created, `deleted_count` and `failed_count` grow as the objects are deleted in batches.
+// `permanent` is set when the objects are deleted permanently instead of being moved to the trash of the bucket
+type ObjectBulkDeletion struct {
+	Id           string                       `json:"id" example:"bulk_deletion_01HPG4GN5JY2Z6S0638ERSG375"`
+	Version      int32                        `json:"version" example:"0"`
+	BucketId     string                       `json:"bucket_id" example:"bucket_01HPG4GN5JY2Z6S0638ERSG375"`
+	Prefix       *string                      `json:"prefix" example:"exports/scratch/" extensions:"x-nullable"`
+	Tags         map[string]string            `json:"tags" example:"retention:short" extensions:"x-nullable"`
+	Permanent    bool                         `json:"permanent" example:"false"`
+	Status       string                       `json:"status" example:"running"`
+	TotalCount   int64                        `json:"total_count" example:"10000"`
+	DeletedCount int64                        `json:"deleted_count" example:"4000"`
+	FailedCount  int64                        `json:"failed_count" example:"1"`
+	Failures     []*ObjectBulkDeletionFailure `json:"failures"`
+	CreatedAt    time.Time                    `json:"created_at" default:"2024-02-13T08:14:49.952238+05:30"`
+	UpdatedAt    *time.Time                   `json:"updated_at" default:"2024-02-13T08:18:21.47635+05:30" extensions:"x-nullable"`
+	CompletedAt  *time.Time                   `json:"completed_at" default:"2024-02-13T08:18:21.47635+05:30" extensions:"x-nullable"`
+}
+
+type ObjectBulkDeletionFailure struct {
+	ObjectId string  `json:"object_id" example:"object_01HPG4GN5JY2Z6S0638ERSG375"`
+	Name     *string `json:"name" example:"exports/scratch/report.csv" extensions:"x-nullable"`
+	Error    string  `json:"error" example:"AccessDenied: Access Denied"`
+}
+
+type ObjectBulkDeletionCreate struct {
+	BucketId string `json:"-" params:"bucket_id" example:"bucket_01HPG4GN5JY2Z6S0638ERSG375"`
+	/*
+		`object_ids` selects the uploaded objects to delete by id, ids of objects that do not exist are reported as
+		failures. without `object_ids` the uploaded objects whose name starts with `prefix` and that have all of
+		`tags` are deleted, and at least one of them is required
+	*/
+	ObjectIds []string          `json:"object_ids" example:"object_01HPG4GN5JY2Z6S0638ERSG375"`
+	Prefix    *string           `json:"prefix" example:"exports/scratch/" extensions:"x-nullable"`
+	Tags      map[string]string `json:"tags" example:"retention:short" extensions:"x-nullable"`
+	//	the objects are moved to the trash of the bucket like deleted objects unless `permanent` is set or the bucket
+	//	has a trash retention of 0 days, in which case they are deleted from storage right away
+	Permanent bool `json:"permanent" example:"false"`
+}
+
+func (o *ObjectBulkDeletionCreate) IsValid() error {
+	if !IsNotEmptyTrimmedString(o.BucketId) {
+		return fmt.Errorf("bucket id cannot be empty. bucket id is required to delete objects")
+	}
+
+	if (len(o.ObjectIds) == 0) == (o.Prefix == nil && len(o.Tags) == 0) {
+		return fmt.Errorf("either object_ids or a prefix or tags are required to delete objects")
+	}
+
+	if len(o.ObjectIds) > ObjectBulkDeletionMaxObjectIds {
+		return fmt.Errorf("too many object_ids. at most %d objects can be deleted by id at once", ObjectBulkDeletionMaxObjectIds)
+	}
+
+	for _, objectId := range o.ObjectIds {
+		if !IsNotEmptyTrimmedString(objectId) {
+			return fmt.Errorf("object id cannot be empty")
+		}
+	}
+
+	if o.Prefix != nil && *o.Prefix == "" {
+		return fmt.Errorf("prefix cannot be empty. empty the bucket to delete all of its objects")
+	}
+
+	if err := IsValidObjectTags(o.Tags); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *ObjectBulkDeletionCreate) PreSave() {
+	if len(o.ObjectIds) > 0 {
+		o.ObjectIds = lo.Uniq(o.ObjectIds)
+	}
+}
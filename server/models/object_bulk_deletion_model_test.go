@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestObjectBulkDeletionCreate_IsValid(t *testing.T) {
+	tests := []struct {
+		name         string
+		bulkDeletion *ObjectBulkDeletionCreate
+		expected     error
+	}{
+		{
+			name: "Valid ObjectBulkDeletionCreate (Object Ids)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId:  "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectIds: []string{"object_01HPG4GN5JY2Z6S0638ERSG375", "object_01HPG4GN5JY2Z6S0638ERSG376"},
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectBulkDeletionCreate (Prefix)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Prefix: func() *string {
+					v := "exports/scratch/"
+					return &v
+				}(),
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectBulkDeletionCreate (Prefix And Tags)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Prefix: func() *string {
+					v := "exports/scratch/"
+					return &v
+				}(),
+				Tags: map[string]string{"retention": "short"},
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectBulkDeletionCreate (Tags)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Tags:     map[string]string{"retention": "short"},
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid ObjectBulkDeletionCreate (No Selector)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+			},
+			expected: fmt.Errorf("either object_ids or a prefix or tags are required to delete objects"),
+		},
+		{
+			name: "Invalid ObjectBulkDeletionCreate (Object Ids And Prefix)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId:  "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectIds: []string{"object_01HPG4GN5JY2Z6S0638ERSG375"},
+				Prefix: func() *string {
+					v := "exports/scratch/"
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("either object_ids or a prefix or tags are required to delete objects"),
+		},
+		{
+			name: "Invalid ObjectBulkDeletionCreate (Object Ids And Tags)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId:  "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectIds: []string{"object_01HPG4GN5JY2Z6S0638ERSG375"},
+				Tags:      map[string]string{"retention": "short"},
+			},
+			expected: fmt.Errorf("either object_ids or a prefix or tags are required to delete objects"),
+		},
+		{
+			name: "Invalid ObjectBulkDeletionCreate (Too Many Object Ids)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId:  "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectIds: make([]string, ObjectBulkDeletionMaxObjectIds+1),
+			},
+			expected: fmt.Errorf("too many object_ids. at most %d objects can be deleted by id at once", ObjectBulkDeletionMaxObjectIds),
+		},
+		{
+			name: "Invalid ObjectBulkDeletionCreate (Empty Object Id)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId:  "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectIds: []string{"object_01HPG4GN5JY2Z6S0638ERSG375", " "},
+			},
+			expected: fmt.Errorf("object id cannot be empty"),
+		},
+		{
+			name: "Invalid ObjectBulkDeletionCreate (Empty Prefix)",
+			bulkDeletion: &ObjectBulkDeletionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Prefix: func() *string {
+					v := ""
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("prefix cannot be empty. empty the bucket to delete all of its objects"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.bulkDeletion.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestObjectBulkDeletionCreate_PreSave(t *testing.T) {
+	bulkDeletion := &ObjectBulkDeletionCreate{
+		ObjectIds: []string{"object_01HPG4GN5JY2Z6S0638ERSG375", "object_01HPG4GN5JY2Z6S0638ERSG376", "object_01HPG4GN5JY2Z6S0638ERSG375"},
+	}
+
+	bulkDeletion.PreSave()
+
+	assert.Equal(t, []string{"object_01HPG4GN5JY2Z6S0638ERSG375", "object_01HPG4GN5JY2Z6S0638ERSG376"}, bulkDeletion.ObjectIds)
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -82,6 +83,190 @@ func TestPreSignedUploadSessionCreate_IsValid(t *testing.T) {
 			},
 			expected: fmt.Errorf("expires in must be greater than 0"),
 		},
+		{
+			name: "Valid PreSignedUploadSessionCreate (SHA-256 Checksum)",
+			session: &PreSignedUploadSessionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/avatar.jpg",
+				Size:     1218077,
+				ChecksumAlgorithm: func() *string {
+					v := "sha256"
+					return &v
+				}(),
+				Checksum: func() *string {
+					v := "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg="
+					return &v
+				}(),
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid PreSignedUploadSessionCreate (Checksum Without Algorithm)",
+			session: &PreSignedUploadSessionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/avatar.jpg",
+				Size:     1218077,
+				Checksum: func() *string {
+					v := "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg="
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("checksum algorithm and checksum must be provided together"),
+		},
+		{
+			name: "Invalid PreSignedUploadSessionCreate (Unsupported Checksum Algorithm)",
+			session: &PreSignedUploadSessionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/avatar.jpg",
+				Size:     1218077,
+				ChecksumAlgorithm: func() *string {
+					v := "sha1"
+					return &v
+				}(),
+				Checksum: func() *string {
+					v := "qvTGHdzF6KLavt4PO0gs2a6pQ00="
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("invalid checksum algorithm 'sha1'. checksum algorithm must be one of 'sha256', 'crc32c' or 'md5'"),
+		},
+		{
+			name: "Invalid PreSignedUploadSessionCreate (Checksum Of Wrong Length)",
+			session: &PreSignedUploadSessionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/avatar.jpg",
+				Size:     1218077,
+				ChecksumAlgorithm: func() *string {
+					v := "crc32c"
+					return &v
+				}(),
+				Checksum: func() *string {
+					v := "XUFAKrxLKna5cZ2REBfFkg=="
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("invalid checksum 'XUFAKrxLKna5cZ2REBfFkg=='. checksum must be the base64 encoded 4 byte crc32c digest of the object"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.session.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestObjectUploadCreate_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		upload   *ObjectUploadCreate
+		expected error
+	}{
+		{
+			name: "Valid ObjectUploadCreate",
+			upload: &ObjectUploadCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/avatar.jpg",
+				MimeType: func() *string {
+					v := "image/jpeg"
+					return &v
+				}(),
+				Size: 1218077,
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectUploadCreate (Unknown Size)",
+			upload: &ObjectUploadCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/avatar.jpg",
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid ObjectUploadCreate (Empty BucketId)",
+			upload: &ObjectUploadCreate{
+				BucketId: "",
+				Name:     "user/david/avatar.jpg",
+			},
+			expected: fmt.Errorf("bucket id cannot be empty. bucket id is required to upload an object"),
+		},
+		{
+			name: "Invalid ObjectUploadCreate (Empty Name)",
+			upload: &ObjectUploadCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     " ",
+			},
+			expected: fmt.Errorf("object name cannot be empty. name is required to upload an object"),
+		},
+		{
+			name: "Invalid ObjectUploadCreate (Invalid Name)",
+			upload: &ObjectUploadCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/",
+			},
+			expected: fmt.Errorf("invalid object name 'user/david/'. object name cannot start or end with '/' and must be between 1 and 961 characters"),
+		},
+		{
+			name: "Invalid ObjectUploadCreate (Negative Size)",
+			upload: &ObjectUploadCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/avatar.jpg",
+				Size:     -1,
+			},
+			expected: fmt.Errorf("size cannot be less than 0"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.upload.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestMultipartUploadSessionCreate_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		session  *MultipartUploadSessionCreate
+		expected error
+	}{
+		{
+			name: "Valid MultipartUploadSessionCreate",
+			session: &MultipartUploadSessionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/video.mp4",
+				Size:     3221225472,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid MultipartUploadSessionCreate (Zero Size)",
+			session: &MultipartUploadSessionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/video.mp4",
+			},
+			expected: fmt.Errorf("size must be greater than 0"),
+		},
+		{
+			name: "Invalid MultipartUploadSessionCreate (Checksum)",
+			session: &MultipartUploadSessionCreate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Name:     "user/david/video.mp4",
+				Size:     3221225472,
+				ChecksumAlgorithm: func() *string {
+					v := "sha256"
+					return &v
+				}(),
+				Checksum: func() *string {
+					v := "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg="
+					return &v
+				}(),
+			},
+			expected: fmt.Errorf("checksums are not supported for multipart upload sessions. use a pre-signed upload session to upload an object with a checksum"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,3 +276,393 @@ func TestPreSignedUploadSessionCreate_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestMultipartUploadSessionComplete_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		complete *MultipartUploadSessionComplete
+		expected error
+	}{
+		{
+			name: "Valid MultipartUploadSessionComplete",
+			complete: &MultipartUploadSessionComplete{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Parts: []*MultipartUploadCompletedPart{
+					{PartNumber: 1, ETag: "\"5d41402abc4b2a76b9719d911017c592\""},
+					{PartNumber: 2, ETag: "\"7d793037a0760186574b0282f2f435e7\""},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid MultipartUploadSessionComplete (Empty Parts)",
+			complete: &MultipartUploadSessionComplete{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+			},
+			expected: fmt.Errorf("parts cannot be empty. at least one uploaded part is required to complete a multipart upload session"),
+		},
+		{
+			name: "Invalid MultipartUploadSessionComplete (Unordered Parts)",
+			complete: &MultipartUploadSessionComplete{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Parts: []*MultipartUploadCompletedPart{
+					{PartNumber: 2, ETag: "\"7d793037a0760186574b0282f2f435e7\""},
+					{PartNumber: 1, ETag: "\"5d41402abc4b2a76b9719d911017c592\""},
+				},
+			},
+			expected: fmt.Errorf("parts must be in ascending order of part number without duplicates"),
+		},
+		{
+			name: "Invalid MultipartUploadSessionComplete (Empty ETag)",
+			complete: &MultipartUploadSessionComplete{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Parts: []*MultipartUploadCompletedPart{
+					{PartNumber: 1, ETag: " "},
+				},
+			},
+			expected: fmt.Errorf("etag of part 1 cannot be empty"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.complete.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestObjectMove_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		move     *ObjectMove
+		expected error
+	}{
+		{
+			name: "Valid ObjectMove",
+			move: &ObjectMove{
+				BucketId:        "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId:        "object_01HPG4GN5JY2Z6S0638ERSG375",
+				DestinationName: "archive/user/david/avatar.jpg",
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectMove (Destination Bucket)",
+			move: &ObjectMove{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				DestinationBucketId: func() *string {
+					v := "bucket_01HQ6M9V0ZP2TQ1YB3JTV4S0XK"
+					return &v
+				}(),
+				DestinationName: "user/david/avatar.jpg",
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid ObjectMove (Empty Destination Bucket)",
+			move: &ObjectMove{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				DestinationBucketId: func() *string {
+					v := " "
+					return &v
+				}(),
+				DestinationName: "user/david/avatar.jpg",
+			},
+			expected: fmt.Errorf("destination bucket id cannot be empty. omit destination bucket id to move an object within the same bucket"),
+		},
+		{
+			name: "Invalid ObjectMove (Invalid Destination Name)",
+			move: &ObjectMove{
+				BucketId:        "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId:        "object_01HPG4GN5JY2Z6S0638ERSG375",
+				DestinationName: "/archive/",
+			},
+			expected: fmt.Errorf("invalid destination name '/archive/'. object name cannot start or end with '/' and must be between 1 and 961 characters"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.move.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestObjectUpdate_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   *ObjectUpdate
+		expected error
+	}{
+		{
+			name: "Valid ObjectUpdate (Merge Metadata)",
+			update: &ObjectUpdate{
+				BucketId:     "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId:     "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Metadata:     map[string]any{"album": "summer"},
+				MetadataMode: ObjectMetadataUpdateModeMerge,
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectUpdate (Clear Metadata)",
+			update: &ObjectUpdate{
+				BucketId:     "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId:     "object_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataMode: ObjectMetadataUpdateModeReplace,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid ObjectUpdate (Invalid Mime Type)",
+			update: &ObjectUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				MimeType: func() *string {
+					v := "png"
+					return &v
+				}(),
+				MetadataMode: ObjectMetadataUpdateModeMerge,
+			},
+			expected: fmt.Errorf("invalid mime type 'png'. mime type must be in the format 'type/subtype'"),
+		},
+		{
+			name: "Invalid ObjectUpdate (Invalid Metadata Mode)",
+			update: &ObjectUpdate{
+				BucketId:     "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId:     "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Metadata:     map[string]any{"album": "summer"},
+				MetadataMode: "patch",
+			},
+			expected: fmt.Errorf("invalid metadata mode 'patch'. metadata mode must be either 'merge' or 'replace'"),
+		},
+		{
+			name: "Invalid ObjectUpdate (Nothing To Update)",
+			update: &ObjectUpdate{
+				BucketId:     "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId:     "object_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataMode: ObjectMetadataUpdateModeMerge,
+			},
+			expected: fmt.Errorf("nothing to update. mime type or metadata is required to update an object"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.update.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestObjectTagsUpdate_IsValid(t *testing.T) {
+	tooManyTags := map[string]string{}
+	for i := 0; i <= ObjectTagsMaxCount; i++ {
+		tooManyTags[fmt.Sprintf("key-%d", i)] = "value"
+	}
+
+	tests := []struct {
+		name     string
+		update   *ObjectTagsUpdate
+		expected error
+	}{
+		{
+			name: "Valid ObjectTagsUpdate",
+			update: &ObjectTagsUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Tags:     map[string]string{"project": "avatars", "team/owner": "identity@example.com", "empty": ""},
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectTagsUpdate (Remove Tags)",
+			update: &ObjectTagsUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid ObjectTagsUpdate (Too Many Tags)",
+			update: &ObjectTagsUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Tags:     tooManyTags,
+			},
+			expected: fmt.Errorf("too many tags. an object can have at most 10 tags"),
+		},
+		{
+			name: "Invalid ObjectTagsUpdate (Empty Key)",
+			update: &ObjectTagsUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Tags:     map[string]string{"": "avatars"},
+			},
+			expected: fmt.Errorf("tag key cannot be empty"),
+		},
+		{
+			name: "Invalid ObjectTagsUpdate (Key With Separator)",
+			update: &ObjectTagsUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Tags:     map[string]string{"a=b": "avatars"},
+			},
+			expected: fmt.Errorf("invalid tag key 'a=b'. tag key must be at most 128 characters of letters, numbers, spaces and '+ - . _ : / @'"),
+		},
+		{
+			name: "Invalid ObjectTagsUpdate (Value Too Long)",
+			update: &ObjectTagsUpdate{
+				BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+				Tags:     map[string]string{"project": strings.Repeat("a", ObjectTagValueMaxLength+1)},
+			},
+			expected: fmt.Errorf("invalid value of tag 'project'. tag value must be at most 256 characters"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.update.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestParseObjectTagFilters(t *testing.T) {
+	tags, err := ParseObjectTagFilters(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, tags)
+
+	tags, err = ParseObjectTagFilters([]string{"project=avatars", "query=a=b", "empty="})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"project": "avatars", "query": "a=b", "empty": ""}, tags)
+
+	_, err = ParseObjectTagFilters([]string{"project"})
+	assert.Equal(t, fmt.Errorf("invalid tag filter 'project'. tag filter must be in the format 'key=value'"), err)
+
+	_, err = ParseObjectTagFilters([]string{"project=avatars", "project=videos"})
+	assert.Equal(t, fmt.Errorf("invalid tag filters. tag 'project' cannot be filtered by more than one value"), err)
+
+	_, err = ParseObjectTagFilters([]string{"=avatars"})
+	assert.Equal(t, fmt.Errorf("tag key cannot be empty"), err)
+}
+
+func TestObjectSearchInput_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		search   *ObjectSearchInput
+		expected error
+	}{
+		{
+			name: "Valid ObjectSearchInput (Object Path)",
+			search: &ObjectSearchInput{
+				BucketId:   "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectPath: "avatar",
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid ObjectSearchInput (Metadata Filters)",
+			search: &ObjectSearchInput{
+				BucketId:         "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataEquals:   `{"owner_id": "42", "public": true}`,
+				MetadataContains: `{"albums": ["summer"]}`,
+				MetadataExists:   []string{"owner_id"},
+				MetadataRange:    `{"width": {"gte": 1024, "lt": 4096}}`,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid ObjectSearchInput (Nothing To Search)",
+			search: &ObjectSearchInput{
+				BucketId:         "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataContains: `{}`,
+			},
+			expected: fmt.Errorf("nothing to search. object_path, tag or a metadata filter is required to search objects"),
+		},
+		{
+			name: "Valid ObjectSearchInput (Fuzzy Mode)",
+			search: &ObjectSearchInput{
+				BucketId:   "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectPath: "avtar",
+				Mode:       SearchModeFuzzy,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid ObjectSearchInput (Unknown Mode)",
+			search: &ObjectSearchInput{
+				BucketId:   "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				ObjectPath: "avatar",
+				Mode:       "suffix",
+			},
+			expected: fmt.Errorf("invalid mode 'suffix'. mode must be one of prefix, contains or fuzzy"),
+		},
+		{
+			name: "Invalid ObjectSearchInput (Fuzzy Mode Without Object Path)",
+			search: &ObjectSearchInput{
+				BucketId:       "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				Mode:           SearchModeFuzzy,
+				MetadataExists: []string{"owner_id"},
+			},
+			expected: fmt.Errorf("object_path cannot be empty. object_path is required to search objects in fuzzy mode"),
+		},
+		{
+			name: "Invalid ObjectSearchInput (Metadata Equals Not An Object)",
+			search: &ObjectSearchInput{
+				BucketId:       "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataEquals: `["owner_id"]`,
+			},
+			expected: fmt.Errorf(`invalid metadata_equals '["owner_id"]'. metadata_equals must be a json object`),
+		},
+		{
+			name: "Invalid ObjectSearchInput (Metadata Equals Array Value)",
+			search: &ObjectSearchInput{
+				BucketId:       "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataEquals: `{"albums": ["summer"]}`,
+			},
+			expected: fmt.Errorf("invalid metadata_equals value of key 'albums'. use metadata_contains to match objects and arrays"),
+		},
+		{
+			name: "Invalid ObjectSearchInput (Empty Metadata Exists Key)",
+			search: &ObjectSearchInput{
+				BucketId:       "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataExists: []string{""},
+			},
+			expected: fmt.Errorf("metadata_exists key cannot be empty"),
+		},
+		{
+			name: "Invalid ObjectSearchInput (Metadata Range Without Bounds)",
+			search: &ObjectSearchInput{
+				BucketId:      "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataRange: `{"width": {}}`,
+			},
+			expected: fmt.Errorf("invalid metadata_range of key 'width'. at least one of gt, gte, lt and lte is required"),
+		},
+		{
+			name: "Invalid ObjectSearchInput (Metadata Range With Text Bound)",
+			search: &ObjectSearchInput{
+				BucketId:      "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+				MetadataRange: `{"width": {"gte": "1024"}}`,
+			},
+			expected: fmt.Errorf(`invalid metadata_range '{"width": {"gte": "1024"}}'. metadata_range must be a json object of keys and their gt, gte, lt and lte bounds`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.search.SetDefaults()
+			err := tt.search.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
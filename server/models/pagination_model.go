@@ -1,19 +1,141 @@
 package models
 
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/samber/lo"
+	"strconv"
+	"strings"
+)
+
+const (
+	PaginationDefaultLimit = 10
+	PaginationMaxLimit     = 100
+
+	paginationCursorNext     = "next"
+	paginationCursorPrevious = "prev"
+)
+
 type PaginationInput struct {
-	Cursor string `json:"cursor"`
-	Limit  int32  `json:"limit"`
+	//	`cursor` is an opaque value taken from `next_cursor` or `prev_cursor` of a previous page. empty gets the first page
+	Cursor string `json:"cursor" query:"cursor" example:"bmV4dDpidWNrZXRfMDFIUEc0R041SlkyWjZTMDYzOEVSU0czNzU"`
+	Limit  int32  `json:"limit" query:"limit" example:"10"`
 }
 
 func (p *PaginationInput) SetDefaults() {
 	if p.Limit == 0 {
-		p.Limit = 10
+		p.Limit = PaginationDefaultLimit
+	}
+}
+
+func (p *PaginationInput) IsValid() error {
+	if p.Limit < 0 || p.Limit > PaginationMaxLimit {
+		return fmt.Errorf("limit must be between 1 and %d", PaginationMaxLimit)
+	}
+
+	if _, _, err := p.DecodeCursor(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DecodeCursor returns the id the page starts after and whether the page is before that id instead.
+// an empty cursor returns an empty id for the first page
+func (p *PaginationInput) DecodeCursor() (id string, previous bool, err error) {
+	if p.Cursor == "" {
+		return "", false, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid cursor '%s'. cursor must be a next_cursor or prev_cursor of a previous page", p.Cursor)
+	}
+
+	direction, id, ok := strings.Cut(string(decoded), ":")
+	if !ok || !IsNotEmptyTrimmedString(id) || (direction != paginationCursorNext && direction != paginationCursorPrevious) {
+		return "", false, fmt.Errorf("invalid cursor '%s'. cursor must be a next_cursor or prev_cursor of a previous page", p.Cursor)
+	}
+
+	return id, direction == paginationCursorPrevious, nil
+}
+
+// DecodeRankedCursor returns the score and id the page starts after for results ordered by descending score and then
+// by id, and whether the page is before that score and id instead. an empty cursor returns an empty id for the first
+// page
+func (p *PaginationInput) DecodeRankedCursor() (score float32, id string, previous bool, err error) {
+	cursorId, previous, err := p.DecodeCursor()
+	if err != nil || cursorId == "" {
+		return 0, "", previous, err
+	}
+
+	scoreText, id, ok := strings.Cut(cursorId, ":")
+	parsedScore, parseErr := strconv.ParseFloat(scoreText, 32)
+	if !ok || parseErr != nil || !IsNotEmptyTrimmedString(id) {
+		return 0, "", false, fmt.Errorf("invalid cursor '%s'. cursor must be a next_cursor or prev_cursor of a previous page", p.Cursor)
 	}
+
+	return float32(parsedScore), id, previous, nil
+}
+
+// RankedCursorId combines the score and id of an item into the id of a cursor for results ordered by descending score
+// and then by id. the score is formatted without loss so that it compares equal to the score of the item in queries
+func RankedCursorId(score float32, id string) string {
+	return strconv.FormatFloat(float64(score), 'g', -1, 32) + ":" + id
 }
 
-type PaginationResult struct {
+func EncodeCursor(id string, previous bool) string {
+	direction := paginationCursorNext
+	if previous {
+		direction = paginationCursorPrevious
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(direction + ":" + id))
+}
+
+type PaginationResult[T any] struct {
+	Data           []T    `json:"data"`
 	HasPrevious    bool   `json:"has_prev"`
 	PreviousCursor string `json:"prev_cursor"`
 	HasNext        bool   `json:"has_next"`
 	NextCursor     string `json:"next_cursor"`
 }
+
+// NewPaginationResult creates a page from items that were queried with a limit of one more than the page limit,
+// in descending order of id for previous pages, so that the extra item tells whether there is another page
+func NewPaginationResult[T any](items []T, paginationInput *PaginationInput, id func(T) string) *PaginationResult[T] {
+	cursorId, previous, _ := paginationInput.DecodeCursor()
+
+	hasMore := len(items) > int(paginationInput.Limit)
+	if hasMore {
+		items = items[:paginationInput.Limit]
+	}
+
+	result := &PaginationResult[T]{
+		Data: items,
+	}
+
+	if previous {
+		result.Data = lo.Reverse(items)
+		result.HasPrevious = hasMore
+		result.HasNext = true
+	} else {
+		result.HasPrevious = cursorId != ""
+		result.HasNext = hasMore
+	}
+
+	if len(result.Data) == 0 {
+		result.HasPrevious, result.HasNext = false, false
+		return result
+	}
+
+	if result.HasPrevious {
+		result.PreviousCursor = EncodeCursor(id(result.Data[0]), true)
+	}
+
+	if result.HasNext {
+		result.NextCursor = EncodeCursor(id(result.Data[len(result.Data)-1]), false)
+	}
+
+	return result
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPaginationInput_IsValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination *PaginationInput
+		expected   error
+	}{
+		{
+			name:       "Valid PaginationInput (First Page)",
+			pagination: &PaginationInput{Limit: 10},
+			expected:   nil,
+		},
+		{
+			name:       "Valid PaginationInput (Cursor)",
+			pagination: &PaginationInput{Cursor: EncodeCursor("bucket_01HPG4GN5JY2Z6S0638ERSG375", true), Limit: 10},
+			expected:   nil,
+		},
+		{
+			name:       "Invalid PaginationInput (Limit Too Large)",
+			pagination: &PaginationInput{Limit: PaginationMaxLimit + 1},
+			expected:   fmt.Errorf("limit must be between 1 and %d", PaginationMaxLimit),
+		},
+		{
+			name:       "Invalid PaginationInput (Malformed Cursor)",
+			pagination: &PaginationInput{Cursor: "bucket_01HPG4GN5JY2Z6S0638ERSG375", Limit: 10},
+			expected:   fmt.Errorf("invalid cursor 'bucket_01HPG4GN5JY2Z6S0638ERSG375'. cursor must be a next_cursor or prev_cursor of a previous page"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pagination.IsValid()
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestPaginationInput_DecodeRankedCursor(t *testing.T) {
+	cursor := EncodeCursor(RankedCursorId(0.42857143, "bucket_01HPG4GN5JY2Z6S0638ERSG375"), true)
+
+	score, id, previous, err := (&PaginationInput{Cursor: cursor}).DecodeRankedCursor()
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0.42857143), score)
+	assert.Equal(t, "bucket_01HPG4GN5JY2Z6S0638ERSG375", id)
+	assert.True(t, previous)
+
+	score, id, previous, err = (&PaginationInput{}).DecodeRankedCursor()
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0), score)
+	assert.Equal(t, "", id)
+	assert.False(t, previous)
+
+	cursor = EncodeCursor("bucket_01HPG4GN5JY2Z6S0638ERSG375", false)
+	_, _, _, err = (&PaginationInput{Cursor: cursor}).DecodeRankedCursor()
+	assert.Equal(t, fmt.Errorf("invalid cursor '%s'. cursor must be a next_cursor or prev_cursor of a previous page", cursor), err)
+}
+
+func TestNewPaginationResult(t *testing.T) {
+	identity := func(id string) string { return id }
+
+	firstPage := NewPaginationResult([]string{"a", "b", "c"}, &PaginationInput{Limit: 2}, identity)
+	assert.Equal(t, []string{"a", "b"}, firstPage.Data)
+	assert.False(t, firstPage.HasPrevious)
+	assert.True(t, firstPage.HasNext)
+	assert.Equal(t, EncodeCursor("b", false), firstPage.NextCursor)
+
+	lastPage := NewPaginationResult([]string{"c"}, &PaginationInput{Cursor: firstPage.NextCursor, Limit: 2}, identity)
+	assert.Equal(t, []string{"c"}, lastPage.Data)
+	assert.True(t, lastPage.HasPrevious)
+	assert.False(t, lastPage.HasNext)
+	assert.Equal(t, EncodeCursor("c", true), lastPage.PreviousCursor)
+
+	// previous pages are queried in descending order
+	previousPage := NewPaginationResult([]string{"b", "a"}, &PaginationInput{Cursor: lastPage.PreviousCursor, Limit: 2}, identity)
+	assert.Equal(t, []string{"a", "b"}, previousPage.Data)
+	assert.False(t, previousPage.HasPrevious)
+	assert.True(t, previousPage.HasNext)
+	assert.Equal(t, EncodeCursor("b", false), previousPage.NextCursor)
+
+	id, previous, err := (&PaginationInput{Cursor: previousPage.NextCursor}).DecodeCursor()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", id)
+	assert.False(t, previous)
+}
@@ -0,0 +1,16 @@
+package models
+
+const (
+	// SearchModePrefix matches names that start with the search term
+	SearchModePrefix = "prefix"
+	// SearchModeContains matches names that contain the search term
+	SearchModeContains = "contains"
+	// SearchModeFuzzy matches names that contain a word similar to the search term
+	SearchModeFuzzy = "fuzzy"
+
+	SearchDefaultMode = SearchModeContains
+)
+
+func IsValidSearchMode(mode string) bool {
+	return mode == SearchModePrefix || mode == SearchModeContains || mode == SearchModeFuzzy
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"io"
+)
+
+// SignedObjectRequest is a request to a pre-signed url served by the server when objects are kept on the filesystem.
+// the query parameters are the values the url was signed with
+type SignedObjectRequest struct {
+	BucketName        string  `json:"-" params:"bucket_name" example:"avatars"`
+	ObjectName        string  `json:"-" params:"object_name" example:"user/david/avatar.jpg"`
+	Expires           int64   `json:"-" query:"expires" example:"1707792409"`
+	UploadId          *string `json:"-" query:"upload_id" example:"5d41402abc4b2a76b9719d911017c592" extensions:"x-nullable"`
+	PartNumber        *int32  `json:"-" query:"part_number" example:"1" extensions:"x-nullable"`
+	ContentType       *string `json:"-" query:"content_type" example:"image/jpeg" extensions:"x-nullable"`
+	ContentLength     *int64  `json:"-" query:"content_length" example:"1218077" extensions:"x-nullable"`
+	ChecksumAlgorithm *string `json:"-" query:"checksum_algorithm" example:"sha256" extensions:"x-nullable"`
+	Checksum          *string `json:"-" query:"checksum" example:"n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=" extensions:"x-nullable"`
+	Signature         string  `json:"-" query:"signature" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+func (s *SignedObjectRequest) IsValid() error {
+	if !IsNotEmptyTrimmedString(s.BucketName) {
+		return fmt.Errorf("bucket name cannot be empty. bucket name is required")
+	}
+
+	if !IsNotEmptyTrimmedString(s.ObjectName) {
+		return fmt.Errorf("object name cannot be empty. object name is required")
+	}
+
+	if !IsNotEmptyTrimmedString(s.Signature) {
+		return fmt.Errorf("signature cannot be empty. signature is required")
+	}
+
+	return nil
+}
+
+type SignedObjectUpload struct {
+	SignedObjectRequest
+	//	`mime_type` is the content type header of the upload request
+	MimeType string    `json:"-" example:"image/jpeg"`
+	Content  io.Reader `json:"-"`
+}
+
+type SignedObjectDownload struct {
+	SignedObjectRequest
+	Range *string `json:"-" example:"bytes=0-1023" extensions:"x-nullable"`
+}
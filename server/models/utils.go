@@ -3,6 +3,8 @@ package models
 import (
 	"regexp"
 	"strings"
+
+	"github.com/samber/lo"
 )
 
 func IsValidBucketName(name string) bool {
@@ -56,6 +58,61 @@ func IsValidMimeType(mimeType string) bool {
 	}
 }
 
+// IsValidMimeTypePattern checks that a pattern of bucket allowed mime types is a mime type, the '*/*' wildcard,
+// a type family wildcard like 'image/*' or a structured syntax suffix wildcard like 'application/*+json'
+func IsValidMimeTypePattern(pattern string) bool {
+	if pattern == BucketAllowedMimeTypesWildcard || IsValidMimeType(pattern) {
+		return true
+	}
+
+	mimeTypeWildcardPattern := `^[a-zA-Z]+/\*(\+[a-zA-Z0-9\-\.]+)?$`
+
+	regex := regexp.MustCompile(mimeTypeWildcardPattern)
+
+	return regex.MatchString(pattern)
+}
+
+// IsMimeTypeWildcardPattern reports whether a pattern of bucket allowed mime types matches more than one mime type
+func IsMimeTypeWildcardPattern(pattern string) bool {
+	return strings.Contains(pattern, "*")
+}
+
+// MatchesMimeTypePattern reports whether a mime type matches a pattern of bucket allowed mime types, ignoring case
+func MatchesMimeTypePattern(pattern string, mimeType string) bool {
+	pattern, mimeType = strings.ToLower(pattern), strings.ToLower(mimeType)
+
+	if pattern == BucketAllowedMimeTypesWildcard || pattern == mimeType {
+		return true
+	}
+
+	patternType, patternSubtype, ok := strings.Cut(pattern, "/")
+	if !ok {
+		return false
+	}
+
+	mimeTypeType, mimeTypeSubtype, ok := strings.Cut(mimeType, "/")
+	if !ok || patternType != mimeTypeType {
+		return false
+	}
+
+	if patternSubtype == "*" {
+		return true
+	}
+
+	if suffix, ok := strings.CutPrefix(patternSubtype, "*"); ok {
+		return strings.HasSuffix(mimeTypeSubtype, suffix) && len(mimeTypeSubtype) > len(suffix)
+	}
+
+	return false
+}
+
+// IsMimeTypeAllowed reports whether a mime type matches any of the patterns of bucket allowed mime types
+func IsMimeTypeAllowed(allowedMimeTypes []string, mimeType string) bool {
+	return lo.ContainsBy(allowedMimeTypes, func(pattern string) bool {
+		return MatchesMimeTypePattern(pattern, mimeType)
+	})
+}
+
 func IsNotEmptyTrimmedString(value string) bool {
 	if strings.TrimSpace(value) != "" {
 		return true
@@ -63,3 +120,33 @@ func IsNotEmptyTrimmedString(value string) bool {
 		return false
 	}
 }
+
+// IsDetectedMimeTypeAllowed reports whether content that was sniffed as detectedMimeType can be stored under the
+// declaredMimeType in a bucket that allows allowedMimeTypes. sniffing only recognises a limited set of formats, so
+// text, xml and zip based formats are detected by their generic type, which is accepted for declared types that
+// sniffing cannot tell apart. unrecognised binary content is detected as application/octet-stream, which could be
+// anything, so it is only accepted by buckets that allow application/octet-stream
+func IsDetectedMimeTypeAllowed(allowedMimeTypes []string, declaredMimeType string, detectedMimeType string) bool {
+	if IsMimeTypeAllowed(allowedMimeTypes, detectedMimeType) {
+		return true
+	}
+
+	declaredMimeType = strings.ToLower(declaredMimeType)
+
+	switch detectedMimeType {
+	case "text/plain":
+		return strings.HasPrefix(declaredMimeType, "text/") ||
+			strings.HasSuffix(declaredMimeType, "+json") ||
+			strings.HasSuffix(declaredMimeType, "+xml") ||
+			lo.Contains([]string{"application/json", "application/xml", "application/javascript", "application/yaml", "application/x-yaml", "application/x-ndjson", "application/sql", "application/graphql"}, declaredMimeType)
+	case "text/xml":
+		return strings.HasSuffix(declaredMimeType, "/xml") || strings.HasSuffix(declaredMimeType, "+xml")
+	case "application/zip":
+		return strings.HasSuffix(declaredMimeType, "+zip") ||
+			strings.HasPrefix(declaredMimeType, "application/vnd.openxmlformats-officedocument.") ||
+			strings.HasPrefix(declaredMimeType, "application/vnd.oasis.opendocument.") ||
+			lo.Contains([]string{"application/java-archive", "application/vnd.android.package-archive"}, declaredMimeType)
+	default:
+		return false
+	}
+}
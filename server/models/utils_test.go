@@ -64,6 +64,60 @@ func TestIsValidMimeType(t *testing.T) {
 	a.False(IsValidMimeType("/plain"), "Mime Type Missing type")
 }
 
+func TestIsValidMimeTypePattern(t *testing.T) {
+	a := assert.New(t)
+
+	// Test cases for valid mime type patterns
+	a.True(IsValidMimeTypePattern("image/png"), "Mime Type")
+	a.True(IsValidMimeTypePattern("*/*"), "Wildcard")
+	a.True(IsValidMimeTypePattern("image/*"), "Type Family Wildcard")
+	a.True(IsValidMimeTypePattern("application/*+json"), "Suffix Wildcard")
+
+	// Test cases for invalid mime type patterns
+	a.False(IsValidMimeTypePattern("*/png"), "Type Wildcard")
+	a.False(IsValidMimeTypePattern("image/p*"), "Partial Subtype Wildcard")
+	a.False(IsValidMimeTypePattern("application/*+"), "Empty Suffix")
+	a.False(IsValidMimeTypePattern("image"), "Missing Subtype")
+}
+
+func TestIsMimeTypeAllowed(t *testing.T) {
+	a := assert.New(t)
+
+	// Test cases for allowed mime types
+	a.True(IsMimeTypeAllowed([]string{"*/*"}, "video/mp4"), "Wildcard")
+	a.True(IsMimeTypeAllowed([]string{"image/png"}, "image/png"), "Exact Mime Type")
+	a.True(IsMimeTypeAllowed([]string{"image/*"}, "image/webp"), "Type Family")
+	a.True(IsMimeTypeAllowed([]string{"Image/*"}, "image/WEBP"), "Type Family Different Case")
+	a.True(IsMimeTypeAllowed([]string{"video/mp4", "application/*+json"}, "application/ld+json"), "Suffix")
+
+	// Test cases for not allowed mime types
+	a.False(IsMimeTypeAllowed([]string{"image/png"}, "image/jpeg"), "Other Mime Type")
+	a.False(IsMimeTypeAllowed([]string{"image/*"}, "video/mp4"), "Other Type Family")
+	a.False(IsMimeTypeAllowed([]string{"application/*+json"}, "application/json"), "Suffix Without Subtype")
+	a.False(IsMimeTypeAllowed(nil, "image/png"), "No Allowed Mime Types")
+}
+
+func TestIsDetectedMimeTypeAllowed(t *testing.T) {
+	a := assert.New(t)
+
+	// Test cases for allowed content
+	a.True(IsDetectedMimeTypeAllowed([]string{"*/*"}, "image/png", "application/octet-stream"), "Wildcard Bucket")
+	a.True(IsDetectedMimeTypeAllowed([]string{"image/png", "image/jpeg"}, "image/png", "image/jpeg"), "Detected Type Allowed")
+	a.True(IsDetectedMimeTypeAllowed([]string{"image/*"}, "image/png", "image/gif"), "Detected Type Family Allowed")
+	a.True(IsDetectedMimeTypeAllowed([]string{"image/heic", "application/octet-stream"}, "image/heic", "application/octet-stream"), "Unrecognised Binary Format Allowed")
+	a.True(IsDetectedMimeTypeAllowed([]string{"application/json"}, "application/json", "text/plain"), "Text Based Format")
+	a.True(IsDetectedMimeTypeAllowed([]string{"image/svg+xml"}, "image/svg+xml", "text/xml"), "Xml Based Format")
+	a.True(IsDetectedMimeTypeAllowed([]string{"application/epub+zip"}, "application/epub+zip", "application/zip"), "Zip Based Format")
+
+	// Test cases for rejected content
+	a.False(IsDetectedMimeTypeAllowed([]string{"image/png"}, "image/png", "application/octet-stream"), "Executable Declared As Png")
+	a.False(IsDetectedMimeTypeAllowed([]string{"image/heic"}, "image/heic", "application/octet-stream"), "Unrecognised Binary Format")
+	a.False(IsDetectedMimeTypeAllowed([]string{"image/*"}, "image/heic", "application/octet-stream"), "Unrecognised Binary Format In Type Family")
+	a.False(IsDetectedMimeTypeAllowed([]string{"image/png"}, "image/png", "text/html"), "Html Declared As Png")
+	a.False(IsDetectedMimeTypeAllowed([]string{"application/pdf"}, "application/pdf", "application/zip"), "Zip Declared As Pdf")
+	a.False(IsDetectedMimeTypeAllowed([]string{"application/json"}, "application/json", "application/x-gzip"), "Gzip Declared As Json")
+}
+
 func TestIsNotEmptyTrimmedString(t *testing.T) {
 	a := assert.New(t)
 
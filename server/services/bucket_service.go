@@ -3,28 +3,36 @@ package services
 import (
 	"context"
 	"fmt"
+	"github.com/driftdev/storage/server/config"
 	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/envelope"
 	"github.com/driftdev/storage/server/jobs"
 	"github.com/driftdev/storage/server/models"
 	"github.com/driftdev/storage/server/srverr"
+	"github.com/driftdev/storage/server/storage"
 	"github.com/driftdev/storage/server/utils"
 	"github.com/driftdev/storage/server/zapfield"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
+	"github.com/samber/lo"
 	"go.uber.org/zap"
 )
 
 type BucketService struct {
 	query       *database.Queries
 	transaction *database.Transaction
+	storage     *storage.Registry
+	keyring     *envelope.Keyring
 	job         *river.Client[pgx.Tx]
 	logger      *zap.Logger
 }
 
-func NewBucketService(db *pgxpool.Pool, job *river.Client[pgx.Tx], logger *zap.Logger) *BucketService {
+func NewBucketService(db *pgxpool.Pool, storage *storage.Registry, keyring *envelope.Keyring, job *river.Client[pgx.Tx], logger *zap.Logger) *BucketService {
 	return &BucketService{
 		query:       database.New(db),
 		transaction: database.NewTransaction(db),
+		storage:     storage,
+		keyring:     keyring,
 		job:         job,
 		logger:      logger,
 	}
@@ -40,10 +48,29 @@ func (bs *BucketService) CreateBucket(ctx context.Context, bucketCreate *models.
 
 	bucketCreate.PreSave()
 
+	if !bs.storage.HasBackend(*bucketCreate.Backend) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("bucket backend '%s' is not configured", *bucketCreate.Backend), op, reqId, nil)
+	}
+
+	if bucketCreate.Encryption != nil && !bs.storage.SupportsEncryption(*bucketCreate.Backend) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("bucket backend '%s' does not support encryption", *bucketCreate.Backend), op, reqId, nil)
+	}
+
+	if bucketCreate.Encrypted && !bs.keyring.Enabled() {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, "encrypted buckets cannot be created. the encryption master key is not configured", op, reqId, nil)
+	}
+
 	id, err := bs.query.BucketCreate(ctx, &database.BucketCreateParams{
 		Name:                 bucketCreate.Name,
 		AllowedMimeTypes:     bucketCreate.AllowedMimeTypes,
 		MaxAllowedObjectSize: bucketCreate.MaxAllowedObjectSize,
+		MaxTotalSize:         bucketCreate.MaxTotalSize,
+		MaxObjectCount:       bucketCreate.MaxObjectCount,
+		TrashRetentionDays:   *bucketCreate.TrashRetentionDays,
+		Backend:              *bucketCreate.Backend,
+		Encryption:           bucketCreate.Encryption,
+		EncryptionKmsKeyID:   bucketCreate.EncryptionKmsKeyId,
+		Encrypted:            bucketCreate.Encrypted,
 		Public:               bucketCreate.Public,
 	})
 	if err != nil {
@@ -96,6 +123,30 @@ func (bs *BucketService) UpdateBucket(ctx context.Context, bucketUpdate *models.
 			bucket.MaxAllowedObjectSize = bucketUpdate.MaxAllowedObjectSize
 		}
 
+		if bucketUpdate.MaxTotalSize != nil {
+			bucket.MaxTotalSize = bucketUpdate.MaxTotalSize
+		}
+
+		if bucketUpdate.MaxObjectCount != nil {
+			bucket.MaxObjectCount = bucketUpdate.MaxObjectCount
+		}
+
+		if bucketUpdate.ClearMaxAllowedObjectSize {
+			bucket.MaxAllowedObjectSize = nil
+		}
+
+		if bucketUpdate.ClearMaxTotalSize {
+			bucket.MaxTotalSize = nil
+		}
+
+		if bucketUpdate.ClearMaxObjectCount {
+			bucket.MaxObjectCount = nil
+		}
+
+		if bucketUpdate.TrashRetentionDays != nil {
+			bucket.TrashRetentionDays = *bucketUpdate.TrashRetentionDays
+		}
+
 		if bucketUpdate.Public != nil {
 			bucket.Public = *bucketUpdate.Public
 		}
@@ -104,6 +155,9 @@ func (bs *BucketService) UpdateBucket(ctx context.Context, bucketUpdate *models.
 			ID:                   bucket.ID,
 			AllowedMimeTypes:     bucket.AllowedMimeTypes,
 			MaxAllowedObjectSize: bucket.MaxAllowedObjectSize,
+			MaxTotalSize:         bucket.MaxTotalSize,
+			MaxObjectCount:       bucket.MaxObjectCount,
+			TrashRetentionDays:   &bucket.TrashRetentionDays,
 			Public:               &bucket.Public,
 		})
 		if err != nil {
@@ -344,6 +398,13 @@ func (bs *BucketService) GetBucket(ctx context.Context, id string) (*models.Buck
 		Name:                 bucket.Name,
 		AllowedMimeTypes:     bucket.AllowedMimeTypes,
 		MaxAllowedObjectSize: bucket.MaxAllowedObjectSize,
+		MaxTotalSize:         bucket.MaxTotalSize,
+		MaxObjectCount:       bucket.MaxObjectCount,
+		TrashRetentionDays:   bucket.TrashRetentionDays,
+		Backend:              bucket.Backend,
+		Encryption:           bucket.Encryption,
+		EncryptionKmsKeyId:   bucket.EncryptionKmsKeyID,
+		Encrypted:            bucket.Encrypted,
 		Public:               bucket.Public,
 		Disabled:             bucket.Disabled,
 		Locked:               bucket.Locked,
@@ -362,29 +423,59 @@ func (bs *BucketService) GetBucketSize(ctx context.Context, id string) (*models.
 		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket id cannot be empty. bucket id is required to get bucket size", op, reqId, nil)
 	}
 
-	bucketSize, err := bs.query.BucketGetSizeById(ctx, id)
+	bucket, err := bs.query.BucketGetById(ctx, id)
 	if err != nil {
 		if database.IsNotFoundError(err) {
 			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("bucket '%s' not found", id), op, reqId, err)
 		}
-		bs.logger.Error("failed to get bucket size", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		bs.logger.Error("failed to get bucket", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to get bucket size", op, reqId, err)
+	}
+
+	bucketUsage, err := bs.query.BucketGetUsageById(ctx, bucket.ID)
+	if err != nil {
+		bs.logger.Error("failed to get bucket usage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
 		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to get bucket size", op, reqId, err)
 	}
 
 	return &models.BucketSize{
-		Id:   bucketSize.ID,
-		Name: bucketSize.Name,
-		Size: bucketSize.Size,
+		Id:             bucket.ID,
+		Name:           bucket.Name,
+		Size:           bucketUsage.Size,
+		MaxTotalSize:   bucket.MaxTotalSize,
+		ObjectCount:    bucketUsage.ObjectCount,
+		MaxObjectCount: bucket.MaxObjectCount,
 	}, nil
 }
 
-func (bs *BucketService) ListAllBuckets(ctx context.Context) ([]*models.Bucket, error) {
+func (bs *BucketService) ListAllBuckets(ctx context.Context, paginationInput *models.PaginationInput) (*models.PaginationResult[*models.Bucket], error) {
 	const op = "BucketService.ListAllBuckets"
 	reqId := utils.RequestId(ctx)
 
-	buckets, err := bs.query.BucketListAll(ctx)
+	paginationInput.SetDefaults()
+
+	if err := paginationInput.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	cursor, previous, _ := paginationInput.DecodeCursor()
+
+	var buckets []*database.StorageBucket
+	var err error
+
+	if previous {
+		buckets, err = bs.query.BucketListPaginatedPrevious(ctx, &database.BucketListPaginatedPreviousParams{
+			Cursor: cursor,
+			Limit:  paginationInput.Limit + 1,
+		})
+	} else {
+		buckets, err = bs.query.BucketListPaginated(ctx, &database.BucketListPaginatedParams{
+			Cursor: cursor,
+			Limit:  paginationInput.Limit + 1,
+		})
+	}
 	if err != nil {
-		bs.logger.Error("failed to list all buckets", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		bs.logger.Error("failed to list buckets", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
 		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to list all buckets", op, reqId, err)
 	}
 	if len(buckets) == 0 {
@@ -400,6 +491,13 @@ func (bs *BucketService) ListAllBuckets(ctx context.Context) ([]*models.Bucket,
 			Name:                 bucket.Name,
 			AllowedMimeTypes:     bucket.AllowedMimeTypes,
 			MaxAllowedObjectSize: bucket.MaxAllowedObjectSize,
+			MaxTotalSize:         bucket.MaxTotalSize,
+			MaxObjectCount:       bucket.MaxObjectCount,
+			TrashRetentionDays:   bucket.TrashRetentionDays,
+			Backend:              bucket.Backend,
+			Encryption:           bucket.Encryption,
+			EncryptionKmsKeyId:   bucket.EncryptionKmsKeyID,
+			Encrypted:            bucket.Encrypted,
 			Public:               bucket.Public,
 			Disabled:             bucket.Disabled,
 			Locked:               bucket.Locked,
@@ -410,10 +508,12 @@ func (bs *BucketService) ListAllBuckets(ctx context.Context) ([]*models.Bucket,
 		})
 	}
 
-	return result, nil
+	return models.NewPaginationResult(result, paginationInput, func(bucket *models.Bucket) string {
+		return bucket.Id
+	}), nil
 }
 
-func (bs *BucketService) SearchBuckets(ctx context.Context, name string) ([]*models.Bucket, error) {
+func (bs *BucketService) SearchBuckets(ctx context.Context, name string, mode string, paginationInput *models.PaginationInput) (*models.PaginationResult[*models.Bucket], error) {
 	const op = "BucketService.SearchBuckets"
 	reqId := utils.RequestId(ctx)
 
@@ -421,7 +521,50 @@ func (bs *BucketService) SearchBuckets(ctx context.Context, name string) ([]*mod
 		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket name cannot be empty. bucket name is required to search buckets", op, reqId, nil)
 	}
 
-	buckets, err := bs.query.BucketSearch(ctx, name)
+	if mode == "" {
+		mode = models.SearchDefaultMode
+	}
+
+	if !models.IsValidSearchMode(mode) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("invalid mode '%s'. mode must be one of %s, %s or %s", mode, models.SearchModePrefix, models.SearchModeContains, models.SearchModeFuzzy), op, reqId, nil)
+	}
+
+	paginationInput.SetDefaults()
+
+	if err := paginationInput.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	cursorScore, cursorId, previous, err := paginationInput.DecodeRankedCursor()
+	if err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	var buckets []*database.BucketSearchPaginatedRow
+
+	if previous {
+		var previousBuckets []*database.BucketSearchPaginatedPreviousRow
+		previousBuckets, err = bs.query.BucketSearchPaginatedPrevious(ctx, &database.BucketSearchPaginatedPreviousParams{
+			Name:        name,
+			Fuzzy:       mode == models.SearchModeFuzzy,
+			Pattern:     searchNamePattern(name, mode),
+			CursorScore: cursorScore,
+			CursorID:    cursorId,
+			Limit:       paginationInput.Limit + 1,
+		})
+		for _, bucket := range previousBuckets {
+			buckets = append(buckets, (*database.BucketSearchPaginatedRow)(bucket))
+		}
+	} else {
+		buckets, err = bs.query.BucketSearchPaginated(ctx, &database.BucketSearchPaginatedParams{
+			Name:        name,
+			Fuzzy:       mode == models.SearchModeFuzzy,
+			Pattern:     searchNamePattern(name, mode),
+			CursorID:    cursorId,
+			CursorScore: cursorScore,
+			Limit:       paginationInput.Limit + 1,
+		})
+	}
 	if err != nil {
 		bs.logger.Error("failed to search buckets", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
 		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to search buckets", op, reqId, err)
@@ -431,14 +574,23 @@ func (bs *BucketService) SearchBuckets(ctx context.Context, name string) ([]*mod
 	}
 
 	var result []*models.Bucket
+	scores := make(map[string]float32, len(buckets))
 
 	for _, bucket := range buckets {
+		scores[bucket.ID] = bucket.Score
 		result = append(result, &models.Bucket{
 			Id:                   bucket.ID,
 			Version:              bucket.Version,
 			Name:                 bucket.Name,
 			AllowedMimeTypes:     bucket.AllowedMimeTypes,
 			MaxAllowedObjectSize: bucket.MaxAllowedObjectSize,
+			MaxTotalSize:         bucket.MaxTotalSize,
+			MaxObjectCount:       bucket.MaxObjectCount,
+			TrashRetentionDays:   bucket.TrashRetentionDays,
+			Backend:              bucket.Backend,
+			Encryption:           bucket.Encryption,
+			EncryptionKmsKeyId:   bucket.EncryptionKmsKeyID,
+			Encrypted:            bucket.Encrypted,
 			Public:               bucket.Public,
 			Disabled:             bucket.Disabled,
 			Locked:               bucket.Locked,
@@ -449,5 +601,348 @@ func (bs *BucketService) SearchBuckets(ctx context.Context, name string) ([]*mod
 		})
 	}
 
+	return models.NewPaginationResult(result, paginationInput, func(bucket *models.Bucket) string {
+		return models.RankedCursorId(scores[bucket.Id], bucket.Id)
+	}), nil
+}
+
+// ProvisionDefaultBuckets reconciles the buckets declared in the config with the buckets in the database.
+// missing buckets are created and drifted settings are updated. buckets that are not declared are only reported
+func (bs *BucketService) ProvisionDefaultBuckets(ctx context.Context, defaultBuckets []config.DefaultBucket) error {
+	const op = "BucketService.ProvisionDefaultBuckets"
+	reqId := utils.RequestId(ctx)
+
+	declaredBucketNames := make(map[string]bool, len(defaultBuckets))
+
+	for _, defaultBucket := range defaultBuckets {
+		bucketCreate := &models.BucketCreate{
+			Name:                 defaultBucket.Name,
+			AllowedMimeTypes:     defaultBucket.AllowedMimeTypes,
+			MaxAllowedObjectSize: defaultBucket.MaxAllowedObjectSize,
+			MaxTotalSize:         defaultBucket.MaxTotalSize,
+			MaxObjectCount:       defaultBucket.MaxObjectCount,
+			TrashRetentionDays:   defaultBucket.TrashRetentionDays,
+			Encrypted:            defaultBucket.Encrypted,
+			Public:               defaultBucket.Public,
+		}
+
+		if defaultBucket.Backend != "" {
+			bucketCreate.Backend = &defaultBucket.Backend
+		}
+
+		if defaultBucket.Encryption != "" {
+			bucketCreate.Encryption = &defaultBucket.Encryption
+		}
+
+		if defaultBucket.EncryptionKmsKeyId != "" {
+			bucketCreate.EncryptionKmsKeyId = &defaultBucket.EncryptionKmsKeyId
+		}
+
+		// an empty list of allowed mime types is documented as a wild card, same as null
+		if len(bucketCreate.AllowedMimeTypes) == 0 {
+			bucketCreate.AllowedMimeTypes = nil
+		}
+
+		if err := bucketCreate.IsValid(); err != nil {
+			return srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("default bucket '%s' is not valid: %s", defaultBucket.Name, err.Error()), op, reqId, err)
+		}
+
+		bucketCreate.PreSave()
+
+		if !bs.storage.HasBackend(*bucketCreate.Backend) {
+			return srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("default bucket '%s' has backend '%s' which is not configured", bucketCreate.Name, *bucketCreate.Backend), op, reqId, nil)
+		}
+
+		if bucketCreate.Encryption != nil && !bs.storage.SupportsEncryption(*bucketCreate.Backend) {
+			return srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("default bucket '%s' has backend '%s' which does not support encryption", bucketCreate.Name, *bucketCreate.Backend), op, reqId, nil)
+		}
+
+		if declaredBucketNames[bucketCreate.Name] {
+			return srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("default bucket '%s' is declared more than once", bucketCreate.Name), op, reqId, nil)
+		}
+		declaredBucketNames[bucketCreate.Name] = true
+
+		err := bs.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+			bucket, err := bs.query.WithTx(tx).BucketGetByName(ctx, bucketCreate.Name)
+			if err != nil && !database.IsNotFoundError(err) {
+				bs.logger.Error("failed to get default bucket by name", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+				return srverr.NewServiceError(srverr.UnknownError, "failed to provision default buckets", op, reqId, err)
+			}
+
+			if err != nil {
+				id, err := bs.query.WithTx(tx).BucketCreate(ctx, &database.BucketCreateParams{
+					Name:                 bucketCreate.Name,
+					AllowedMimeTypes:     bucketCreate.AllowedMimeTypes,
+					MaxAllowedObjectSize: bucketCreate.MaxAllowedObjectSize,
+					MaxTotalSize:         bucketCreate.MaxTotalSize,
+					MaxObjectCount:       bucketCreate.MaxObjectCount,
+					TrashRetentionDays:   *bucketCreate.TrashRetentionDays,
+					Backend:              *bucketCreate.Backend,
+					Encryption:           bucketCreate.Encryption,
+					EncryptionKmsKeyID:   bucketCreate.EncryptionKmsKeyId,
+					Encrypted:            bucketCreate.Encrypted,
+					Public:               bucketCreate.Public,
+				})
+				if err != nil {
+					bs.logger.Error("failed to create default bucket", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+					return srverr.NewServiceError(srverr.UnknownError, "failed to provision default buckets", op, reqId, err)
+				}
+
+				if defaultBucket.Disabled {
+					err = bs.query.WithTx(tx).BucketDisable(ctx, id)
+					if err != nil {
+						bs.logger.Error("failed to disable default bucket", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+						return srverr.NewServiceError(srverr.UnknownError, "failed to provision default buckets", op, reqId, err)
+					}
+				}
+
+				if defaultBucket.Id != "" {
+					bs.logger.Warn("default bucket was created with a generated id", zap.String("bucket_name", bucketCreate.Name), zap.String("declared_bucket_id", defaultBucket.Id), zap.String("bucket_id", id), zapfield.Operation(op))
+				}
+				bs.logger.Info("created default bucket", zap.String("bucket_name", bucketCreate.Name), zap.String("bucket_id", id), zapfield.Operation(op))
+
+				return nil
+			}
+
+			if defaultBucket.Id != "" && defaultBucket.Id != bucket.ID {
+				bs.logger.Warn("default bucket id does not match the id of the existing bucket", zap.String("bucket_name", bucket.Name), zap.String("declared_bucket_id", defaultBucket.Id), zap.String("bucket_id", bucket.ID), zapfield.Operation(op))
+			}
+
+			// the objects of a bucket are not moved between backends, so a changed backend is only reported
+			if bucket.Backend != *bucketCreate.Backend {
+				bs.logger.Warn("default bucket backend does not match the backend of the existing bucket", zap.String("bucket_id", bucket.ID), zap.String("declared_backend", *bucketCreate.Backend), zap.String("backend", bucket.Backend), zapfield.Operation(op))
+			}
+
+			// existing objects are not re-encrypted, so a changed encryption is only reported as well
+			if lo.FromPtr(bucket.Encryption) != lo.FromPtr(bucketCreate.Encryption) || lo.FromPtr(bucket.EncryptionKmsKeyID) != lo.FromPtr(bucketCreate.EncryptionKmsKeyId) {
+				bs.logger.Warn("default bucket encryption does not match the encryption of the existing bucket", zap.String("bucket_id", bucket.ID), zap.String("declared_encryption", lo.FromPtr(bucketCreate.Encryption)), zap.String("encryption", lo.FromPtr(bucket.Encryption)), zapfield.Operation(op))
+			}
+
+			if bucket.Encrypted != bucketCreate.Encrypted {
+				bs.logger.Warn("default bucket encrypted does not match the existing bucket", zap.String("bucket_id", bucket.ID), zap.Bool("declared_encrypted", bucketCreate.Encrypted), zap.Bool("encrypted", bucket.Encrypted), zapfield.Operation(op))
+			}
+
+			if bucket.Locked {
+				bs.logger.Warn("default bucket is locked and was not reconciled", zap.String("bucket_id", bucket.ID), zap.String("lock_reason", *bucket.LockReason), zapfield.Operation(op))
+				return nil
+			}
+
+			if isBucketSettingsDrifted(bucket, bucketCreate) {
+				bucketUpdate := &models.BucketUpdate{
+					Id:                   bucket.ID,
+					AllowedMimeTypes:     bucketCreate.AllowedMimeTypes,
+					MaxAllowedObjectSize: bucketCreate.MaxAllowedObjectSize,
+					MaxTotalSize:         bucketCreate.MaxTotalSize,
+					MaxObjectCount:       bucketCreate.MaxObjectCount,
+					TrashRetentionDays:   bucketCreate.TrashRetentionDays,
+					Public:               &bucketCreate.Public,
+				}
+				if err = bucketUpdate.IsValid(); err != nil {
+					return srverr.NewServiceError(srverr.InvalidInputError, fmt.Sprintf("default bucket '%s' is not valid: %s", bucket.Name, err.Error()), op, reqId, err)
+				}
+
+				err = bs.query.WithTx(tx).BucketUpdateSettings(ctx, &database.BucketUpdateSettingsParams{
+					ID:                   bucket.ID,
+					AllowedMimeTypes:     bucketUpdate.AllowedMimeTypes,
+					MaxAllowedObjectSize: bucketUpdate.MaxAllowedObjectSize,
+					MaxTotalSize:         bucketUpdate.MaxTotalSize,
+					MaxObjectCount:       bucketUpdate.MaxObjectCount,
+					TrashRetentionDays:   *bucketUpdate.TrashRetentionDays,
+					Public:               *bucketUpdate.Public,
+				})
+				if err != nil {
+					bs.logger.Error("failed to update default bucket", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+					return srverr.NewServiceError(srverr.UnknownError, "failed to provision default buckets", op, reqId, err)
+				}
+				bs.logger.Info("updated drifted settings of default bucket", zap.String("bucket_id", bucket.ID), zapfield.Operation(op))
+			}
+
+			if bucket.Disabled != defaultBucket.Disabled {
+				if defaultBucket.Disabled {
+					err = bs.query.WithTx(tx).BucketDisable(ctx, bucket.ID)
+				} else {
+					err = bs.query.WithTx(tx).BucketEnable(ctx, bucket.ID)
+				}
+				if err != nil {
+					bs.logger.Error("failed to update disabled state of default bucket", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+					return srverr.NewServiceError(srverr.UnknownError, "failed to provision default buckets", op, reqId, err)
+				}
+				bs.logger.Info("updated disabled state of default bucket", zap.String("bucket_id", bucket.ID), zap.Bool("disabled", defaultBucket.Disabled), zapfield.Operation(op))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	buckets, err := bs.query.BucketListAll(ctx)
+	if err != nil {
+		bs.logger.Error("failed to list all buckets", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to provision default buckets", op, reqId, err)
+	}
+
+	for _, bucket := range buckets {
+		if !declaredBucketNames[bucket.Name] {
+			bs.logger.Warn("bucket is not declared in default buckets", zap.String("bucket_id", bucket.ID), zap.String("bucket_name", bucket.Name), zapfield.Operation(op))
+		}
+		if !bs.storage.HasBackend(bucket.Backend) {
+			bs.logger.Error("bucket backend is not configured, its objects cannot be reached", zap.String("bucket_id", bucket.ID), zap.String("backend", bucket.Backend), zapfield.Operation(op))
+		}
+	}
+
+	return nil
+}
+
+func (bs *BucketService) CreateBucketLifecycleRule(ctx context.Context, lifecycleRuleCreate *models.BucketLifecycleRuleCreate) (*models.BucketLifecycleRule, error) {
+	const op = "BucketService.CreateBucketLifecycleRule"
+	reqId := utils.RequestId(ctx)
+
+	if err := lifecycleRuleCreate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	lifecycleRuleCreate.PreSave()
+
+	_, err := bs.GetBucket(ctx, lifecycleRuleCreate.BucketId)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata []byte
+	if lifecycleRuleCreate.Metadata != nil {
+		metadata = metadataToBytes(lifecycleRuleCreate.Metadata)
+	}
+
+	id, err := bs.query.BucketLifecycleRuleCreate(ctx, &database.BucketLifecycleRuleCreateParams{
+		BucketID:                       lifecycleRuleCreate.BucketId,
+		Name:                           lifecycleRuleCreate.Name,
+		Enabled:                        *lifecycleRuleCreate.Enabled,
+		Prefix:                         lifecycleRuleCreate.Prefix,
+		Metadata:                       metadata,
+		Tags:                           tagsToBytes(lifecycleRuleCreate.Tags),
+		ExpireAfterDays:                lifecycleRuleCreate.ExpireAfterDays,
+		ExpireAfterDaysSinceLastAccess: lifecycleRuleCreate.ExpireAfterDaysSinceLastAccess,
+		AbortPendingUploadAfterHours:   lifecycleRuleCreate.AbortPendingUploadAfterHours,
+	})
+	if err != nil {
+		if database.IsConflictError(err) {
+			return nil, srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("lifecycle rule with name '%s' already exists", lifecycleRuleCreate.Name), op, reqId, err)
+		}
+		bs.logger.Error("failed to create lifecycle rule", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to create lifecycle rule", op, reqId, err)
+	}
+
+	return bs.GetBucketLifecycleRule(ctx, lifecycleRuleCreate.BucketId, id)
+}
+
+func (bs *BucketService) UpdateBucketLifecycleRule(ctx context.Context, lifecycleRuleUpdate *models.BucketLifecycleRuleUpdate) (*models.BucketLifecycleRule, error) {
+	const op = "BucketService.UpdateBucketLifecycleRule"
+	reqId := utils.RequestId(ctx)
+
+	if err := lifecycleRuleUpdate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	lifecycleRuleUpdate.PreSave()
+
+	lifecycleRule, err := bs.GetBucketLifecycleRule(ctx, lifecycleRuleUpdate.BucketId, lifecycleRuleUpdate.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata []byte
+	if lifecycleRuleUpdate.Metadata != nil {
+		metadata = metadataToBytes(lifecycleRuleUpdate.Metadata)
+	}
+
+	err = bs.query.BucketLifecycleRuleUpdate(ctx, &database.BucketLifecycleRuleUpdateParams{
+		ID:                             lifecycleRule.Id,
+		Name:                           lifecycleRuleUpdate.Name,
+		Enabled:                        *lifecycleRuleUpdate.Enabled,
+		Prefix:                         lifecycleRuleUpdate.Prefix,
+		Metadata:                       metadata,
+		Tags:                           tagsToBytes(lifecycleRuleUpdate.Tags),
+		ExpireAfterDays:                lifecycleRuleUpdate.ExpireAfterDays,
+		ExpireAfterDaysSinceLastAccess: lifecycleRuleUpdate.ExpireAfterDaysSinceLastAccess,
+		AbortPendingUploadAfterHours:   lifecycleRuleUpdate.AbortPendingUploadAfterHours,
+	})
+	if err != nil {
+		if database.IsConflictError(err) {
+			return nil, srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("lifecycle rule with name '%s' already exists", lifecycleRuleUpdate.Name), op, reqId, err)
+		}
+		bs.logger.Error("failed to update lifecycle rule", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to update lifecycle rule", op, reqId, err)
+	}
+
+	return bs.GetBucketLifecycleRule(ctx, lifecycleRule.BucketId, lifecycleRule.Id)
+}
+
+func (bs *BucketService) DeleteBucketLifecycleRule(ctx context.Context, bucketId string, id string) error {
+	const op = "BucketService.DeleteBucketLifecycleRule"
+	reqId := utils.RequestId(ctx)
+
+	lifecycleRule, err := bs.GetBucketLifecycleRule(ctx, bucketId, id)
+	if err != nil {
+		return err
+	}
+
+	err = bs.query.BucketLifecycleRuleDelete(ctx, lifecycleRule.Id)
+	if err != nil {
+		bs.logger.Error("failed to delete lifecycle rule", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to delete lifecycle rule", op, reqId, err)
+	}
+
+	return nil
+}
+
+func (bs *BucketService) GetBucketLifecycleRule(ctx context.Context, bucketId string, id string) (*models.BucketLifecycleRule, error) {
+	const op = "BucketService.GetBucketLifecycleRule"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket id cannot be empty. bucket id is required to get lifecycle rule", op, reqId, nil)
+	}
+
+	if !models.IsNotEmptyTrimmedString(id) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "lifecycle rule id cannot be empty. lifecycle rule id is required to get lifecycle rule", op, reqId, nil)
+	}
+
+	lifecycleRule, err := bs.query.BucketLifecycleRuleGetByBucketIdAndId(ctx, &database.BucketLifecycleRuleGetByBucketIdAndIdParams{
+		BucketID: bucketId,
+		ID:       id,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("lifecycle rule '%s' not found in bucket '%s'", id, bucketId), op, reqId, err)
+		}
+		bs.logger.Error("failed to get lifecycle rule", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to get lifecycle rule", op, reqId, err)
+	}
+
+	return toBucketLifecycleRule(lifecycleRule), nil
+}
+
+func (bs *BucketService) ListBucketLifecycleRules(ctx context.Context, bucketId string) ([]*models.BucketLifecycleRule, error) {
+	const op = "BucketService.ListBucketLifecycleRules"
+	reqId := utils.RequestId(ctx)
+
+	bucket, err := bs.GetBucket(ctx, bucketId)
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycleRules, err := bs.query.BucketLifecycleRuleListByBucketId(ctx, bucket.Id)
+	if err != nil {
+		bs.logger.Error("failed to list lifecycle rules", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to list lifecycle rules", op, reqId, err)
+	}
+
+	result := make([]*models.BucketLifecycleRule, 0, len(lifecycleRules))
+	for _, lifecycleRule := range lifecycleRules {
+		result = append(result, toBucketLifecycleRule(lifecycleRule))
+	}
+
 	return result, nil
 }
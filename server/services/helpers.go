@@ -2,11 +2,18 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/driftdev/storage/server/database"
 	"github.com/driftdev/storage/server/models"
+	"github.com/driftdev/storage/server/storage"
 	"github.com/samber/lo"
 	"github.com/zhooravell/mime"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func metadataToBytes(metadata map[string]any) []byte {
@@ -26,42 +33,367 @@ func bytesToMetadata(metadataBytes []byte) map[string]any {
 	return metadata
 }
 
+// tagsToBytes encodes the tags of a lifecycle rule or a bulk deletion, an empty tag set is stored as null as it does
+// not filter anything
+func tagsToBytes(tags map[string]string) []byte {
+	if len(tags) == 0 {
+		return nil
+	}
+	tagsBytes, err := json.Marshal(tags)
+	if err != nil {
+		return nil
+	}
+	return tagsBytes
+}
+
+func bytesToTags(tagsBytes []byte) map[string]string {
+	var tags map[string]string
+	err := json.Unmarshal(tagsBytes, &tags)
+	if err != nil {
+		return nil
+	}
+	return tags
+}
+
+// splitObjectTags splits tags into keys and values ordered by key as the tag queries take them as two arrays.
+// the arrays are never nil because a null array would not match any object
+func splitObjectTags(tags map[string]string) ([]string, []string) {
+	keys := lo.Keys(tags)
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, tags[key])
+	}
+
+	return keys, values
+}
+
+// metadataFilterParams compiles the metadata predicates of a search into the jsonb and array parameters of the
+// search query. predicates that are not set are passed as null or as an empty array so that they match any object
+func metadataFilterParams(filter *models.ObjectMetadataFilter) (equals []byte, contains []byte, exists []string, ranges []byte) {
+	exists = []string{}
+
+	if filter == nil {
+		return nil, nil, exists, nil
+	}
+
+	if len(filter.Equals) > 0 {
+		equals = metadataToBytes(filter.Equals)
+	}
+
+	if len(filter.Contains) > 0 {
+		contains = metadataToBytes(filter.Contains)
+	}
+
+	if len(filter.Exists) > 0 {
+		exists = filter.Exists
+	}
+
+	if len(filter.Ranges) > 0 {
+		rangesBytes, err := json.Marshal(filter.Ranges)
+		if err == nil {
+			ranges = rangesBytes
+		}
+	}
+
+	return equals, contains, exists, ranges
+}
+
+// searchNamePattern builds the `ilike` pattern of the prefix and contains search modes. the wildcards and the escape
+// character of `ilike` are escaped so that the term is matched literally. fuzzy searches do not use a pattern
+func searchNamePattern(term string, mode string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+
+	switch mode {
+	case models.SearchModePrefix:
+		return escaped + "%"
+	case models.SearchModeFuzzy:
+		return ""
+	default:
+		return "%" + escaped + "%"
+	}
+}
+
 func determineMimeType(bucket *models.Bucket, preSignedUploadSessionCreate *models.PreSignedUploadSessionCreate) (*string, error) {
-	if preSignedUploadSessionCreate.MimeType != nil {
-		if !models.IsNotEmptyTrimmedString(*preSignedUploadSessionCreate.MimeType) {
+	return resolveMimeType(bucket, preSignedUploadSessionCreate.Name, preSignedUploadSessionCreate.MimeType)
+}
+
+func resolveMimeType(bucket *models.Bucket, name string, mimeType *string) (*string, error) {
+	if mimeType != nil {
+		if !models.IsNotEmptyTrimmedString(*mimeType) {
 			return nil, fmt.Errorf("mime_type cannot be empty. please specify a valid mime type")
 		}
 
-		if !models.IsValidMimeType(*preSignedUploadSessionCreate.MimeType) {
-			return nil, fmt.Errorf("mime_type '%s' is not valid. please specify a valid mime type", *preSignedUploadSessionCreate.MimeType)
+		if !models.IsValidMimeType(*mimeType) {
+			return nil, fmt.Errorf("mime_type '%s' is not valid. please specify a valid mime type", *mimeType)
 		}
 
-		if lo.Contains[string](bucket.AllowedMimeTypes, models.BucketAllowedMimeTypesWildcard) {
-			return preSignedUploadSessionCreate.MimeType, nil
-		} else {
-			if !lo.Contains[string](bucket.AllowedMimeTypes, *preSignedUploadSessionCreate.MimeType) {
-				return nil, fmt.Errorf("mime_type '%s' is not allowed. bucket only allows [%s] mime types. please specify an allowed mime type", *preSignedUploadSessionCreate.MimeType, strings.Join(bucket.AllowedMimeTypes, ", "))
-			}
+		if !models.IsMimeTypeAllowed(bucket.AllowedMimeTypes, *mimeType) {
+			return nil, fmt.Errorf("mime_type '%s' is not allowed. bucket only allows [%s] mime types. please specify an allowed mime type", *mimeType, strings.Join(bucket.AllowedMimeTypes, ", "))
 		}
 
-		return preSignedUploadSessionCreate.MimeType, nil
+		return mimeType, nil
 	} else {
-		defaultMimeType := models.ObjectDefaultMimeType
-		if lo.Contains[string](bucket.AllowedMimeTypes, models.BucketAllowedMimeTypesWildcard) {
-			objectNameParts := strings.Split(preSignedUploadSessionCreate.Name, ".")
-			if len(objectNameParts) > 1 {
-				objectExtension := objectNameParts[len(objectNameParts)-1]
-				mimeType, err := mime.GetMimeTypes(objectExtension)
-				if err != nil {
-					return &defaultMimeType, nil
-				} else {
-					return &mimeType[0], nil
-				}
-			} else {
-				return &defaultMimeType, nil
-			}
-		} else {
+		// the mime type is only inferred from the extension for buckets that allow more than an exact list of mime types
+		if !lo.ContainsBy(bucket.AllowedMimeTypes, models.IsMimeTypeWildcardPattern) {
 			return nil, fmt.Errorf("mime_type cannot be empty. bucket only allows [%s] mime types. please specify an allowed mime type", strings.Join(bucket.AllowedMimeTypes, ", "))
 		}
+
+		inferredMimeTypes := []string{models.ObjectDefaultMimeType}
+		objectNameParts := strings.Split(name, ".")
+		if len(objectNameParts) > 1 {
+			objectExtension := objectNameParts[len(objectNameParts)-1]
+			if mimeTypes, err := mime.GetMimeTypes(objectExtension); err == nil {
+				inferredMimeTypes = mimeTypes
+			}
+		}
+
+		inferredMimeType, ok := lo.Find(inferredMimeTypes, func(inferredMimeType string) bool {
+			return models.IsMimeTypeAllowed(bucket.AllowedMimeTypes, inferredMimeType)
+		})
+		if !ok {
+			return nil, fmt.Errorf("mime_type cannot be empty. mime type '%s' inferred from the object name is not allowed. bucket only allows [%s] mime types. please specify an allowed mime type", inferredMimeTypes[0], strings.Join(bucket.AllowedMimeTypes, ", "))
+		}
+
+		return &inferredMimeType, nil
+	}
+}
+
+var errObjectTooLarge = errors.New("object is too large")
+
+// sizeLimitedReader counts the bytes read from the underlying reader and fails with errObjectTooLarge
+// as soon as more than limit bytes have been read. a limit of nil means there is no upper limit
+type sizeLimitedReader struct {
+	reader io.Reader
+	limit  *int64
+	read   int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.limit != nil && r.read > *r.limit {
+		return n, errObjectTooLarge
+	}
+	return n, err
+}
+
+// decryptedContent is the decrypted content of an object encrypted by the service, closing it closes the encrypted
+// content it is read from
+type decryptedContent struct {
+	io.Reader
+	io.Closer
+}
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseByteRange parses a single http byte range like `bytes=0-1023`, `bytes=1024-` or `bytes=-1024` against an object
+// of the given size and returns the inclusive start and end offsets. ok is false when the header is malformed or asks for
+// multiple ranges, in which case the range should be ignored and the whole object returned
+func parseByteRange(rangeHeader string, size int64) (start int64, end int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(rangeHeader), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		suffixLength, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffixLength < 0 {
+			return 0, 0, false, nil
+		}
+		if suffixLength == 0 || size == 0 {
+			return 0, 0, false, errRangeNotSatisfiable
+		}
+		return max(size-suffixLength, 0), size - 1, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		end = min(end, size-1)
+	}
+
+	if start >= size {
+		return 0, 0, false, errRangeNotSatisfiable
+	}
+
+	return start, end, true, nil
+}
+
+// isNotModified evaluates the If-None-Match and If-Modified-Since conditions of a download against the stored object.
+// If-Modified-Since is only considered when If-None-Match is not present
+func isNotModified(objectDownload *models.ObjectDownload, etag string, lastModified time.Time) bool {
+	if objectDownload.IfNoneMatch != nil {
+		for _, candidate := range strings.Split(*objectDownload.IfNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if objectDownload.IfModifiedSince != nil {
+		return !lastModified.Truncate(time.Second).After(*objectDownload.IfModifiedSince)
+	}
+
+	return false
+}
+
+// isBucketSettingsDrifted reports whether the settings of a bucket differ from its declaration.
+// allowed mime types are compared as sets since the database removes duplicates without keeping the order
+func isBucketSettingsDrifted(bucket *database.StorageBucket, bucketCreate *models.BucketCreate) bool {
+	if bucket.Public != bucketCreate.Public {
+		return true
+	}
+
+	if !isEqualOptionalInt64(bucket.MaxAllowedObjectSize, bucketCreate.MaxAllowedObjectSize) {
+		return true
+	}
+
+	if !isEqualOptionalInt64(bucket.MaxTotalSize, bucketCreate.MaxTotalSize) || !isEqualOptionalInt64(bucket.MaxObjectCount, bucketCreate.MaxObjectCount) {
+		return true
+	}
+
+	if bucketCreate.TrashRetentionDays != nil && bucket.TrashRetentionDays != *bucketCreate.TrashRetentionDays {
+		return true
+	}
+
+	missing, extra := lo.Difference(bucketCreate.AllowedMimeTypes, bucket.AllowedMimeTypes)
+
+	return len(missing) > 0 || len(extra) > 0
+}
+
+// isEqualOptionalInt64 reports whether two optional values are both unset or both set to the same value
+func isEqualOptionalInt64(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// maxNameRune sorts after every other character when names are compared byte wise with the "C" collation
+const maxNameRune = "\U0010FFFF"
+
+// prefixListingBounds returns the exclusive upper bound of the names starting with a prefix and the name a page of a
+// prefix listing starts after. a cursor that continues with the delimiter after the prefix can only be a common
+// prefix, as objects with such names are listed as part of it, and all names below it are skipped like the listing
+// query skips them after every common prefix
+func prefixListingBounds(prefix string, delimiter string, cursor string) (prefixEnd string, startAfter string) {
+	prefixEnd = prefix + maxNameRune
+
+	startAfter = cursor
+	if delimiter != "" && len(cursor) > len(prefix) && strings.HasSuffix(cursor, delimiter) {
+		startAfter = cursor + maxNameRune
+	}
+
+	return prefixEnd, startAfter
+}
+
+// mergeMetadata merges a metadata patch into existing metadata. keys of the patch that are nil are removed
+func mergeMetadata(metadata map[string]any, patch map[string]any) map[string]any {
+	merged := make(map[string]any, len(metadata)+len(patch))
+
+	for key, value := range metadata {
+		merged[key] = value
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+func toBucketLifecycleRule(lifecycleRule *database.StorageBucketLifecycleRule) *models.BucketLifecycleRule {
+	return &models.BucketLifecycleRule{
+		Id:                             lifecycleRule.ID,
+		Version:                        lifecycleRule.Version,
+		BucketId:                       lifecycleRule.BucketID,
+		Name:                           lifecycleRule.Name,
+		Enabled:                        lifecycleRule.Enabled,
+		Prefix:                         lifecycleRule.Prefix,
+		Metadata:                       bytesToMetadata(lifecycleRule.Metadata),
+		Tags:                           bytesToTags(lifecycleRule.Tags),
+		ExpireAfterDays:                lifecycleRule.ExpireAfterDays,
+		ExpireAfterDaysSinceLastAccess: lifecycleRule.ExpireAfterDaysSinceLastAccess,
+		AbortPendingUploadAfterHours:   lifecycleRule.AbortPendingUploadAfterHours,
+		CreatedAt:                      lifecycleRule.CreatedAt,
+		UpdatedAt:                      lifecycleRule.UpdatedAt,
+	}
+}
+
+func toObjectBulkDeletion(bulkDeletion *database.StorageObjectBulkDeletion) *models.ObjectBulkDeletion {
+	return &models.ObjectBulkDeletion{
+		Id:           bulkDeletion.ID,
+		Version:      bulkDeletion.Version,
+		BucketId:     bulkDeletion.BucketID,
+		Prefix:       bulkDeletion.Prefix,
+		Tags:         bytesToTags(bulkDeletion.Tags),
+		Permanent:    bulkDeletion.Permanent,
+		Status:       bulkDeletion.Status,
+		TotalCount:   bulkDeletion.TotalCount,
+		DeletedCount: bulkDeletion.DeletedCount,
+		FailedCount:  bulkDeletion.FailedCount,
+		Failures:     bytesToObjectBulkDeletionFailures(bulkDeletion.Failures),
+		CreatedAt:    bulkDeletion.CreatedAt,
+		UpdatedAt:    bulkDeletion.UpdatedAt,
+		CompletedAt:  bulkDeletion.CompletedAt,
+	}
+}
+
+func toSignedRequest(method string, signedObjectRequest *models.SignedObjectRequest) *storage.SignedRequest {
+	return &storage.SignedRequest{
+		Method:            method,
+		Bucket:            signedObjectRequest.BucketName,
+		Name:              signedObjectRequest.ObjectName,
+		ExpiresAt:         signedObjectRequest.Expires,
+		UploadId:          signedObjectRequest.UploadId,
+		PartNumber:        signedObjectRequest.PartNumber,
+		ContentType:       signedObjectRequest.ContentType,
+		ContentLength:     signedObjectRequest.ContentLength,
+		ChecksumAlgorithm: signedObjectRequest.ChecksumAlgorithm,
+		Checksum:          signedObjectRequest.Checksum,
+		Signature:         signedObjectRequest.Signature,
+	}
+}
+
+// objectBulkDeletionFailuresToBytes encodes the failures of a bulk deletion, no failures are stored as an empty array
+// because the failures of later batches are appended to them
+func objectBulkDeletionFailuresToBytes(failures []*models.ObjectBulkDeletionFailure) []byte {
+	if len(failures) == 0 {
+		return []byte("[]")
+	}
+	failuresBytes, err := json.Marshal(failures)
+	if err != nil {
+		return []byte("[]")
+	}
+	return failuresBytes
+}
+
+func bytesToObjectBulkDeletionFailures(failuresBytes []byte) []*models.ObjectBulkDeletionFailure {
+	var failures []*models.ObjectBulkDeletionFailure
+	err := json.Unmarshal(failuresBytes, &failures)
+	if err != nil || failures == nil {
+		return []*models.ObjectBulkDeletionFailure{}
 	}
+	return failures
 }
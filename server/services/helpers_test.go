@@ -2,10 +2,14 @@ package services
 
 import (
 	"fmt"
+	"github.com/driftdev/storage/server/database"
 	"github.com/driftdev/storage/server/models"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestMetadataConversion(t *testing.T) {
@@ -121,4 +125,252 @@ func TestDetermineMimeType(t *testing.T) {
 
 	_, err = determineMimeType(mimeTypeEmptyStringTest.bucket, mimeTypeEmptyStringTest.preSignedUploadSessionCreate)
 	assert.Equal(t, mimeTypeEmptyStringTest.expectedError, err)
+
+	mimeTypeFamilyTest := test{
+		name: "Mime type of an allowed type family",
+		bucket: &models.Bucket{
+			AllowedMimeTypes: []string{"image/*"},
+		},
+		preSignedUploadSessionCreate: &models.PreSignedUploadSessionCreate{
+			Name:     "user/david/avatar.webp",
+			MimeType: lo.ToPtr[string]("image/webp"),
+		},
+		expectedMimeType: lo.ToPtr[string]("image/webp"),
+		expectedError:    nil,
+	}
+
+	familyMimeType, err := determineMimeType(mimeTypeFamilyTest.bucket, mimeTypeFamilyTest.preSignedUploadSessionCreate)
+	assert.Equal(t, mimeTypeFamilyTest.expectedMimeType, familyMimeType)
+	assert.Equal(t, mimeTypeFamilyTest.expectedError, err)
+
+	mimeTypeInferredOutsideFamilyTest := test{
+		name: "Inferred mime type outside of the allowed type family",
+		bucket: &models.Bucket{
+			AllowedMimeTypes: []string{"image/*"},
+		},
+		preSignedUploadSessionCreate: &models.PreSignedUploadSessionCreate{
+			Name:     "user/david/avatar",
+			MimeType: nil,
+		},
+		expectedMimeType: nil,
+		expectedError:    fmt.Errorf("mime_type cannot be empty. mime type 'application/octet-stream' inferred from the object name is not allowed. bucket only allows [image/*] mime types. please specify an allowed mime type"),
+	}
+
+	_, err = determineMimeType(mimeTypeInferredOutsideFamilyTest.bucket, mimeTypeInferredOutsideFamilyTest.preSignedUploadSessionCreate)
+	assert.Equal(t, mimeTypeInferredOutsideFamilyTest.expectedError, err)
+}
+
+func TestSizeLimitedReader(t *testing.T) {
+	withinLimit := &sizeLimitedReader{
+		reader: strings.NewReader("hello world"),
+		limit:  lo.ToPtr[int64](11),
+	}
+	content, err := io.ReadAll(withinLimit)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+	assert.Equal(t, int64(11), withinLimit.read)
+
+	overLimit := &sizeLimitedReader{
+		reader: strings.NewReader("hello world"),
+		limit:  lo.ToPtr[int64](5),
+	}
+	_, err = io.ReadAll(overLimit)
+	assert.ErrorIs(t, err, errObjectTooLarge)
+
+	noLimit := &sizeLimitedReader{
+		reader: strings.NewReader("hello world"),
+	}
+	_, err = io.ReadAll(noLimit)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), noLimit.read)
+}
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		rangeHeader string
+		size        int64
+		start       int64
+		end         int64
+		ok          bool
+		err         error
+	}{
+		{name: "Closed range", rangeHeader: "bytes=0-99", size: 1000, start: 0, end: 99, ok: true},
+		{name: "Closed range past the end", rangeHeader: "bytes=900-1999", size: 1000, start: 900, end: 999, ok: true},
+		{name: "Open range", rangeHeader: "bytes=500-", size: 1000, start: 500, end: 999, ok: true},
+		{name: "Suffix range", rangeHeader: "bytes=-100", size: 1000, start: 900, end: 999, ok: true},
+		{name: "Suffix range larger than object", rangeHeader: "bytes=-5000", size: 1000, start: 0, end: 999, ok: true},
+		{name: "Start past the end", rangeHeader: "bytes=1000-", size: 1000, err: errRangeNotSatisfiable},
+		{name: "Empty suffix", rangeHeader: "bytes=-0", size: 1000, err: errRangeNotSatisfiable},
+		{name: "Multiple ranges are ignored", rangeHeader: "bytes=0-1,5-10", size: 1000},
+		{name: "Other units are ignored", rangeHeader: "items=0-10", size: 1000},
+		{name: "Reversed range is ignored", rangeHeader: "bytes=10-5", size: 1000},
+		{name: "Malformed range is ignored", rangeHeader: "bytes=abc", size: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok, err := parseByteRange(tt.rangeHeader, tt.size)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestIsNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 2, 13, 8, 16, 49, 500, time.UTC)
+
+	assert.True(t, isNotModified(&models.ObjectDownload{IfNoneMatch: lo.ToPtr(`"abc"`)}, `"abc"`, lastModified))
+	assert.True(t, isNotModified(&models.ObjectDownload{IfNoneMatch: lo.ToPtr(`"xyz", W/"abc"`)}, `"abc"`, lastModified))
+	assert.True(t, isNotModified(&models.ObjectDownload{IfNoneMatch: lo.ToPtr("*")}, `"abc"`, lastModified))
+	assert.False(t, isNotModified(&models.ObjectDownload{IfNoneMatch: lo.ToPtr(`"xyz"`)}, `"abc"`, lastModified))
+
+	assert.True(t, isNotModified(&models.ObjectDownload{IfModifiedSince: lo.ToPtr(lastModified.Truncate(time.Second))}, `"abc"`, lastModified))
+	assert.False(t, isNotModified(&models.ObjectDownload{IfModifiedSince: lo.ToPtr(lastModified.Add(-time.Hour))}, `"abc"`, lastModified))
+
+	assert.False(t, isNotModified(&models.ObjectDownload{
+		IfNoneMatch:     lo.ToPtr(`"xyz"`),
+		IfModifiedSince: lo.ToPtr(lastModified.Add(time.Hour)),
+	}, `"abc"`, lastModified), "If-Modified-Since is ignored when If-None-Match is present")
+
+	assert.False(t, isNotModified(&models.ObjectDownload{}, `"abc"`, lastModified))
+}
+
+func TestIsBucketSettingsDrifted(t *testing.T) {
+	bucket := &database.StorageBucket{
+		AllowedMimeTypes:     []string{"image/png", "image/jpeg"},
+		MaxAllowedObjectSize: lo.ToPtr(int64(10485760)),
+		Public:               true,
+	}
+
+	assert.False(t, isBucketSettingsDrifted(bucket, &models.BucketCreate{
+		AllowedMimeTypes:     []string{"image/jpeg", "image/png", "image/png"},
+		MaxAllowedObjectSize: lo.ToPtr(int64(10485760)),
+		Public:               true,
+	}), "mime types are compared as sets")
+
+	assert.True(t, isBucketSettingsDrifted(bucket, &models.BucketCreate{
+		AllowedMimeTypes:     []string{"image/jpeg"},
+		MaxAllowedObjectSize: lo.ToPtr(int64(10485760)),
+		Public:               true,
+	}))
+
+	assert.True(t, isBucketSettingsDrifted(bucket, &models.BucketCreate{
+		AllowedMimeTypes: []string{"image/jpeg", "image/png"},
+		Public:           true,
+	}), "removing the size limit is a drift")
+
+	assert.True(t, isBucketSettingsDrifted(bucket, &models.BucketCreate{
+		AllowedMimeTypes:     []string{"image/jpeg", "image/png"},
+		MaxAllowedObjectSize: lo.ToPtr(int64(10485760)),
+		Public:               false,
+	}))
+
+	assert.True(t, isBucketSettingsDrifted(bucket, &models.BucketCreate{
+		AllowedMimeTypes:     []string{"image/jpeg", "image/png"},
+		MaxAllowedObjectSize: lo.ToPtr(int64(10485760)),
+		MaxObjectCount:       lo.ToPtr(int64(1000)),
+		Public:               true,
+	}), "adding a quota is a drift")
+
+	assert.True(t, isBucketSettingsDrifted(bucket, &models.BucketCreate{
+		AllowedMimeTypes:     []string{"image/jpeg", "image/png"},
+		MaxAllowedObjectSize: lo.ToPtr(int64(10485760)),
+		TrashRetentionDays:   lo.ToPtr(int32(7)),
+		Public:               true,
+	}), "changing the trash retention is a drift")
+}
+
+func TestPrefixListingBounds(t *testing.T) {
+	prefixEnd, startAfter := prefixListingBounds("users/42/", "/", "")
+	assert.Equal(t, "users/42/\U0010FFFF", prefixEnd)
+	assert.Equal(t, "", startAfter)
+
+	_, startAfter = prefixListingBounds("users/42/", "/", "users/42/avatar.jpg")
+	assert.Equal(t, "users/42/avatar.jpg", startAfter)
+
+	_, startAfter = prefixListingBounds("users/42/", "/", "users/42/documents/")
+	assert.Equal(t, "users/42/documents/\U0010FFFF", startAfter, "names below a listed common prefix are skipped")
+
+	_, startAfter = prefixListingBounds("users/42/", "", "users/42/documents/")
+	assert.Equal(t, "users/42/documents/", startAfter)
+}
+
+func TestMergeMetadata(t *testing.T) {
+	metadata := map[string]any{
+		"user_id":  "user_123456789",
+		"album":    "holidays",
+		"favorite": true,
+	}
+
+	merged := mergeMetadata(metadata, map[string]any{
+		"album":    "summer",
+		"favorite": nil,
+		"rating":   float64(5),
+	})
+
+	assert.Equal(t, map[string]any{
+		"user_id": "user_123456789",
+		"album":   "summer",
+		"rating":  float64(5),
+	}, merged)
+	assert.Equal(t, "holidays", metadata["album"], "existing metadata is not modified")
+
+	assert.Equal(t, map[string]any{"album": "summer"}, mergeMetadata(nil, map[string]any{"album": "summer"}))
+}
+
+func TestSplitObjectTags(t *testing.T) {
+	keys, values := splitObjectTags(map[string]string{"team": "identity", "project": "avatars"})
+	assert.Equal(t, []string{"project", "team"}, keys)
+	assert.Equal(t, []string{"avatars", "identity"}, values)
+
+	keys, values = splitObjectTags(nil)
+	assert.NotNil(t, keys, "a nil array would not match any object")
+	assert.NotNil(t, values)
+	assert.Empty(t, keys)
+}
+
+func TestTagsConversion(t *testing.T) {
+	assert.Nil(t, tagsToBytes(map[string]string{}), "an empty tag set does not filter anything")
+	assert.Equal(t, map[string]string{"retention": "short"}, bytesToTags(tagsToBytes(map[string]string{"retention": "short"})))
+	assert.Nil(t, bytesToTags(nil))
+}
+
+func TestObjectBulkDeletionFailuresConversion(t *testing.T) {
+	name := "exports/scratch/report.csv"
+	failures := []*models.ObjectBulkDeletionFailure{
+		{ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375", Error: "object not found or its upload has not been completed"},
+		{ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG376", Name: &name, Error: "AccessDenied: Access Denied"},
+	}
+
+	assert.Equal(t, []byte("[]"), objectBulkDeletionFailuresToBytes(nil), "failures of later batches are appended to the stored array")
+	assert.Equal(t, failures, bytesToObjectBulkDeletionFailures(objectBulkDeletionFailuresToBytes(failures)))
+	assert.Equal(t, []*models.ObjectBulkDeletionFailure{}, bytesToObjectBulkDeletionFailures(nil))
+}
+
+func TestMetadataFilterParams(t *testing.T) {
+	equals, contains, exists, ranges := metadataFilterParams(nil)
+	assert.Nil(t, equals)
+	assert.Nil(t, contains)
+	assert.Equal(t, []string{}, exists, "a null array would not match any object")
+	assert.Nil(t, ranges)
+
+	equals, contains, exists, ranges = metadataFilterParams(&models.ObjectMetadataFilter{
+		Equals: map[string]any{"owner_id": "42"},
+		Exists: []string{"owner_id"},
+		Ranges: map[string]*models.ObjectMetadataRange{"width": {Gte: lo.ToPtr(1024.0)}},
+	})
+	assert.JSONEq(t, `{"owner_id": "42"}`, string(equals))
+	assert.Nil(t, contains)
+	assert.Equal(t, []string{"owner_id"}, exists)
+	assert.JSONEq(t, `{"width": {"gte": 1024}}`, string(ranges))
+}
+
+func TestSearchNamePattern(t *testing.T) {
+	assert.Equal(t, "avatar%", searchNamePattern("avatar", models.SearchModePrefix))
+	assert.Equal(t, "%avatar%", searchNamePattern("avatar", models.SearchModeContains))
+	assert.Equal(t, `%100\%\_done\\%`, searchNamePattern(`100%_done\`, models.SearchModeContains))
+	assert.Equal(t, "", searchNamePattern("avatar", models.SearchModeFuzzy))
 }
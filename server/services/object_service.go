@@ -1,13 +1,18 @@
 package services
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/driftdev/storage/server/utils"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/driftdev/storage/server/config"
 	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/envelope"
 	"github.com/driftdev/storage/server/jobs"
 	"github.com/driftdev/storage/server/models"
 	"github.com/driftdev/storage/server/srverr"
@@ -15,29 +20,194 @@ import (
 	"github.com/driftdev/storage/server/zapfield"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
+	"github.com/samber/lo"
 	"go.uber.org/zap"
 )
 
 type ObjectService struct {
 	queries     *database.Queries
 	transaction *database.Transaction
-	storage     *storage.Storage
+	storage     *storage.Registry
+	keyring     *envelope.Keyring
 	job         *river.Client[pgx.Tx]
 	config      *config.Config
 	logger      *zap.Logger
 }
 
-func NewObjectService(db *pgxpool.Pool, storage *storage.Storage, job *river.Client[pgx.Tx], config *config.Config, logger *zap.Logger) *ObjectService {
+func NewObjectService(db *pgxpool.Pool, storage *storage.Registry, keyring *envelope.Keyring, job *river.Client[pgx.Tx], config *config.Config, logger *zap.Logger) *ObjectService {
 	return &ObjectService{
 		queries:     database.New(db),
 		transaction: database.NewTransaction(db),
 		storage:     storage,
+		keyring:     keyring,
 		job:         job,
 		config:      config,
 		logger:      logger,
 	}
 }
 
+func (os *ObjectService) UploadObject(ctx context.Context, objectUploadCreate *models.ObjectUploadCreate, content io.Reader) (*models.Object, error) {
+	const op = "ObjectService.UploadObject"
+	reqId := utils.RequestId(ctx)
+
+	var id string
+
+	if err := objectUploadCreate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	bucket, err := os.getBucketById(ctx, objectUploadCreate.BucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := os.getBucketEncryption(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKeyCipher, dataKey, masterKeyId, err := os.newObjectDataKey(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	objectUploadCreate.MimeType, err = resolveMimeType(bucket, objectUploadCreate.Name, objectUploadCreate.MimeType)
+	if err != nil {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, err.Error(), op, reqId, err)
+	}
+
+	if bucket.MaxAllowedObjectSize != nil {
+		if objectUploadCreate.Size > *bucket.MaxAllowedObjectSize {
+			return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object size is too large. max allowed object size is %d bytes", *bucket.MaxAllowedObjectSize), op, reqId, nil)
+		}
+	}
+
+	bufferedContent := bufio.NewReader(content)
+	if _, err = bufferedContent.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, srverr.NewServiceError(srverr.BadRequestError, "object content cannot be empty", op, reqId, err)
+		}
+		os.logger.Error("failed to read object content", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to upload object", op, reqId, err)
+	}
+
+	// the content is sniffed before it is encrypted and streamed, so that content the bucket does not allow is
+	// rejected before anything is written to storage
+	detectedMimeType, err := storage.DetectContentType(bufferedContent)
+	if err != nil {
+		os.logger.Error("failed to detect object content type", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to upload object", op, reqId, err)
+	}
+
+	if !models.IsDetectedMimeTypeAllowed(bucket.AllowedMimeTypes, *objectUploadCreate.MimeType, detectedMimeType) {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("content of object '%s' was detected as '%s' which is not allowed. bucket only allows [%s] mime types", objectUploadCreate.Name, detectedMimeType, strings.Join(bucket.AllowedMimeTypes, ", ")), op, reqId, nil)
+	}
+
+	limitedContent := &sizeLimitedReader{
+		reader: bufferedContent,
+		limit:  bucket.MaxAllowedObjectSize,
+	}
+
+	var uploadContent io.Reader = limitedContent
+	if dataKeyCipher != nil {
+		uploadContent = dataKeyCipher.EncryptReader(limitedContent)
+	}
+
+	// the object row is created as a pending upload before streaming so that the unique (bucket_id, name) constraint
+	// rejects conflicting uploads before anything is written to storage, and so that the declared size reserves the
+	// quota while the content is streamed outside of any transaction
+	size := max(objectUploadCreate.Size, 1)
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if err = os.reserveBucketQuota(ctx, tx, bucket, 1, size, op); err != nil {
+			return err
+		}
+
+		encryptionAlgorithm, encryptionKmsKeyId, encryptionCustomerKeyMd5 := encryptionColumns(encryption)
+
+		id, err = os.queries.WithTx(tx).ObjectCreate(ctx, &database.ObjectCreateParams{
+			BucketID:                 bucket.Id,
+			Name:                     objectUploadCreate.Name,
+			ContentType:              objectUploadCreate.MimeType,
+			Size:                     size,
+			UploadStatus:             models.ObjectUploadStatusPending,
+			Encryption:               encryptionAlgorithm,
+			EncryptionKmsKeyID:       encryptionKmsKeyId,
+			EncryptionCustomerKeyMd5: encryptionCustomerKeyMd5,
+			EncryptionDataKey:        dataKey,
+			EncryptionMasterKeyID:    masterKeyId,
+		})
+		if err != nil {
+			if database.IsConflictError(err) {
+				return srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("object with name '%s' already exists", objectUploadCreate.Name), op, reqId, err)
+			}
+			os.logger.Error("failed to create object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to upload object", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = backend.UploadObject(ctx, &storage.ObjectUpload{
+		Bucket:      bucket.Name,
+		Name:        objectUploadCreate.Name,
+		ContentType: *objectUploadCreate.MimeType,
+		Content:     uploadContent,
+		Encryption:  encryption,
+	})
+	if err != nil {
+		os.abortObjectUpload(ctx, backend, bucket.Name, id, objectUploadCreate.Name, op)
+		if errors.Is(err, errObjectTooLarge) {
+			return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object size is too large. max allowed object size is %d bytes", *bucket.MaxAllowedObjectSize), op, reqId, err)
+		}
+		os.logger.Error("failed to upload object to storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to upload object", op, reqId, err)
+	}
+
+	// the quota is checked again with the actual size, as the declared size is only an estimate of the content
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if limitedContent.read != size {
+			err = os.queries.WithTx(tx).ObjectUpdate(ctx, &database.ObjectUpdateParams{
+				ID:   id,
+				Size: &limitedContent.read,
+			})
+			if err != nil {
+				os.logger.Error("failed to update object size in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+				return srverr.NewServiceError(srverr.UnknownError, "failed to upload object", op, reqId, err)
+			}
+
+			if err = os.reserveBucketQuota(ctx, tx, bucket, 0, 0, op); err != nil {
+				return err
+			}
+		}
+
+		err = os.queries.WithTx(tx).ObjectCompleteUpload(ctx, &database.ObjectCompleteUploadParams{
+			ID:               id,
+			DetectedMimeType: &detectedMimeType,
+		})
+		if err != nil {
+			os.logger.Error("failed to complete object upload in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to upload object", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		os.abortObjectUpload(ctx, backend, bucket.Name, id, objectUploadCreate.Name, op)
+		return nil, err
+	}
+
+	return os.GetObject(ctx, bucket.Id, id)
+}
+
 func (os *ObjectService) CreatePreSignedUploadSession(ctx context.Context, preSignedUploadSessionCreate *models.PreSignedUploadSessionCreate) (*models.PreSignedUploadSession, error) {
 	const op = "ObjectService.CreatePreSignedUploadSession"
 	reqId := utils.RequestId(ctx)
@@ -54,6 +224,26 @@ func (os *ObjectService) CreatePreSignedUploadSession(ctx context.Context, preSi
 		return nil, err
 	}
 
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket.Encrypted {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("bucket '%s' is encrypted by the service. objects can only be uploaded through the server", bucket.Id), op, reqId, nil)
+	}
+
+	encryption, err := os.getBucketEncryption(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// the etag s3 returns for sse-kms and sse-c objects is not the md5 digest of the content, so an md5 checksum
+	// could not be verified when the upload is completed
+	if lo.FromPtr(preSignedUploadSessionCreate.ChecksumAlgorithm) == models.ObjectChecksumAlgorithmMD5 && encryption != nil && encryption.Algorithm != storage.EncryptionSSES3 {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("md5 checksums are not supported for bucket '%s' encrypted with %s. use sha256 or crc32c instead", bucket.Id, encryption.Algorithm), op, reqId, nil)
+	}
+
 	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
 		if preSignedUploadSessionCreate.ExpiresIn == nil {
 			preSignedUploadSessionCreate.ExpiresIn = &os.config.DefaultPreSignedUploadUrlExpiry
@@ -70,25 +260,39 @@ func (os *ObjectService) CreatePreSignedUploadSession(ctx context.Context, preSi
 			}
 		}
 
-		preSignedObject, err = os.storage.CreatePreSignedUploadObject(ctx, &storage.PreSignedUploadObjectCreate{
-			Bucket:        bucket.Name,
-			Name:          preSignedUploadSessionCreate.Name,
-			ExpiresIn:     preSignedUploadSessionCreate.ExpiresIn,
-			ContentType:   *preSignedUploadSessionCreate.MimeType,
-			ContentLength: preSignedUploadSessionCreate.Size,
+		if err = os.reserveBucketQuota(ctx, tx, bucket, 1, preSignedUploadSessionCreate.Size, op); err != nil {
+			return err
+		}
+
+		preSignedObject, err = backend.CreatePreSignedUploadObject(ctx, &storage.PreSignedUploadObjectCreate{
+			Bucket:            bucket.Name,
+			Name:              preSignedUploadSessionCreate.Name,
+			ExpiresIn:         preSignedUploadSessionCreate.ExpiresIn,
+			ContentType:       *preSignedUploadSessionCreate.MimeType,
+			ContentLength:     preSignedUploadSessionCreate.Size,
+			ChecksumAlgorithm: preSignedUploadSessionCreate.ChecksumAlgorithm,
+			Checksum:          preSignedUploadSessionCreate.Checksum,
+			Encryption:        encryption,
 		})
 		if err != nil {
 			os.logger.Error("failed to create pre-signed upload object", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
 			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed upload session", op, reqId, err)
 		}
 
+		encryptionAlgorithm, encryptionKmsKeyId, encryptionCustomerKeyMd5 := encryptionColumns(encryption)
+
 		id, err = os.queries.WithTx(tx).ObjectCreate(ctx, &database.ObjectCreateParams{
-			BucketID:     bucket.Id,
-			Name:         preSignedUploadSessionCreate.Name,
-			ContentType:  preSignedUploadSessionCreate.MimeType,
-			Size:         preSignedUploadSessionCreate.Size,
-			Metadata:     metadataToBytes(preSignedUploadSessionCreate.Metadata),
-			UploadStatus: models.ObjectUploadStatusPending,
+			BucketID:                 bucket.Id,
+			Name:                     preSignedUploadSessionCreate.Name,
+			ContentType:              preSignedUploadSessionCreate.MimeType,
+			Size:                     preSignedUploadSessionCreate.Size,
+			Metadata:                 metadataToBytes(preSignedUploadSessionCreate.Metadata),
+			ChecksumAlgorithm:        preSignedUploadSessionCreate.ChecksumAlgorithm,
+			Checksum:                 preSignedUploadSessionCreate.Checksum,
+			UploadStatus:             models.ObjectUploadStatusPending,
+			Encryption:               encryptionAlgorithm,
+			EncryptionKmsKeyID:       encryptionKmsKeyId,
+			EncryptionCustomerKeyMd5: encryptionCustomerKeyMd5,
 		})
 		if err != nil {
 			if database.IsConflictError(err) {
@@ -98,6 +302,10 @@ func (os *ObjectService) CreatePreSignedUploadSession(ctx context.Context, preSi
 			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed upload session", op, reqId, err)
 		}
 
+		if err = os.setObjectTags(ctx, os.queries.WithTx(tx), id, preSignedUploadSessionCreate.Tags, op); err != nil {
+			return err
+		}
+
 		_, err = os.job.InsertTx(ctx, tx, jobs.PreSignedUploadSessionCompletion{
 			ObjectId: id,
 		}, &river.InsertOpts{
@@ -119,6 +327,7 @@ func (os *ObjectService) CreatePreSignedUploadSession(ctx context.Context, preSi
 		Url:       preSignedObject.Url,
 		Method:    preSignedObject.Method,
 		ExpiresAt: preSignedObject.ExpiresAt,
+		Headers:   preSignedObject.Headers,
 	}, nil
 }
 
@@ -139,6 +348,11 @@ func (os *ObjectService) CompletePreSignedUploadSession(ctx context.Context, buc
 		return err
 	}
 
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return err
+	}
+
 	object, err := os.queries.ObjectGetById(ctx, objectId)
 	if err != nil {
 		if database.IsNotFoundError(err) {
@@ -152,9 +366,15 @@ func (os *ObjectService) CompletePreSignedUploadSession(ctx context.Context, buc
 		return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("upload session has already been completed for object '%s'", objectId), op, reqId, nil)
 	}
 
-	objectExists, err := os.storage.CheckIfObjectExists(ctx, &storage.ObjectExistsCheck{
-		Bucket: bucket.Name,
-		Name:   object.Name,
+	encryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return err
+	}
+
+	objectExists, err := backend.CheckIfObjectExists(ctx, &storage.ObjectExistsCheck{
+		Bucket:     bucket.Name,
+		Name:       object.Name,
+		Encryption: encryption,
 	})
 	if err != nil {
 		os.logger.Error("failed to check if object exists in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
@@ -162,13 +382,8 @@ func (os *ObjectService) CompletePreSignedUploadSession(ctx context.Context, buc
 	}
 
 	if objectExists {
-		err = os.queries.ObjectUpdateUploadStatus(ctx, &database.ObjectUpdateUploadStatusParams{
-			ID:           object.ID,
-			UploadStatus: models.ObjectUploadStatusCompleted,
-		})
-		if err != nil {
-			os.logger.Error("failed to update object upload status in database to completed", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-			return srverr.NewServiceError(srverr.UnknownError, "failed to complete pre-signed upload session", op, reqId, err)
+		if err = os.completeObjectUpload(ctx, os.queries, backend, bucket, object, encryption, op); err != nil {
+			return err
 		}
 	} else {
 		return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object '%s' has not yet been uploaded to storage", objectId), op, reqId, nil)
@@ -177,86 +392,113 @@ func (os *ObjectService) CompletePreSignedUploadSession(ctx context.Context, buc
 	return nil
 }
 
-func (os *ObjectService) CreatePreSignedDownloadSession(ctx context.Context, bucketId string, objectId string, expiresIn int64) (*models.PreSignedDownloadSession, error) {
-	const op = "ObjectService.CreatePreSignedDownloadSession"
+func (os *ObjectService) CreateMultipartUploadSession(ctx context.Context, multipartUploadSessionCreate *models.MultipartUploadSessionCreate) (*models.MultipartUploadSession, error) {
+	const op = "ObjectService.CreateMultipartUploadSession"
 	reqId := utils.RequestId(ctx)
 
-	var preSignedDownloadObject models.PreSignedDownloadSession
+	var id string
 
-	if !models.IsNotEmptyTrimmedString(bucketId) {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to create pre-signed download session", op, reqId, nil)
+	if err := multipartUploadSessionCreate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
 	}
 
-	if !models.IsNotEmptyTrimmedString(objectId) {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to create pre-signed download session", op, reqId, nil)
+	bucket, err := os.getBucketById(ctx, multipartUploadSessionCreate.BucketId, op)
+	if err != nil {
+		return nil, err
 	}
 
-	if expiresIn == 0 {
-		expiresIn = os.config.DefaultPreSignedUploadUrlExpiry
-	} else {
-		if expiresIn < 0 {
-			return nil, srverr.NewServiceError(srverr.InvalidInputError, "expires_in must be greater than 0", op, reqId, nil)
-		}
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
 	}
 
-	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if bucket.Encrypted {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("bucket '%s' is encrypted by the service. objects can only be uploaded through the server", bucket.Id), op, reqId, nil)
+	}
+
+	encryption, err := os.getBucketEncryption(ctx, bucket, op)
 	if err != nil {
 		return nil, err
 	}
 
+	if multipartUploadSessionCreate.ExpiresIn == nil {
+		multipartUploadSessionCreate.ExpiresIn = &os.config.DefaultMultipartUploadSessionExpiry
+	}
+
+	multipartUploadSessionCreate.MimeType, err = resolveMimeType(bucket, multipartUploadSessionCreate.Name, multipartUploadSessionCreate.MimeType)
+	if err != nil {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, err.Error(), op, reqId, err)
+	}
+
+	if bucket.MaxAllowedObjectSize != nil {
+		if multipartUploadSessionCreate.Size > *bucket.MaxAllowedObjectSize {
+			return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object size is too large. max allowed object size is %d bytes", *bucket.MaxAllowedObjectSize), op, reqId, nil)
+		}
+	}
+
+	expiresAt := time.Now().Add(time.Duration(*multipartUploadSessionCreate.ExpiresIn) * time.Second)
+
 	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
-		object, err := os.queries.WithTx(tx).ObjectGetById(ctx, objectId)
-		if err != nil {
-			if database.IsNotFoundError(err) {
-				return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
-			}
-			os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op))
-			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
+		if err = os.reserveBucketQuota(ctx, tx, bucket, 1, multipartUploadSessionCreate.Size, op); err != nil {
+			return err
 		}
 
-		if object.UploadStatus == models.ObjectUploadStatusPending {
-			objectExists, err := os.storage.CheckIfObjectExists(ctx, &storage.ObjectExistsCheck{
-				Bucket: bucket.Name,
-				Name:   object.Name,
-			})
-			if err != nil {
-				os.logger.Error("failed to check if object exists in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-				return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
-			}
+		encryptionAlgorithm, encryptionKmsKeyId, encryptionCustomerKeyMd5 := encryptionColumns(encryption)
 
-			if objectExists {
-				err = os.queries.WithTx(tx).ObjectUpdateUploadStatus(ctx, &database.ObjectUpdateUploadStatusParams{
-					ID:           object.ID,
-					UploadStatus: models.ObjectUploadStatusCompleted,
-				})
-				if err != nil {
-					os.logger.Error("failed to update object upload status in database to completed", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-					return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
-				}
-			} else {
-				return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' upload has not been completed", object.ID), op, reqId, nil)
+		id, err = os.queries.WithTx(tx).ObjectCreate(ctx, &database.ObjectCreateParams{
+			BucketID:                 bucket.Id,
+			Name:                     multipartUploadSessionCreate.Name,
+			ContentType:              multipartUploadSessionCreate.MimeType,
+			Size:                     multipartUploadSessionCreate.Size,
+			Metadata:                 metadataToBytes(multipartUploadSessionCreate.Metadata),
+			UploadStatus:             models.ObjectUploadStatusPending,
+			Encryption:               encryptionAlgorithm,
+			EncryptionKmsKeyID:       encryptionKmsKeyId,
+			EncryptionCustomerKeyMd5: encryptionCustomerKeyMd5,
+		})
+		if err != nil {
+			if database.IsConflictError(err) {
+				return srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("object with name '%s' already exists", multipartUploadSessionCreate.Name), op, reqId, err)
 			}
+			os.logger.Error("failed to create object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to create multipart upload session", op, reqId, err)
+		}
+
+		if err = os.setObjectTags(ctx, os.queries.WithTx(tx), id, multipartUploadSessionCreate.Tags, op); err != nil {
+			return err
 		}
 
-		preSignedObject, err := os.storage.CreatePreSignedDownloadObject(ctx, &storage.PreSignedDownloadObjectCreate{
-			Bucket: bucket.Name,
-			Name:   object.Name,
+		uploadId, err := backend.CreateMultipartUpload(ctx, &storage.MultipartUploadCreate{
+			Bucket:      bucket.Name,
+			Name:        multipartUploadSessionCreate.Name,
+			ContentType: *multipartUploadSessionCreate.MimeType,
+			Encryption:  encryption,
 		})
 		if err != nil {
-			os.logger.Error("failed to create pre-signed download url", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
+			os.logger.Error("failed to create multipart upload in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to create multipart upload session", op, reqId, err)
 		}
 
-		err = os.queries.WithTx(tx).ObjectUpdateLastAccessedAt(ctx, object.ID)
+		err = os.queries.WithTx(tx).MultipartUploadSessionCreate(ctx, &database.MultipartUploadSessionCreateParams{
+			ObjectID:  id,
+			UploadID:  uploadId,
+			ExpiresAt: expiresAt,
+		})
 		if err != nil {
-			os.logger.Error("failed to update object last accessed at", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
+			os.logger.Error("failed to create multipart upload session in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			os.abortMultipartUpload(ctx, backend, bucket.Name, multipartUploadSessionCreate.Name, uploadId, op)
+			return srverr.NewServiceError(srverr.UnknownError, "failed to create multipart upload session", op, reqId, err)
 		}
 
-		preSignedDownloadObject = models.PreSignedDownloadSession{
-			Url:       preSignedObject.Url,
-			Method:    preSignedObject.Method,
-			ExpiresAt: preSignedObject.ExpiresAt,
+		_, err = os.job.InsertTx(ctx, tx, jobs.PreSignedUploadSessionCompletion{
+			ObjectId: id,
+		}, &river.InsertOpts{
+			ScheduledAt: expiresAt.Add(time.Minute * 1),
+		})
+		if err != nil {
+			os.logger.Error("failed to create multipart upload session completion job", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			os.abortMultipartUpload(ctx, backend, bucket.Name, multipartUploadSessionCreate.Name, uploadId, op)
+			return srverr.NewServiceError(srverr.UnknownError, "failed to create multipart upload session", op, reqId, err)
 		}
 
 		return nil
@@ -265,156 +507,2080 @@ func (os *ObjectService) CreatePreSignedDownloadSession(ctx context.Context, buc
 		return nil, err
 	}
 
-	return &preSignedDownloadObject, nil
+	return &models.MultipartUploadSession{
+		Id:        id,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
 }
 
-func (os *ObjectService) DeleteObject(ctx context.Context, bucketId string, objectId string) error {
-	const op = "ObjectService.DeleteObject"
+func (os *ObjectService) CreatePreSignedUploadParts(ctx context.Context, preSignedUploadPartsCreate *models.PreSignedUploadPartsCreate) ([]*models.PreSignedUploadPart, error) {
+	const op = "ObjectService.CreatePreSignedUploadParts"
 	reqId := utils.RequestId(ctx)
 
-	if !models.IsNotEmptyTrimmedString(bucketId) {
-		return srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to delete object", op, reqId, nil)
+	if err := preSignedUploadPartsCreate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
 	}
 
-	if !models.IsNotEmptyTrimmedString(objectId) {
-		return srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to delete object", op, reqId, nil)
+	bucket, err := os.getBucketById(ctx, preSignedUploadPartsCreate.BucketId, op)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := os.getBucketById(ctx, bucketId, op)
+	backend, err := os.getBucketBackend(ctx, bucket, op)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
-		object, err := os.queries.WithTx(tx).ObjectGetById(ctx, objectId)
-		if err != nil {
-			if database.IsNotFoundError(err) {
-				return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
-			}
-			os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-			return srverr.NewServiceError(srverr.UnknownError, "failed to delete object", op, reqId, err)
-		}
+	object, multipartUploadSession, err := os.getMultipartUploadSession(ctx, bucket, preSignedUploadPartsCreate.ObjectId, op)
+	if err != nil {
+		return nil, err
+	}
 
-		if object.UploadStatus == models.ObjectUploadStatusPending {
-			return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("upload has not yet been completed for object '%s'. delete operation can only be performed on objects that have been uploaded", object.ID), op, reqId, nil)
-		}
+	encryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
 
-		_, err = os.job.InsertTx(ctx, tx, jobs.ObjectDeletion{
-			ObjectId: object.ID,
-		}, nil)
+	if preSignedUploadPartsCreate.ExpiresIn == nil {
+		preSignedUploadPartsCreate.ExpiresIn = &os.config.DefaultPreSignedUploadUrlExpiry
+	}
+
+	// part urls never outlive the session they belong to
+	sessionExpiresIn := int64(time.Until(multipartUploadSession.ExpiresAt).Seconds())
+	if *preSignedUploadPartsCreate.ExpiresIn > sessionExpiresIn {
+		preSignedUploadPartsCreate.ExpiresIn = &sessionExpiresIn
+	}
+
+	var result []*models.PreSignedUploadPart
+
+	for _, partNumber := range lo.Uniq(preSignedUploadPartsCreate.PartNumbers) {
+		preSignedObject, err := backend.CreatePreSignedUploadPart(ctx, &storage.PreSignedUploadPartCreate{
+			Bucket:     bucket.Name,
+			Name:       object.Name,
+			UploadId:   multipartUploadSession.UploadID,
+			PartNumber: partNumber,
+			ExpiresIn:  preSignedUploadPartsCreate.ExpiresIn,
+			Encryption: encryption,
+		})
 		if err != nil {
-			os.logger.Error("failed create object deletion job", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-			return srverr.NewServiceError(srverr.UnknownError, "failed to delete object", op, reqId, err)
+			os.logger.Error("failed to create pre-signed upload part", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return nil, srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed upload parts", op, reqId, err)
 		}
 
-		return nil
-	})
-	if err != nil {
-		return err
+		result = append(result, &models.PreSignedUploadPart{
+			PartNumber: partNumber,
+			Url:        preSignedObject.Url,
+			Method:     preSignedObject.Method,
+			ExpiresAt:  preSignedObject.ExpiresAt,
+			Headers:    preSignedObject.Headers,
+		})
 	}
 
-	return nil
+	return result, nil
 }
 
-func (os *ObjectService) GetObject(ctx context.Context, bucketId string, objectId string) (*models.Object, error) {
-	const op = "ObjectService.GetObject"
+func (os *ObjectService) ListUploadedParts(ctx context.Context, bucketId string, objectId string) ([]*models.UploadedPart, error) {
+	const op = "ObjectService.ListUploadedParts"
 	reqId := utils.RequestId(ctx)
 
-	if !models.IsNotEmptyTrimmedString(bucketId) {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to get object", op, reqId, nil)
+	if !models.IsNotEmptyTrimmedString(objectId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to list uploaded parts", op, reqId, nil)
 	}
 
-	if !models.IsNotEmptyTrimmedString(objectId) {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to get object", op, reqId, nil)
+	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := os.getBucketById(ctx, bucketId, op)
+	backend, err := os.getBucketBackend(ctx, bucket, op)
 	if err != nil {
 		return nil, err
 	}
 
-	object, err := os.queries.ObjectGetById(ctx, objectId)
+	object, multipartUploadSession, err := os.getMultipartUploadSession(ctx, bucket, objectId, op)
 	if err != nil {
-		if database.IsNotFoundError(err) {
-			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
+		return nil, err
+	}
+
+	uploadedParts, err := backend.ListUploadedParts(ctx, &storage.UploadedPartsList{
+		Bucket:   bucket.Name,
+		Name:     object.Name,
+		UploadId: multipartUploadSession.UploadID,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("multipart upload session for object '%s' not found", objectId), op, reqId, err)
 		}
-		os.logger.Error("failed to get object", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to get object", op, reqId, err)
+		os.logger.Error("failed to list uploaded parts", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to list uploaded parts", op, reqId, err)
 	}
 
-	return &models.Object{
-		Id:             object.ID,
-		BucketId:       object.BucketID,
-		Name:           object.Name,
-		MimeType:       object.MimeType,
-		Size:           object.Size,
-		Metadata:       bytesToMetadata(object.Metadata),
-		UploadStatus:   object.UploadStatus,
-		LastAccessedAt: object.LastAccessedAt,
-		CreatedAt:      object.CreatedAt,
-		UpdatedAt:      object.UpdatedAt,
-	}, nil
+	result := make([]*models.UploadedPart, 0, len(uploadedParts))
+
+	for _, uploadedPart := range uploadedParts {
+		result = append(result, &models.UploadedPart{
+			PartNumber:   uploadedPart.PartNumber,
+			ETag:         uploadedPart.ETag,
+			Size:         uploadedPart.Size,
+			LastModified: uploadedPart.LastModified,
+		})
+	}
+
+	return result, nil
+}
+
+func (os *ObjectService) CompleteMultipartUploadSession(ctx context.Context, multipartUploadSessionComplete *models.MultipartUploadSessionComplete) (*models.Object, error) {
+	const op = "ObjectService.CompleteMultipartUploadSession"
+	reqId := utils.RequestId(ctx)
+
+	if err := multipartUploadSessionComplete.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	bucket, err := os.getBucketById(ctx, multipartUploadSessionComplete.BucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	object, multipartUploadSession, err := os.getMultipartUploadSession(ctx, bucket, multipartUploadSessionComplete.ObjectId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
+
+	var completedParts []*storage.CompletedPart
+	for _, part := range multipartUploadSessionComplete.Parts {
+		completedParts = append(completedParts, &storage.CompletedPart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+
+	err = backend.CompleteMultipartUpload(ctx, &storage.MultipartUploadComplete{
+		Bucket:   bucket.Name,
+		Name:     object.Name,
+		UploadId: multipartUploadSession.UploadID,
+		Parts:    completedParts,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidPart) {
+			return nil, srverr.NewServiceError(srverr.BadRequestError, "failed to complete multipart upload session. make sure all parts have been uploaded and the etags match the uploaded parts", op, reqId, err)
+		}
+		os.logger.Error("failed to complete multipart upload in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to complete multipart upload session", op, reqId, err)
+	}
+
+	objectInfo, err := backend.HeadObject(ctx, &storage.ObjectHead{
+		Bucket:     bucket.Name,
+		Name:       object.Name,
+		Encryption: encryption,
+	})
+	if err != nil {
+		os.logger.Error("failed to head completed object in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to complete multipart upload session", op, reqId, err)
+	}
+
+	if bucket.MaxAllowedObjectSize != nil && objectInfo.ContentLength > *bucket.MaxAllowedObjectSize {
+		os.deleteUploadedObject(ctx, backend, bucket.Name, object.Name, op)
+		err = os.queries.ObjectDelete(ctx, object.ID)
+		if err != nil {
+			os.logger.Error("failed to delete oversized object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return nil, srverr.NewServiceError(srverr.UnknownError, "failed to complete multipart upload session", op, reqId, err)
+		}
+		return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object size is too large. max allowed object size is %d bytes", *bucket.MaxAllowedObjectSize), op, reqId, nil)
+	}
+
+	detectedMimeType, err := backend.DetectContentType(ctx, &storage.ObjectContentTypeDetection{
+		Bucket:     bucket.Name,
+		Name:       object.Name,
+		Encryption: encryption,
+	})
+	if err != nil {
+		os.logger.Error("failed to detect object content type in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to complete multipart upload session", op, reqId, err)
+	}
+
+	// the parts have been assembled and the upload cannot be resumed, so rejected content is deleted with its object
+	if !models.IsDetectedMimeTypeAllowed(bucket.AllowedMimeTypes, object.MimeType, detectedMimeType) {
+		os.abortObjectUpload(ctx, backend, bucket.Name, object.ID, object.Name, op)
+		return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("uploaded content of object '%s' was detected as '%s' which is not allowed. bucket only allows [%s] mime types", object.ID, detectedMimeType, strings.Join(bucket.AllowedMimeTypes, ", ")), op, reqId, nil)
+	}
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		err = os.queries.WithTx(tx).ObjectUpdate(ctx, &database.ObjectUpdateParams{
+			ID:   object.ID,
+			Size: &objectInfo.ContentLength,
+		})
+		if err != nil {
+			os.logger.Error("failed to update object size in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to complete multipart upload session", op, reqId, err)
+		}
+
+		// the size declared for the session is only an estimate, so the quota is checked again with the size of the parts
+		if err = os.reserveBucketQuota(ctx, tx, bucket, 0, 0, op); err != nil {
+			return err
+		}
+
+		err = os.queries.WithTx(tx).ObjectCompleteUpload(ctx, &database.ObjectCompleteUploadParams{
+			ID:               object.ID,
+			DetectedMimeType: &detectedMimeType,
+		})
+		if err != nil {
+			os.logger.Error("failed to update object upload status in database to completed", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to complete multipart upload session", op, reqId, err)
+		}
+
+		err = os.queries.WithTx(tx).MultipartUploadSessionDelete(ctx, object.ID)
+		if err != nil {
+			os.logger.Error("failed to delete multipart upload session from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to complete multipart upload session", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		os.abortObjectUpload(ctx, backend, bucket.Name, object.ID, object.Name, op)
+		return nil, err
+	}
+
+	return os.GetObject(ctx, bucket.Id, object.ID)
+}
+
+func (os *ObjectService) AbortMultipartUploadSession(ctx context.Context, bucketId string, objectId string) error {
+	const op = "ObjectService.AbortMultipartUploadSession"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(objectId) {
+		return srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to abort multipart upload session", op, reqId, nil)
+	}
+
+	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return err
+	}
+
+	object, multipartUploadSession, err := os.getMultipartUploadSession(ctx, bucket, objectId, op)
+	if err != nil {
+		return err
+	}
+
+	err = backend.AbortMultipartUpload(ctx, &storage.MultipartUploadAbort{
+		Bucket:   bucket.Name,
+		Name:     object.Name,
+		UploadId: multipartUploadSession.UploadID,
+	})
+	if err != nil {
+		os.logger.Error("failed to abort multipart upload in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to abort multipart upload session", op, reqId, err)
+	}
+
+	// the multipart upload session is removed together with the object by the foreign key cascade
+	err = os.queries.ObjectDelete(ctx, object.ID)
+	if err != nil {
+		os.logger.Error("failed to delete object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to abort multipart upload session", op, reqId, err)
+	}
+
+	return nil
+}
+
+func (os *ObjectService) CreatePreSignedDownloadSession(ctx context.Context, bucketId string, objectId string, expiresIn int64) (*models.PreSignedDownloadSession, error) {
+	const op = "ObjectService.CreatePreSignedDownloadSession"
+	reqId := utils.RequestId(ctx)
+
+	var preSignedDownloadObject models.PreSignedDownloadSession
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to create pre-signed download session", op, reqId, nil)
+	}
+
+	if !models.IsNotEmptyTrimmedString(objectId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to create pre-signed download session", op, reqId, nil)
+	}
+
+	if expiresIn == 0 {
+		expiresIn = os.config.DefaultPreSignedUploadUrlExpiry
+	} else {
+		if expiresIn < 0 {
+			return nil, srverr.NewServiceError(srverr.InvalidInputError, "expires_in must be greater than 0", op, reqId, nil)
+		}
+	}
+
+	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		object, err := os.queries.WithTx(tx).ObjectGetById(ctx, objectId)
+		if err != nil {
+			if database.IsNotFoundError(err) {
+				return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
+			}
+			os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
+		}
+
+		if object.EncryptionDataKey != nil {
+			return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object '%s' is encrypted by the service. it can only be downloaded through the server", object.ID), op, reqId, nil)
+		}
+
+		encryption, err := os.getObjectEncryption(ctx, object, op)
+		if err != nil {
+			return err
+		}
+
+		if object.UploadStatus == models.ObjectUploadStatusPending {
+			objectExists, err := backend.CheckIfObjectExists(ctx, &storage.ObjectExistsCheck{
+				Bucket:     bucket.Name,
+				Name:       object.Name,
+				Encryption: encryption,
+			})
+			if err != nil {
+				os.logger.Error("failed to check if object exists in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+				return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
+			}
+
+			if objectExists {
+				if err = os.completeObjectUpload(ctx, os.queries.WithTx(tx), backend, bucket, object, encryption, op); err != nil {
+					return err
+				}
+			} else {
+				return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' upload has not been completed", object.ID), op, reqId, nil)
+			}
+		}
+
+		preSignedObject, err := backend.CreatePreSignedDownloadObject(ctx, &storage.PreSignedDownloadObjectCreate{
+			Bucket:     bucket.Name,
+			Name:       object.Name,
+			Encryption: encryption,
+		})
+		if err != nil {
+			os.logger.Error("failed to create pre-signed download url", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
+		}
+
+		err = os.queries.WithTx(tx).ObjectUpdateLastAccessedAt(ctx, object.ID)
+		if err != nil {
+			os.logger.Error("failed to update object last accessed at", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to create pre-signed download session", op, reqId, err)
+		}
+
+		preSignedDownloadObject = models.PreSignedDownloadSession{
+			Url:       preSignedObject.Url,
+			Method:    preSignedObject.Method,
+			ExpiresAt: preSignedObject.ExpiresAt,
+			Headers:   preSignedObject.Headers,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &preSignedDownloadObject, nil
+}
+
+func (os *ObjectService) DownloadObject(ctx context.Context, objectDownload *models.ObjectDownload) (*models.ObjectContent, error) {
+	const op = "ObjectService.DownloadObject"
+	reqId := utils.RequestId(ctx)
+
+	if err := objectDownload.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	bucket, err := os.getBucketById(ctx, objectDownload.BucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := os.queries.ObjectGetByBucketIdAndId(ctx, &database.ObjectGetByBucketIdAndIdParams{
+		BucketID: bucket.Id,
+		ID:       objectDownload.ObjectId,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectDownload.ObjectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+	}
+
+	encryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if object.UploadStatus == models.ObjectUploadStatusPending {
+		objectExists, err := backend.CheckIfObjectExists(ctx, &storage.ObjectExistsCheck{
+			Bucket:     bucket.Name,
+			Name:       object.Name,
+			Encryption: encryption,
+		})
+		if err != nil {
+			os.logger.Error("failed to check if object exists in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+		}
+
+		if !objectExists {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' upload has not been completed", object.ID), op, reqId, nil)
+		}
+
+		if err = os.completeObjectUpload(ctx, os.queries, backend, bucket, object, encryption, op); err != nil {
+			return nil, err
+		}
+	}
+
+	return os.downloadObjectContent(ctx, backend, bucket.Name, object, encryption, objectDownload, op)
+}
+
+// DownloadPublicObject downloads an object of a public bucket by bucket and object name without authentication.
+// missing, private and unavailable buckets are all reported as a missing object so that bucket names are not leaked
+func (os *ObjectService) DownloadPublicObject(ctx context.Context, publicObjectDownload *models.PublicObjectDownload) (*models.ObjectContent, error) {
+	const op = "ObjectService.DownloadPublicObject"
+	reqId := utils.RequestId(ctx)
+
+	if err := publicObjectDownload.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	notFoundMessage := fmt.Sprintf("object '%s' not found in bucket '%s'", publicObjectDownload.ObjectName, publicObjectDownload.BucketName)
+
+	bucket, err := os.queries.BucketGetByName(ctx, publicObjectDownload.BucketName)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, notFoundMessage, op, reqId, err)
+		}
+		os.logger.Error("failed to get bucket by name", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+	}
+
+	if !bucket.Public || bucket.Disabled || bucket.Locked {
+		return nil, srverr.NewServiceError(srverr.NotFoundError, notFoundMessage, op, reqId, nil)
+	}
+
+	backend, err := os.storage.Backend(bucket.Backend)
+	if err != nil {
+		os.logger.Error("failed to get storage backend of bucket", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+	}
+
+	object, err := os.queries.ObjectGetByBucketIdAndName(ctx, &database.ObjectGetByBucketIdAndNameParams{
+		BucketID: bucket.ID,
+		Name:     publicObjectDownload.ObjectName,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, notFoundMessage, op, reqId, err)
+		}
+		os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+	}
+
+	if object.UploadStatus == models.ObjectUploadStatusPending {
+		return nil, srverr.NewServiceError(srverr.NotFoundError, notFoundMessage, op, reqId, nil)
+	}
+
+	encryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.downloadObjectContent(ctx, backend, bucket.Name, object, encryption, &models.ObjectDownload{
+		BucketId:        bucket.ID,
+		ObjectId:        object.ID,
+		Range:           publicObjectDownload.Range,
+		IfNoneMatch:     publicObjectDownload.IfNoneMatch,
+		IfModifiedSince: publicObjectDownload.IfModifiedSince,
+		HeadOnly:        publicObjectDownload.HeadOnly,
+	}, op)
+}
+
+// completeObjectUpload marks the upload of a pending object that exists in storage as completed, after verifying its
+// content against the checksum declared for the upload session and the mime types allowed by the bucket. content that
+// fails verification is deleted from storage so that the client can upload it again before the session expires
+func (os *ObjectService) completeObjectUpload(ctx context.Context, queries *database.Queries, backend storage.Backend, bucket *models.Bucket, object *database.StorageObject, encryption *storage.Encryption, op string) error {
+	reqId := utils.RequestId(ctx)
+
+	if object.ChecksumAlgorithm != nil {
+		objectInfo, err := backend.HeadObject(ctx, &storage.ObjectHead{
+			Bucket:     bucket.Name,
+			Name:       object.Name,
+			Encryption: encryption,
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrObjectNotFound) {
+				return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object '%s' has not yet been uploaded to storage", object.ID), op, reqId, err)
+			}
+			os.logger.Error("failed to head object in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to complete object upload", op, reqId, err)
+		}
+
+		if !objectInfo.MatchesChecksum(*object.ChecksumAlgorithm, *object.Checksum) {
+			return os.rejectObjectContent(ctx, backend, bucket, object, fmt.Sprintf("uploaded content of object '%s' does not match the %s checksum '%s'", object.ID, *object.ChecksumAlgorithm, *object.Checksum), op)
+		}
+	}
+
+	detectedMimeType, err := backend.DetectContentType(ctx, &storage.ObjectContentTypeDetection{
+		Bucket:     bucket.Name,
+		Name:       object.Name,
+		Encryption: encryption,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object '%s' has not yet been uploaded to storage", object.ID), op, reqId, err)
+		}
+		os.logger.Error("failed to detect object content type in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to complete object upload", op, reqId, err)
+	}
+
+	if !models.IsDetectedMimeTypeAllowed(bucket.AllowedMimeTypes, object.MimeType, detectedMimeType) {
+		return os.rejectObjectContent(ctx, backend, bucket, object, fmt.Sprintf("uploaded content of object '%s' was detected as '%s' which is not allowed. bucket only allows [%s] mime types", object.ID, detectedMimeType, strings.Join(bucket.AllowedMimeTypes, ", ")), op)
+	}
+
+	err = queries.ObjectCompleteUpload(ctx, &database.ObjectCompleteUploadParams{
+		ID:               object.ID,
+		DetectedMimeType: &detectedMimeType,
+	})
+	if err != nil {
+		os.logger.Error("failed to update object upload status in database to completed", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to complete object upload", op, reqId, err)
+	}
+
+	object.UploadStatus = models.ObjectUploadStatusCompleted
+	object.DetectedMimeType = &detectedMimeType
+
+	return nil
+}
+
+// rejectObjectContent deletes content that failed verification from storage and returns the reason as a bad request
+func (os *ObjectService) rejectObjectContent(ctx context.Context, backend storage.Backend, bucket *models.Bucket, object *database.StorageObject, reason string, op string) error {
+	reqId := utils.RequestId(ctx)
+
+	err := backend.DeleteObject(ctx, &storage.ObjectDelete{
+		Bucket: bucket.Name,
+		Name:   object.Name,
+	})
+	if err != nil {
+		os.logger.Error("failed to delete rejected object content from storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to complete object upload", op, reqId, err)
+	}
+
+	return srverr.NewServiceError(srverr.BadRequestError, reason, op, reqId, nil)
+}
+
+// downloadObjectContent resolves the headers of an object in storage and opens its content
+// according to the range and conditional headers of the download
+func (os *ObjectService) downloadObjectContent(ctx context.Context, backend storage.Backend, bucketName string, object *database.StorageObject, encryption *storage.Encryption, objectDownload *models.ObjectDownload, op string) (*models.ObjectContent, error) {
+	reqId := utils.RequestId(ctx)
+
+	objectInfo, err := backend.HeadObject(ctx, &storage.ObjectHead{
+		Bucket:     bucketName,
+		Name:       object.Name,
+		Encryption: encryption,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' upload has not been completed", object.ID), op, reqId, err)
+		}
+		os.logger.Error("failed to head object in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+	}
+
+	dataKeyCipher, err := os.getObjectCipher(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
+
+	size := objectInfo.ContentLength
+	if dataKeyCipher != nil {
+		size, err = envelope.PlaintextSize(objectInfo.ContentLength)
+		if err != nil {
+			os.logger.Error("failed to get plaintext size of encrypted object", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+		}
+	}
+
+	objectContent := &models.ObjectContent{
+		MimeType:      object.MimeType,
+		Size:          size,
+		ContentLength: size,
+		ETag:          objectInfo.ETag,
+		LastModified:  objectInfo.LastModified,
+	}
+
+	if isNotModified(objectDownload, objectInfo.ETag, objectInfo.LastModified) {
+		objectContent.NotModified = true
+		objectContent.ContentLength = 0
+		return objectContent, nil
+	}
+
+	start, end := int64(0), size-1
+	var contentRange *string
+	if objectDownload.Range != nil {
+		rangeStart, rangeEnd, ok, err := parseByteRange(*objectDownload.Range, size)
+		if err != nil {
+			return nil, srverr.NewServiceError(srverr.RangeNotSatisfiableError, fmt.Sprintf("range '%s' is not satisfiable for object of size %d bytes", *objectDownload.Range, size), op, reqId, err)
+		}
+		if ok {
+			start, end = rangeStart, rangeEnd
+			contentRange = lo.ToPtr(fmt.Sprintf("bytes=%d-%d", start, end))
+			objectContent.ContentLength = end - start + 1
+			objectContent.ContentRange = lo.ToPtr(fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+		}
+	}
+
+	if contentRange == nil {
+		objectContent.ChecksumAlgorithm = object.ChecksumAlgorithm
+		objectContent.Checksum = object.Checksum
+	}
+
+	if objectDownload.HeadOnly {
+		return objectContent, nil
+	}
+
+	// encrypted content is read in whole chunks, which are decrypted and trimmed to the requested range
+	if dataKeyCipher != nil && contentRange != nil {
+		encryptedStart, encryptedEnd := envelope.EncryptedRange(size, start, end)
+		contentRange = lo.ToPtr(fmt.Sprintf("bytes=%d-%d", encryptedStart, encryptedEnd))
+	}
+
+	storageContent, err := backend.GetObject(ctx, &storage.ObjectGet{
+		Bucket:     bucketName,
+		Name:       object.Name,
+		Range:      contentRange,
+		Encryption: encryption,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' upload has not been completed", object.ID), op, reqId, err)
+		}
+		os.logger.Error("failed to get object from storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+	}
+
+	err = os.queries.ObjectUpdateLastAccessedAt(ctx, object.ID)
+	if err != nil {
+		_ = storageContent.Content.Close()
+		os.logger.Error("failed to update object last accessed at", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to download object", op, reqId, err)
+	}
+
+	objectContent.ContentLength = storageContent.ContentLength
+	objectContent.Content = storageContent.Content
+
+	if dataKeyCipher != nil {
+		objectContent.ContentLength = end - start + 1
+		objectContent.Content = &decryptedContent{
+			Reader: dataKeyCipher.DecryptReader(storageContent.Content, size, start, end),
+			Closer: storageContent.Content,
+		}
+	}
+
+	return objectContent, nil
+}
+
+func (os *ObjectService) RenameObject(ctx context.Context, objectRename *models.ObjectRename) (*models.Object, error) {
+	const op = "ObjectService.RenameObject"
+	reqId := utils.RequestId(ctx)
+
+	if err := objectRename.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	return os.moveObject(ctx, objectRename.BucketId, objectRename.ObjectId, objectRename.BucketId, objectRename.Name, op)
+}
+
+func (os *ObjectService) MoveObject(ctx context.Context, objectMove *models.ObjectMove) (*models.Object, error) {
+	const op = "ObjectService.MoveObject"
+	reqId := utils.RequestId(ctx)
+
+	if err := objectMove.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	destinationBucketId := lo.FromPtrOr(objectMove.DestinationBucketId, objectMove.BucketId)
+
+	return os.moveObject(ctx, objectMove.BucketId, objectMove.ObjectId, destinationBucketId, objectMove.DestinationName, op)
+}
+
+func (os *ObjectService) CopyObject(ctx context.Context, objectCopy *models.ObjectCopy) (*models.Object, error) {
+	const op = "ObjectService.CopyObject"
+	reqId := utils.RequestId(ctx)
+
+	var id string
+
+	if err := objectCopy.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	destinationBucketId := lo.FromPtrOr(objectCopy.DestinationBucketId, objectCopy.BucketId)
+
+	sourceBucket, object, destinationBucket, err := os.getObjectCopyTarget(ctx, objectCopy.BucketId, objectCopy.ObjectId, destinationBucketId, objectCopy.DestinationName, op)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceEncryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationEncryption, err := os.getBucketEncryption(ctx, destinationBucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationBackend, err := os.getBucketBackend(ctx, destinationBucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationCipher, destinationDataKey, destinationMasterKeyId, err := os.getCopyDataKey(ctx, object, destinationBucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// the copy is created as a pending object that reserves its name and quota, so that the content is copied in
+	// storage outside of any transaction
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if err = os.reserveBucketQuota(ctx, tx, destinationBucket, 1, object.Size, op); err != nil {
+			return err
+		}
+
+		encryptionAlgorithm, encryptionKmsKeyId, encryptionCustomerKeyMd5 := encryptionColumns(destinationEncryption)
+
+		id, err = os.queries.WithTx(tx).ObjectCreate(ctx, &database.ObjectCreateParams{
+			BucketID:                 destinationBucket.Id,
+			Name:                     objectCopy.DestinationName,
+			ContentType:              &object.MimeType,
+			Size:                     object.Size,
+			Metadata:                 object.Metadata,
+			ChecksumAlgorithm:        object.ChecksumAlgorithm,
+			Checksum:                 object.Checksum,
+			DetectedMimeType:         object.DetectedMimeType,
+			UploadStatus:             models.ObjectUploadStatusPending,
+			Encryption:               encryptionAlgorithm,
+			EncryptionKmsKeyID:       encryptionKmsKeyId,
+			EncryptionCustomerKeyMd5: encryptionCustomerKeyMd5,
+			EncryptionDataKey:        destinationDataKey,
+			EncryptionMasterKeyID:    destinationMasterKeyId,
+		})
+		if err != nil {
+			if database.IsConflictError(err) {
+				return srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("object with name '%s' already exists", objectCopy.DestinationName), op, reqId, err)
+			}
+			os.logger.Error("failed to create object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to copy object", op, reqId, err)
+		}
+
+		err = os.queries.WithTx(tx).ObjectTagCopy(ctx, &database.ObjectTagCopyParams{
+			DestinationObjectID: id,
+			SourceObjectID:      object.ID,
+		})
+		if err != nil {
+			os.logger.Error("failed to copy object tags in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to copy object", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.copyObjectInStorage(ctx, sourceBucket, object, sourceEncryption, destinationBucket, destinationEncryption, destinationCipher, objectCopy.DestinationName, op)
+	if err != nil {
+		os.abortObjectUpload(ctx, destinationBackend, destinationBucket.Name, id, objectCopy.DestinationName, op)
+		return nil, err
+	}
+
+	err = os.queries.ObjectCompleteUpload(ctx, &database.ObjectCompleteUploadParams{
+		DetectedMimeType: object.DetectedMimeType,
+		ID:               id,
+	})
+	if err != nil {
+		os.logger.Error("failed to complete object upload in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		os.abortObjectUpload(ctx, destinationBackend, destinationBucket.Name, id, objectCopy.DestinationName, op)
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to copy object", op, reqId, err)
+	}
+
+	return os.GetObject(ctx, destinationBucket.Id, id)
+}
+
+func (os *ObjectService) UpdateObject(ctx context.Context, objectUpdate *models.ObjectUpdate) (*models.Object, error) {
+	const op = "ObjectService.UpdateObject"
+	reqId := utils.RequestId(ctx)
+
+	objectUpdate.SetDefaults()
+
+	if err := objectUpdate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	bucket, err := os.getBucketById(ctx, objectUpdate.BucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		object, err := os.queries.WithTx(tx).ObjectGetByBucketIdAndId(ctx, &database.ObjectGetByBucketIdAndIdParams{
+			BucketID: bucket.Id,
+			ID:       objectUpdate.ObjectId,
+		})
+		if err != nil {
+			if database.IsNotFoundError(err) {
+				return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectUpdate.ObjectId), op, reqId, err)
+			}
+			os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to update object", op, reqId, err)
+		}
+
+		if object.UploadStatus == models.ObjectUploadStatusPending {
+			return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("upload has not yet been completed for object '%s'. update operation can only be performed on objects that have been uploaded", object.ID), op, reqId, nil)
+		}
+
+		encryption, err := os.getObjectEncryption(ctx, object, op)
+		if err != nil {
+			return err
+		}
+
+		var mimeType *string
+		if objectUpdate.MimeType != nil && *objectUpdate.MimeType != object.MimeType {
+			mimeType, err = resolveMimeType(bucket, object.Name, objectUpdate.MimeType)
+			if err != nil {
+				return srverr.NewServiceError(srverr.BadRequestError, err.Error(), op, reqId, err)
+			}
+		}
+
+		var metadata []byte
+		switch {
+		case objectUpdate.MetadataMode == models.ObjectMetadataUpdateModeReplace:
+			metadata = metadataToBytes(lo.Ternary(objectUpdate.Metadata == nil, map[string]any{}, objectUpdate.Metadata))
+		case objectUpdate.Metadata != nil:
+			metadata = metadataToBytes(mergeMetadata(bytesToMetadata(object.Metadata), objectUpdate.Metadata))
+		}
+
+		err = os.queries.WithTx(tx).ObjectUpdate(ctx, &database.ObjectUpdateParams{
+			ID:       object.ID,
+			MimeType: mimeType,
+			Metadata: metadata,
+		})
+		if err != nil {
+			os.logger.Error("failed to update object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to update object", op, reqId, err)
+		}
+
+		// the content type stored in s3 is served by pre-signed downloads, so it is replaced in place to stay consistent
+		if mimeType != nil {
+			err = backend.CopyObject(ctx, &storage.ObjectCopy{
+				SourceBucket:      bucket.Name,
+				SourceName:        object.Name,
+				DestinationBucket: bucket.Name,
+				DestinationName:   object.Name,
+				ContentType:       mimeType,
+				SourceEncryption:  encryption,
+				Encryption:        encryption,
+			})
+			if err != nil {
+				if errors.Is(err, storage.ErrObjectNotFound) {
+					return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found in storage", object.ID), op, reqId, err)
+				}
+				os.logger.Error("failed to update object content type in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+				return srverr.NewServiceError(srverr.UnknownError, "failed to update object", op, reqId, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return os.GetObject(ctx, bucket.Id, objectUpdate.ObjectId)
+}
+
+func (os *ObjectService) UpdateObjectTags(ctx context.Context, objectTagsUpdate *models.ObjectTagsUpdate) (*models.Object, error) {
+	const op = "ObjectService.UpdateObjectTags"
+	reqId := utils.RequestId(ctx)
+
+	if err := objectTagsUpdate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	bucket, err := os.getBucketById(ctx, objectTagsUpdate.BucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		object, err := os.queries.WithTx(tx).ObjectGetByBucketIdAndId(ctx, &database.ObjectGetByBucketIdAndIdParams{
+			BucketID: bucket.Id,
+			ID:       objectTagsUpdate.ObjectId,
+		})
+		if err != nil {
+			if database.IsNotFoundError(err) {
+				return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectTagsUpdate.ObjectId), op, reqId, err)
+			}
+			os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to update object tags", op, reqId, err)
+		}
+
+		if object.UploadStatus == models.ObjectUploadStatusPending {
+			return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("upload has not yet been completed for object '%s'. update tags operation can only be performed on objects that have been uploaded", object.ID), op, reqId, nil)
+		}
+
+		err = os.queries.WithTx(tx).ObjectTagDeleteByObjectId(ctx, object.ID)
+		if err != nil {
+			os.logger.Error("failed to delete object tags from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to update object tags", op, reqId, err)
+		}
+
+		return os.setObjectTags(ctx, os.queries.WithTx(tx), object.ID, objectTagsUpdate.Tags, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return os.GetObject(ctx, bucket.Id, objectTagsUpdate.ObjectId)
+}
+
+func (os *ObjectService) DeleteObject(ctx context.Context, bucketId string, objectId string) error {
+	const op = "ObjectService.DeleteObject"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to delete object", op, reqId, nil)
+	}
+
+	if !models.IsNotEmptyTrimmedString(objectId) {
+		return srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to delete object", op, reqId, nil)
+	}
+
+	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return err
+	}
+
+	object, err := os.queries.ObjectGetByBucketIdAndId(ctx, &database.ObjectGetByBucketIdAndIdParams{
+		BucketID: bucket.Id,
+		ID:       objectId,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to delete object", op, reqId, err)
+	}
+
+	if object.UploadStatus == models.ObjectUploadStatusPending {
+		return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("upload has not yet been completed for object '%s'. delete operation can only be performed on objects that have been uploaded", object.ID), op, reqId, nil)
+	}
+
+	if bucket.TrashRetentionDays == 0 {
+		_, err = os.job.Insert(ctx, jobs.ObjectDeletion{
+			ObjectId: object.ID,
+		}, nil)
+		if err != nil {
+			os.logger.Error("failed create object deletion job", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to delete object", op, reqId, err)
+		}
+
+		return nil
+	}
+
+	encryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return err
+	}
+
+	// the content of the object is copied to the trash of its bucket under its id before the object is marked deleted,
+	// which releases its name. the content under its name is deleted by a job once the object is in the trash
+	err = backend.CopyObject(ctx, &storage.ObjectCopy{
+		SourceBucket:      bucket.Name,
+		SourceName:        object.Name,
+		DestinationBucket: storage.TrashBucket(bucket.Name),
+		DestinationName:   object.ID,
+		SourceEncryption:  encryption,
+		Encryption:        encryption,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found in storage", object.ID), op, reqId, err)
+		}
+		os.logger.Error("failed to copy object to trash in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to delete object", op, reqId, err)
+	}
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		trashed, err := os.queries.WithTx(tx).ObjectTrash(ctx, &database.ObjectTrashParams{
+			ID:       object.ID,
+			BucketID: bucket.Id,
+			Name:     object.Name,
+		})
+		if err != nil {
+			os.logger.Error("failed to move object to trash in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to delete object", op, reqId, err)
+		}
+		if trashed == 0 {
+			return srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("object '%s' was deleted, renamed or moved while it was being deleted", object.ID), op, reqId, nil)
+		}
+
+		_, err = os.job.InsertTx(ctx, tx, jobs.ObjectSourceDeletion{
+			BucketId:      bucket.Id,
+			BucketName:    bucket.Name,
+			BucketBackend: bucket.Backend,
+			ObjectName:    object.Name,
+		}, nil)
+		if err != nil {
+			os.logger.Error("failed create object source deletion job", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to delete object", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		os.deleteUploadedObject(ctx, backend, storage.TrashBucket(bucket.Name), object.ID, op)
+		return err
+	}
+
+	return nil
+}
+
+// CreateObjectBulkDeletion deletes the uploaded objects of a bucket selected by ids or by a name prefix and tags with a
+// single job that moves the objects to the trash of the bucket, or deletes them from storage when the deletion is permanent,
+// in batches. the returned bulk deletion can be polled with GetObjectBulkDeletion for its progress
+func (os *ObjectService) CreateObjectBulkDeletion(ctx context.Context, bulkDeletionCreate *models.ObjectBulkDeletionCreate) (*models.ObjectBulkDeletion, error) {
+	const op = "ObjectService.CreateObjectBulkDeletion"
+	reqId := utils.RequestId(ctx)
+
+	if err := bulkDeletionCreate.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	bulkDeletionCreate.PreSave()
+
+	bucket, err := os.getBucketById(ctx, bulkDeletionCreate.BucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		totalCount := int64(len(bulkDeletionCreate.ObjectIds))
+		var failures []*models.ObjectBulkDeletionFailure
+
+		if len(bulkDeletionCreate.ObjectIds) == 0 {
+			totalCount, err = os.queries.WithTx(tx).ObjectCountForBulkDeletion(ctx, &database.ObjectCountForBulkDeletionParams{
+				BucketID: bucket.Id,
+				Prefix:   bulkDeletionCreate.Prefix,
+				Tags:     tagsToBytes(bulkDeletionCreate.Tags),
+			})
+			if err != nil {
+				os.logger.Error("failed to count objects for bulk deletion", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+				return srverr.NewServiceError(srverr.UnknownError, "failed to delete objects", op, reqId, err)
+			}
+		} else {
+			objectIds, err := os.queries.WithTx(tx).ObjectListIdsByBucketIdAndIds(ctx, &database.ObjectListIdsByBucketIdAndIdsParams{
+				BucketID: bucket.Id,
+				Ids:      bulkDeletionCreate.ObjectIds,
+			})
+			if err != nil {
+				os.logger.Error("failed to list objects by ids", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+				return srverr.NewServiceError(srverr.UnknownError, "failed to delete objects", op, reqId, err)
+			}
+
+			found := lo.SliceToMap(objectIds, func(objectId string) (string, bool) {
+				return objectId, true
+			})
+			for _, objectId := range bulkDeletionCreate.ObjectIds {
+				if !found[objectId] {
+					failures = append(failures, &models.ObjectBulkDeletionFailure{
+						ObjectId: objectId,
+						Error:    "object not found or its upload has not been completed",
+					})
+				}
+			}
+		}
+
+		failedCount := int64(len(failures))
+		if len(failures) > models.ObjectBulkDeletionMaxReportedFailures {
+			failures = failures[:models.ObjectBulkDeletionMaxReportedFailures]
+		}
+
+		id, err = os.queries.WithTx(tx).ObjectBulkDeletionCreate(ctx, &database.ObjectBulkDeletionCreateParams{
+			BucketID:    bucket.Id,
+			ObjectIds:   bulkDeletionCreate.ObjectIds,
+			Prefix:      bulkDeletionCreate.Prefix,
+			Tags:        tagsToBytes(bulkDeletionCreate.Tags),
+			Permanent:   bulkDeletionCreate.Permanent || bucket.TrashRetentionDays == 0,
+			TotalCount:  totalCount,
+			FailedCount: failedCount,
+			Failures:    objectBulkDeletionFailuresToBytes(failures),
+		})
+		if err != nil {
+			os.logger.Error("failed to create bulk deletion", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to delete objects", op, reqId, err)
+		}
+
+		_, err = os.job.InsertTx(ctx, tx, jobs.ObjectBulkDeletion{
+			BulkDeletionId: id,
+		}, nil)
+		if err != nil {
+			os.logger.Error("failed create object bulk deletion job", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to delete objects", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return os.GetObjectBulkDeletion(ctx, bucket.Id, id)
+}
+
+func (os *ObjectService) GetObjectBulkDeletion(ctx context.Context, bucketId string, id string) (*models.ObjectBulkDeletion, error) {
+	const op = "ObjectService.GetObjectBulkDeletion"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to get bulk deletion", op, reqId, nil)
+	}
+
+	if !models.IsNotEmptyTrimmedString(id) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bulk_deletion_id cannot be empty. bulk_deletion_id is required to get bulk deletion", op, reqId, nil)
+	}
+
+	bulkDeletion, err := os.queries.ObjectBulkDeletionGetByBucketIdAndId(ctx, &database.ObjectBulkDeletionGetByBucketIdAndIdParams{
+		BucketID: bucketId,
+		ID:       id,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("bulk deletion '%s' not found in bucket '%s'", id, bucketId), op, reqId, err)
+		}
+		os.logger.Error("failed to get bulk deletion", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to get bulk deletion", op, reqId, err)
+	}
+
+	return toObjectBulkDeletion(bulkDeletion), nil
+}
+
+// RestoreObject moves an object out of the trash of its bucket under the name it was deleted with. the object is
+// restored as pending, which reserves its name and quota while its content is copied back from the trash, and goes
+// back to the trash when the copy fails
+func (os *ObjectService) RestoreObject(ctx context.Context, bucketId string, objectId string) (*models.Object, error) {
+	const op = "ObjectService.RestoreObject"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to restore object", op, reqId, nil)
+	}
+
+	if !models.IsNotEmptyTrimmedString(objectId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to restore object", op, reqId, nil)
+	}
+
+	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := os.getBucketBackend(ctx, bucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := os.queries.ObjectGetTrashedByBucketIdAndId(ctx, &database.ObjectGetTrashedByBucketIdAndIdParams{
+		BucketID: bucket.Id,
+		ID:       objectId,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found in trash", objectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get trashed object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to restore object", op, reqId, err)
+	}
+
+	encryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if err := os.reserveBucketQuota(ctx, tx, bucket, 1, object.Size, op); err != nil {
+			return err
+		}
+
+		restored, err := os.queries.WithTx(tx).ObjectRestore(ctx, object.ID)
+		if err != nil {
+			if database.IsConflictError(err) {
+				return srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("object with name '%s' already exists. rename or delete it to restore object '%s'", object.Name, object.ID), op, reqId, err)
+			}
+			os.logger.Error("failed to restore object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to restore object", op, reqId, err)
+		}
+		if restored == 0 {
+			return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found in trash", object.ID), op, reqId, nil)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = backend.CopyObject(ctx, &storage.ObjectCopy{
+		SourceBucket:      storage.TrashBucket(bucket.Name),
+		SourceName:        object.ID,
+		DestinationBucket: bucket.Name,
+		DestinationName:   object.Name,
+		SourceEncryption:  encryption,
+		Encryption:        encryption,
+	})
+	if err != nil {
+		os.returnObjectToTrash(ctx, backend, bucket.Name, object, op)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found in storage", object.ID), op, reqId, err)
+		}
+		os.logger.Error("failed to copy object from trash in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to restore object", op, reqId, err)
+	}
+
+	err = os.queries.ObjectCompleteUpload(ctx, &database.ObjectCompleteUploadParams{
+		DetectedMimeType: object.DetectedMimeType,
+		ID:               object.ID,
+	})
+	if err != nil {
+		os.logger.Error("failed to complete restored object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		os.returnObjectToTrash(ctx, backend, bucket.Name, object, op)
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to restore object", op, reqId, err)
+	}
+
+	os.deleteUploadedObject(ctx, backend, storage.TrashBucket(bucket.Name), object.ID, op)
+
+	return os.GetObject(ctx, bucket.Id, objectId)
+}
+
+// DeleteTrashedObject permanently deletes an object from the trash of its bucket before its trash retention has
+// passed
+func (os *ObjectService) DeleteTrashedObject(ctx context.Context, bucketId string, objectId string) error {
+	const op = "ObjectService.DeleteTrashedObject"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to delete trashed object", op, reqId, nil)
+	}
+
+	if !models.IsNotEmptyTrimmedString(objectId) {
+		return srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to delete trashed object", op, reqId, nil)
+	}
+
+	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return err
+	}
+
+	object, err := os.queries.ObjectGetTrashedByBucketIdAndId(ctx, &database.ObjectGetTrashedByBucketIdAndIdParams{
+		BucketID: bucket.Id,
+		ID:       objectId,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found in trash", objectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get trashed object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to delete trashed object", op, reqId, err)
+	}
+
+	// the job keeps the object when it is restored before the job runs
+	_, err = os.job.Insert(ctx, jobs.ObjectDeletion{
+		ObjectId: object.ID,
+		Trashed:  true,
+	}, nil)
+	if err != nil {
+		os.logger.Error("failed create object deletion job", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to delete trashed object", op, reqId, err)
+	}
+
+	return nil
+}
+
+// ListTrashedObjects lists the objects in the trash of a bucket, most recently deleted first
+func (os *ObjectService) ListTrashedObjects(ctx context.Context, bucketId string, limit int32, offset int32) ([]*models.Object, error) {
+	const op = "ObjectService.ListTrashedObjects"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to list trashed objects", op, reqId, nil)
+	}
+
+	if limit < 0 {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "limit cannot be less than 0", op, reqId, nil)
+	}
+
+	if offset < 0 {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "offset cannot be less than 0", op, reqId, nil)
+	}
+
+	if limit == 0 {
+		limit = 100
+	}
+
+	bucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := os.queries.ObjectListTrashedByBucketId(ctx, &database.ObjectListTrashedByBucketIdParams{
+		BucketID: bucket.Id,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		os.logger.Error("failed to list trashed objects", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to list trashed objects", op, reqId, err)
+	}
+
+	result := make([]*models.Object, 0, len(objects))
+
+	for _, object := range objects {
+		result = append(result, &models.Object{
+			Id:                       object.ID,
+			Version:                  object.Version,
+			BucketId:                 object.BucketID,
+			Name:                     object.Name,
+			MimeType:                 object.MimeType,
+			Size:                     object.Size,
+			Metadata:                 bytesToMetadata(object.Metadata),
+			ChecksumAlgorithm:        object.ChecksumAlgorithm,
+			Checksum:                 object.Checksum,
+			DetectedMimeType:         object.DetectedMimeType,
+			UploadStatus:             object.UploadStatus,
+			LastAccessedAt:           object.LastAccessedAt,
+			CreatedAt:                object.CreatedAt,
+			UpdatedAt:                object.UpdatedAt,
+			DeletedAt:                object.DeletedAt,
+			Encryption:               object.Encryption,
+			EncryptionKmsKeyId:       object.EncryptionKmsKeyID,
+			EncryptionCustomerKeyMd5: object.EncryptionCustomerKeyMd5,
+			Encrypted:                object.EncryptionDataKey != nil,
+		})
+	}
+
+	if err = os.attachObjectTags(ctx, result, op); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (os *ObjectService) GetObject(ctx context.Context, bucketId string, objectId string) (*models.Object, error) {
+	const op = "ObjectService.GetObject"
+	reqId := utils.RequestId(ctx)
+
+	if !models.IsNotEmptyTrimmedString(bucketId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to get object", op, reqId, nil)
+	}
+
+	if !models.IsNotEmptyTrimmedString(objectId) {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object_id cannot be empty. object_id is required to get object", op, reqId, nil)
+	}
+
+	_, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := os.queries.ObjectGetById(ctx, objectId)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get object", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to get object", op, reqId, err)
+	}
+
+	result := &models.Object{
+		Id:                       object.ID,
+		BucketId:                 object.BucketID,
+		Name:                     object.Name,
+		MimeType:                 object.MimeType,
+		Size:                     object.Size,
+		Metadata:                 bytesToMetadata(object.Metadata),
+		ChecksumAlgorithm:        object.ChecksumAlgorithm,
+		Checksum:                 object.Checksum,
+		DetectedMimeType:         object.DetectedMimeType,
+		UploadStatus:             object.UploadStatus,
+		LastAccessedAt:           object.LastAccessedAt,
+		CreatedAt:                object.CreatedAt,
+		UpdatedAt:                object.UpdatedAt,
+		Encryption:               object.Encryption,
+		EncryptionKmsKeyId:       object.EncryptionKmsKeyID,
+		EncryptionCustomerKeyMd5: object.EncryptionCustomerKeyMd5,
+		Encrypted:                object.EncryptionDataKey != nil,
+	}
+
+	if err = os.attachObjectTags(ctx, []*models.Object{result}, op); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (os *ObjectService) SearchObjects(ctx context.Context, objectSearchInput *models.ObjectSearchInput, paginationInput *models.PaginationInput) (*models.ObjectSearchResult, error) {
+	const op = "ObjectService.SearchObjects"
+	reqId := utils.RequestId(ctx)
+
+	objectSearchInput.SetDefaults()
+
+	if err := objectSearchInput.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	paginationInput.SetDefaults()
+
+	if err := paginationInput.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	cursorScore, cursorId, previous, err := paginationInput.DecodeRankedCursor()
+	if err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+	if previous {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object search can only be paged forward with next_cursor", op, reqId, nil)
+	}
+
+	tags, _ := models.ParseObjectTagFilters(objectSearchInput.Tags)
+	tagKeys, tagValues := splitObjectTags(tags)
+
+	metadataFilter, _ := objectSearchInput.MetadataFilter()
+	metadataEquals, metadataContains, metadataExists, metadataRanges := metadataFilterParams(metadataFilter)
+
+	objects, err := os.queries.ObjectSearchByBucketIdAndObjectPath(ctx, &database.ObjectSearchByBucketIdAndObjectPathParams{
+		ObjectPath:       objectSearchInput.ObjectPath,
+		BucketID:         objectSearchInput.BucketId,
+		Fuzzy:            objectSearchInput.Mode == models.SearchModeFuzzy,
+		Pattern:          searchNamePattern(objectSearchInput.ObjectPath, objectSearchInput.Mode),
+		TagKeys:          tagKeys,
+		TagValues:        tagValues,
+		MetadataEquals:   metadataEquals,
+		MetadataContains: metadataContains,
+		MetadataExists:   metadataExists,
+		MetadataRanges:   metadataRanges,
+		CursorID:         cursorId,
+		CursorScore:      cursorScore,
+		Limit:            paginationInput.Limit + 1,
+	})
+	if err != nil {
+		os.logger.Error("failed to search objects", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to search objects", op, reqId, err)
+	}
+	if len(objects) == 0 {
+		if objectSearchInput.ObjectPath == "" {
+			return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("no objects found for bucket '%s' matching the filters", objectSearchInput.BucketId), op, reqId, nil)
+		}
+		return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("no objects found for bucket '%s' with path '%s'", objectSearchInput.BucketId, objectSearchInput.ObjectPath), op, reqId, nil)
+	}
+
+	result := &models.ObjectSearchResult{
+		Objects: []*models.Object{},
+	}
+
+	if len(objects) > int(paginationInput.Limit) {
+		objects = objects[:paginationInput.Limit]
+		last := objects[len(objects)-1]
+		result.HasNext = true
+		result.NextCursor = models.EncodeCursor(models.RankedCursorId(last.Score, last.ID), false)
+	}
+
+	for _, object := range objects {
+		result.Objects = append(result.Objects, &models.Object{
+			Id:                       object.ID,
+			Version:                  object.Version,
+			BucketId:                 object.BucketID,
+			Name:                     object.Name,
+			MimeType:                 object.MimeType,
+			Size:                     object.Size,
+			Metadata:                 bytesToMetadata(object.Metadata),
+			ChecksumAlgorithm:        object.ChecksumAlgorithm,
+			Checksum:                 object.Checksum,
+			DetectedMimeType:         object.DetectedMimeType,
+			UploadStatus:             object.UploadStatus,
+			LastAccessedAt:           object.LastAccessedAt,
+			CreatedAt:                object.CreatedAt,
+			UpdatedAt:                object.UpdatedAt,
+			Encryption:               object.Encryption,
+			EncryptionKmsKeyId:       object.EncryptionKmsKeyID,
+			EncryptionCustomerKeyMd5: object.EncryptionCustomerKeyMd5,
+			Encrypted:                object.EncryptionDataKey != nil,
+		})
+	}
+
+	if err = os.attachObjectTags(ctx, result.Objects, op); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// moveObject copies an object to its new bucket and name, points the object row at the copy and leaves the deletion
+// of the source to a job so that the request does not wait for storage. the object keeps its id
+func (os *ObjectService) moveObject(ctx context.Context, bucketId string, objectId string, destinationBucketId string, destinationName string, op string) (*models.Object, error) {
+	reqId := utils.RequestId(ctx)
+
+	sourceBucket, object, destinationBucket, err := os.getObjectCopyTarget(ctx, bucketId, objectId, destinationBucketId, destinationName, op)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationBackend, err := os.getBucketBackend(ctx, destinationBucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceEncryption, err := os.getObjectEncryption(ctx, object, op)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationEncryption, err := os.getBucketEncryption(ctx, destinationBucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	destinationCipher, destinationDataKey, destinationMasterKeyId, err := os.getCopyDataKey(ctx, object, destinationBucket, op)
+	if err != nil {
+		return nil, err
+	}
+
+	// a pending object reserves the destination name, and the quota of the destination bucket when the object moves
+	// to another bucket, while the content is copied in storage outside of any transaction. the object row takes
+	// its place once the content has been copied
+	var placeholderId string
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if destinationBucket.Id != sourceBucket.Id {
+			if err = os.reserveBucketQuota(ctx, tx, destinationBucket, 1, object.Size, op); err != nil {
+				return err
+			}
+		}
+
+		placeholderId, err = os.queries.WithTx(tx).ObjectCreate(ctx, &database.ObjectCreateParams{
+			BucketID:     destinationBucket.Id,
+			Name:         destinationName,
+			ContentType:  &object.MimeType,
+			Size:         object.Size,
+			UploadStatus: models.ObjectUploadStatusPending,
+		})
+		if err != nil {
+			if database.IsConflictError(err) {
+				return srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("object with name '%s' already exists", destinationName), op, reqId, err)
+			}
+			os.logger.Error("failed to create object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to move object", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.copyObjectInStorage(ctx, sourceBucket, object, sourceEncryption, destinationBucket, destinationEncryption, destinationCipher, destinationName, op)
+	if err != nil {
+		os.abortObjectUpload(ctx, destinationBackend, destinationBucket.Name, placeholderId, destinationName, op)
+		return nil, err
+	}
+
+	err = os.transaction.WithTransaction(ctx, func(tx pgx.Tx) error {
+		err = os.queries.WithTx(tx).ObjectDelete(ctx, placeholderId)
+		if err != nil {
+			os.logger.Error("failed to delete object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to move object", op, reqId, err)
+		}
+
+		encryptionAlgorithm, encryptionKmsKeyId, encryptionCustomerKeyMd5 := encryptionColumns(destinationEncryption)
+
+		// the object is only moved when it has not been deleted, renamed or moved since it was copied
+		moved, err := os.queries.WithTx(tx).ObjectUpdateBucketIdAndName(ctx, &database.ObjectUpdateBucketIdAndNameParams{
+			ID:                       object.ID,
+			BucketID:                 destinationBucket.Id,
+			Name:                     destinationName,
+			Encryption:               encryptionAlgorithm,
+			EncryptionKmsKeyID:       encryptionKmsKeyId,
+			EncryptionCustomerKeyMd5: encryptionCustomerKeyMd5,
+			EncryptionDataKey:        destinationDataKey,
+			EncryptionMasterKeyID:    destinationMasterKeyId,
+			SourceBucketID:           sourceBucket.Id,
+			SourceName:               object.Name,
+		})
+		if err != nil {
+			os.logger.Error("failed to update object in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to move object", op, reqId, err)
+		}
+		if moved == 0 {
+			return srverr.NewServiceError(srverr.ConflictError, fmt.Sprintf("object '%s' was deleted or moved while it was being moved", object.ID), op, reqId, nil)
+		}
+
+		_, err = os.job.InsertTx(ctx, tx, jobs.ObjectSourceDeletion{
+			BucketId:      sourceBucket.Id,
+			BucketName:    sourceBucket.Name,
+			BucketBackend: sourceBucket.Backend,
+			ObjectName:    object.Name,
+		}, nil)
+		if err != nil {
+			os.logger.Error("failed create object source deletion job", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+			return srverr.NewServiceError(srverr.UnknownError, "failed to move object", op, reqId, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		os.abortObjectUpload(ctx, destinationBackend, destinationBucket.Name, placeholderId, destinationName, op)
+		return nil, err
+	}
+
+	return os.GetObject(ctx, destinationBucket.Id, object.ID)
 }
 
-func (os *ObjectService) SearchObjects(ctx context.Context, bucketId string, objectPath string, limit int32, offset int32) ([]*models.Object, error) {
-	const op = "ObjectService.SearchObjects"
+// getObjectCopyTarget returns the source bucket, the uploaded object and the destination bucket of a copy, rename or
+// move after checking that the object satisfies the mime type and size rules of the destination bucket
+func (os *ObjectService) getObjectCopyTarget(ctx context.Context, bucketId string, objectId string, destinationBucketId string, destinationName string, op string) (*models.Bucket, *database.StorageObject, *models.Bucket, error) {
 	reqId := utils.RequestId(ctx)
 
-	if !models.IsNotEmptyTrimmedString(bucketId) {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "bucket_id cannot be empty. bucket_id is required to search objects", op, reqId, nil)
+	sourceBucket, err := os.getBucketById(ctx, bucketId, op)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	if !models.IsNotEmptyTrimmedString(objectPath) {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object_path cannot be empty. object_path is required to search objects", op, reqId, nil)
+	object, err := os.queries.ObjectGetByBucketIdAndId(ctx, &database.ObjectGetByBucketIdAndIdParams{
+		BucketID: sourceBucket.Id,
+		ID:       objectId,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, nil, nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, nil, nil, srverr.NewServiceError(srverr.UnknownError, "failed to get object", op, reqId, err)
 	}
 
-	if limit < 0 {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "limit cannot be less than 0", op, reqId, nil)
+	if object.UploadStatus == models.ObjectUploadStatusPending {
+		return nil, nil, nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("upload has not yet been completed for object '%s'. only uploaded objects can be copied, renamed or moved", object.ID), op, reqId, nil)
 	}
 
-	if offset < 0 {
-		return nil, srverr.NewServiceError(srverr.InvalidInputError, "offset cannot be less than 0", op, reqId, nil)
+	destinationBucket := sourceBucket
+	if destinationBucketId != sourceBucket.Id {
+		destinationBucket, err = os.getBucketById(ctx, destinationBucketId, op)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
-	if limit == 0 {
-		limit = 100
+	if destinationBucket.Id == sourceBucket.Id && destinationName == object.Name {
+		return nil, nil, nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object '%s' already has the name '%s' in bucket '%s'", object.ID, destinationName, destinationBucket.Id), op, reqId, nil)
 	}
 
-	objects, err := os.queries.ObjectSearchByBucketIdAndObjectPath(ctx, &database.ObjectSearchByBucketIdAndObjectPathParams{
-		BucketID:   bucketId,
-		ObjectPath: objectPath,
-		Limit:      limit,
-		Offset:     offset,
+	_, err = resolveMimeType(destinationBucket, destinationName, &object.MimeType)
+	if err != nil {
+		return nil, nil, nil, srverr.NewServiceError(srverr.BadRequestError, err.Error(), op, reqId, err)
+	}
+
+	if destinationBucket.MaxAllowedObjectSize != nil && object.Size > *destinationBucket.MaxAllowedObjectSize {
+		return nil, nil, nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("object size is too large. max allowed object size is %d bytes", *destinationBucket.MaxAllowedObjectSize), op, reqId, nil)
+	}
+
+	return sourceBucket, object, destinationBucket, nil
+}
+
+// copyObjectInStorage copies an object to another bucket and name, the content is streamed through the server when
+// the buckets keep their objects in different storage backends. the copy is written with the encryption of the
+// destination bucket. content encrypted by the service is copied as it is between encrypted buckets, copies between
+// an encrypted bucket and one that is not are streamed through the server to decrypt or encrypt the content with the
+// destination cipher
+func (os *ObjectService) copyObjectInStorage(ctx context.Context, sourceBucket *models.Bucket, object *database.StorageObject, sourceEncryption *storage.Encryption, destinationBucket *models.Bucket, destinationEncryption *storage.Encryption, destinationCipher *envelope.Cipher, destinationName string, op string) error {
+	reqId := utils.RequestId(ctx)
+
+	sourceBackend, err := os.getBucketBackend(ctx, sourceBucket, op)
+	if err != nil {
+		return err
+	}
+
+	destinationBackend, err := os.getBucketBackend(ctx, destinationBucket, op)
+	if err != nil {
+		return err
+	}
+
+	sourceCipher, err := os.getObjectCipher(ctx, object, op)
+	if err != nil {
+		return err
+	}
+
+	if (sourceCipher != nil) == destinationBucket.Encrypted {
+		err = storage.CopyObjectBetween(ctx, sourceBackend, destinationBackend, &storage.ObjectCopy{
+			SourceBucket:      sourceBucket.Name,
+			SourceName:        object.Name,
+			DestinationBucket: destinationBucket.Name,
+			DestinationName:   destinationName,
+			SourceEncryption:  sourceEncryption,
+			Encryption:        destinationEncryption,
+		})
+	} else {
+		err = reencryptObjectBetween(ctx, sourceBackend, destinationBackend, &storage.ObjectCopy{
+			SourceBucket:      sourceBucket.Name,
+			SourceName:        object.Name,
+			DestinationBucket: destinationBucket.Name,
+			DestinationName:   destinationName,
+			ContentType:       &object.MimeType,
+			SourceEncryption:  sourceEncryption,
+			Encryption:        destinationEncryption,
+		}, object.Size, sourceCipher, destinationCipher)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found in storage", object.ID), op, reqId, err)
+		}
+		os.logger.Error("failed to copy object in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to copy object", op, reqId, err)
+	}
+
+	return nil
+}
+
+// reencryptObjectBetween copies an object between an encrypted bucket and one that is not by streaming its content
+// through the server. the content of the source is decrypted with the source cipher and the copy is encrypted with the
+// destination cipher, either of which is nil on the side that is not encrypted
+func reencryptObjectBetween(ctx context.Context, source storage.Backend, destination storage.Backend, objectCopy *storage.ObjectCopy, size int64, sourceCipher *envelope.Cipher, destinationCipher *envelope.Cipher) error {
+	objectContent, err := source.GetObject(ctx, &storage.ObjectGet{
+		Bucket:     objectCopy.SourceBucket,
+		Name:       objectCopy.SourceName,
+		Encryption: objectCopy.SourceEncryption,
 	})
 	if err != nil {
-		os.logger.Error("failed to search objects", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
-		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to search objects", op, reqId, err)
+		return err
 	}
-	if len(objects) == 0 {
-		return nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("no objects found for bucket '%s' with path '%s'", bucketId, objectPath), op, reqId, nil)
+	defer objectContent.Content.Close()
+
+	var content io.Reader = objectContent.Content
+	if sourceCipher != nil {
+		content = sourceCipher.DecryptReader(content, size, 0, size-1)
+	}
+	if destinationCipher != nil {
+		content = destinationCipher.EncryptReader(content)
 	}
 
-	var result []*models.Object
+	return destination.UploadObject(ctx, &storage.ObjectUpload{
+		Bucket:      objectCopy.DestinationBucket,
+		Name:        objectCopy.DestinationName,
+		ContentType: lo.FromPtrOr(objectCopy.ContentType, objectContent.ContentType),
+		Content:     content,
+		Encryption:  objectCopy.Encryption,
+	})
+}
+
+func (os *ObjectService) ListObjects(ctx context.Context, objectListInput *models.ObjectListInput, paginationInput *models.PaginationInput) (*models.ObjectListResult, error) {
+	const op = "ObjectService.ListObjects"
+	reqId := utils.RequestId(ctx)
 
-	for _, object := range objects {
-		result = append(result, &models.Object{
-			Id:             object.ID,
-			Version:        object.Version,
-			BucketId:       object.BucketID,
-			Name:           object.Name,
-			MimeType:       object.MimeType,
-			Size:           object.Size,
-			Metadata:       bytesToMetadata(object.Metadata),
-			UploadStatus:   object.UploadStatus,
-			LastAccessedAt: object.LastAccessedAt,
-			CreatedAt:      object.CreatedAt,
-			UpdatedAt:      object.UpdatedAt,
+	if err := objectListInput.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	paginationInput.SetDefaults()
+
+	if err := paginationInput.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	cursor, previous, _ := paginationInput.DecodeCursor()
+	if previous {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, "object listing can only be paged forward with next_cursor", op, reqId, nil)
+	}
+
+	bucket, err := os.getBucketById(ctx, objectListInput.BucketId, op)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixEnd, startAfter := prefixListingBounds(objectListInput.Prefix, objectListInput.Delimiter, cursor)
+
+	tags, _ := models.ParseObjectTagFilters(objectListInput.Tags)
+	tagKeys, tagValues := splitObjectTags(tags)
+
+	entries, err := os.queries.ObjectListByPrefix(ctx, &database.ObjectListByPrefixParams{
+		BucketID:   bucket.Id,
+		Prefix:     objectListInput.Prefix,
+		Delimiter:  objectListInput.Delimiter,
+		PrefixEnd:  prefixEnd,
+		StartAfter: startAfter,
+		TagKeys:    tagKeys,
+		TagValues:  tagValues,
+		Limit:      paginationInput.Limit + 1,
+	})
+	if err != nil {
+		os.logger.Error("failed to list objects by prefix", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, "failed to list objects", op, reqId, err)
+	}
+
+	result := &models.ObjectListResult{
+		Prefix:         objectListInput.Prefix,
+		Delimiter:      objectListInput.Delimiter,
+		Objects:        []*models.Object{},
+		CommonPrefixes: []string{},
+	}
+
+	if len(entries) > int(paginationInput.Limit) {
+		entries = entries[:paginationInput.Limit]
+		result.HasNext = true
+		result.NextCursor = models.EncodeCursor(entries[len(entries)-1].Key, false)
+	}
+
+	for _, entry := range entries {
+		if entry.IsCommonPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, entry.Key)
+			continue
+		}
+
+		result.Objects = append(result.Objects, &models.Object{
+			Id:                       entry.ID,
+			Version:                  entry.Version,
+			BucketId:                 entry.BucketID,
+			Name:                     entry.Name,
+			MimeType:                 entry.MimeType,
+			Size:                     entry.Size,
+			Metadata:                 bytesToMetadata(entry.Metadata),
+			ChecksumAlgorithm:        entry.ChecksumAlgorithm,
+			Checksum:                 entry.Checksum,
+			DetectedMimeType:         entry.DetectedMimeType,
+			UploadStatus:             entry.UploadStatus,
+			LastAccessedAt:           entry.LastAccessedAt,
+			CreatedAt:                entry.CreatedAt,
+			UpdatedAt:                entry.UpdatedAt,
+			Encryption:               entry.Encryption,
+			EncryptionKmsKeyId:       entry.EncryptionKmsKeyID,
+			EncryptionCustomerKeyMd5: entry.EncryptionCustomerKeyMd5,
+			Encrypted:                entry.EncryptionDataKey != nil,
 		})
 	}
 
+	if err = os.attachObjectTags(ctx, result.Objects, op); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
+// getMultipartUploadSession returns a pending object of the bucket together with its multipart upload session
+// as long as the session has not expired
+func (os *ObjectService) getMultipartUploadSession(ctx context.Context, bucket *models.Bucket, objectId string, op string) (*database.StorageObject, *database.StorageMultipartUploadSession, error) {
+	reqId := utils.RequestId(ctx)
+
+	object, err := os.queries.ObjectGetByBucketIdAndId(ctx, &database.ObjectGetByBucketIdAndIdParams{
+		BucketID: bucket.Id,
+		ID:       objectId,
+	})
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("object '%s' not found", objectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, nil, srverr.NewServiceError(srverr.UnknownError, "failed to get multipart upload session", op, reqId, err)
+	}
+
+	if object.UploadStatus == models.ObjectUploadStatusCompleted {
+		return nil, nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("upload session has already been completed for object '%s'", objectId), op, reqId, nil)
+	}
+
+	multipartUploadSession, err := os.queries.MultipartUploadSessionGetByObjectId(ctx, object.ID)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, nil, srverr.NewServiceError(srverr.NotFoundError, fmt.Sprintf("multipart upload session for object '%s' not found", objectId), op, reqId, err)
+		}
+		os.logger.Error("failed to get multipart upload session from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, nil, srverr.NewServiceError(srverr.UnknownError, "failed to get multipart upload session", op, reqId, err)
+	}
+
+	if time.Now().After(multipartUploadSession.ExpiresAt) {
+		return nil, nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("multipart upload session for object '%s' has expired", objectId), op, reqId, nil)
+	}
+
+	return object, multipartUploadSession, nil
+}
+
+// abortMultipartUpload aborts a multipart upload in storage that was created by a request that failed afterwards
+func (os *ObjectService) abortMultipartUpload(ctx context.Context, backend storage.Backend, bucketName string, objectName string, uploadId string, op string) {
+	err := backend.AbortMultipartUpload(context.WithoutCancel(ctx), &storage.MultipartUploadAbort{
+		Bucket:   bucketName,
+		Name:     objectName,
+		UploadId: uploadId,
+	})
+	if err != nil {
+		os.logger.Error("failed to abort multipart upload in storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(utils.RequestId(ctx)))
+	}
+}
+
+// deleteUploadedObject removes an object that was written to storage by a request that failed afterwards
+func (os *ObjectService) deleteUploadedObject(ctx context.Context, backend storage.Backend, bucketName string, objectName string, op string) {
+	err := backend.DeleteObject(context.WithoutCancel(ctx), &storage.ObjectDelete{
+		Bucket: bucketName,
+		Name:   objectName,
+	})
+	if err != nil {
+		os.logger.Error("failed to delete uploaded object from storage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(utils.RequestId(ctx)))
+	}
+}
+
+// abortObjectUpload deletes the content and the row of an object whose upload or copy failed after its row had been
+// committed to reserve its name and quota. the content is deleted first, as the row keeps other objects from being
+// written under the same name
+func (os *ObjectService) abortObjectUpload(ctx context.Context, backend storage.Backend, bucketName string, objectId string, objectName string, op string) {
+	os.deleteUploadedObject(ctx, backend, bucketName, objectName, op)
+
+	err := os.queries.ObjectDelete(context.WithoutCancel(ctx), objectId)
+	if err != nil {
+		os.logger.Error("failed to delete object from database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(utils.RequestId(ctx)))
+	}
+}
+
+// returnObjectToTrash moves an object whose restore failed back to the trash with the time it was deleted at. the
+// content copied under its name is deleted first, as the row keeps other objects from being written under the name
+func (os *ObjectService) returnObjectToTrash(ctx context.Context, backend storage.Backend, bucketName string, object *database.StorageObject, op string) {
+	os.deleteUploadedObject(ctx, backend, bucketName, object.Name, op)
+
+	err := os.queries.ObjectReturnToTrash(context.WithoutCancel(ctx), &database.ObjectReturnToTrashParams{
+		DeletedAt: object.DeletedAt,
+		ID:        object.ID,
+	})
+	if err != nil {
+		os.logger.Error("failed to return object to trash in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(utils.RequestId(ctx)))
+	}
+}
+
+// reserveBucketQuota checks that adding objectCount objects with a total of size bytes keeps the bucket within its quotas.
+// pending uploads count towards the quotas with their declared size. the check takes a transaction scoped lock of the
+// bucket quota, which is held until the object row that reserves the quota is committed in the same transaction, so that
+// concurrent uploads cannot overshoot the quotas together
+func (os *ObjectService) reserveBucketQuota(ctx context.Context, tx pgx.Tx, bucket *models.Bucket, objectCount int64, size int64, op string) error {
+	reqId := utils.RequestId(ctx)
+
+	if bucket.MaxTotalSize == nil && bucket.MaxObjectCount == nil {
+		return nil
+	}
+
+	err := os.queries.WithTx(tx).BucketLockQuotaById(ctx, bucket.Id)
+	if err != nil {
+		os.logger.Error("failed to lock bucket quota", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to reserve bucket quota", op, reqId, err)
+	}
+
+	bucketUsage, err := os.queries.WithTx(tx).BucketGetUsageById(ctx, bucket.Id)
+	if err != nil {
+		os.logger.Error("failed to get bucket usage", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to reserve bucket quota", op, reqId, err)
+	}
+
+	if bucket.MaxObjectCount != nil && bucketUsage.ObjectCount+objectCount > *bucket.MaxObjectCount {
+		return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("bucket object count quota exceeded. bucket '%s' allows at most %d objects and has %d objects", bucket.Id, *bucket.MaxObjectCount, bucketUsage.ObjectCount), op, reqId, nil)
+	}
+
+	if bucket.MaxTotalSize != nil && bucketUsage.Size+size > *bucket.MaxTotalSize {
+		return srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("bucket storage quota exceeded. bucket '%s' allows at most %d bytes in total and has %d bytes available", bucket.Id, *bucket.MaxTotalSize, max(*bucket.MaxTotalSize-bucketUsage.Size, 0)), op, reqId, nil)
+	}
+
+	return nil
+}
+
+// setObjectTags adds tags to an object that has no tags yet
+func (os *ObjectService) setObjectTags(ctx context.Context, queries *database.Queries, objectId string, tags map[string]string, op string) error {
+	reqId := utils.RequestId(ctx)
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	keys, values := splitObjectTags(tags)
+
+	err := queries.ObjectTagCreateMany(ctx, &database.ObjectTagCreateManyParams{
+		ObjectID: objectId,
+		Keys:     keys,
+		Values:   values,
+	})
+	if err != nil {
+		os.logger.Error("failed to create object tags in database", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to set object tags", op, reqId, err)
+	}
+
+	return nil
+}
+
+// attachObjectTags loads the tags of the objects with a single query, objects without tags get an empty tag set
+func (os *ObjectService) attachObjectTags(ctx context.Context, objects []*models.Object, op string) error {
+	reqId := utils.RequestId(ctx)
+
+	if len(objects) == 0 {
+		return nil
+	}
+
+	objectsById := make(map[string]*models.Object, len(objects))
+	for _, object := range objects {
+		object.Tags = map[string]string{}
+		objectsById[object.Id] = object
+	}
+
+	tags, err := os.queries.ObjectTagListByObjectIds(ctx, lo.Keys(objectsById))
+	if err != nil {
+		os.logger.Error("failed to list object tags", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, "failed to get object tags", op, reqId, err)
+	}
+
+	for _, tag := range tags {
+		objectsById[tag.ObjectID].Tags[tag.Key] = tag.Value
+	}
+
+	return nil
+}
+
 func (os *ObjectService) getBucketById(ctx context.Context, bucketId string, op string) (*models.Bucket, error) {
 	reqId := utils.RequestId(ctx)
 
@@ -445,6 +2611,13 @@ func (os *ObjectService) getBucketById(ctx context.Context, bucketId string, op
 		Name:                 bucket.Name,
 		AllowedMimeTypes:     bucket.AllowedMimeTypes,
 		MaxAllowedObjectSize: bucket.MaxAllowedObjectSize,
+		MaxTotalSize:         bucket.MaxTotalSize,
+		MaxObjectCount:       bucket.MaxObjectCount,
+		TrashRetentionDays:   bucket.TrashRetentionDays,
+		Backend:              bucket.Backend,
+		Encryption:           bucket.Encryption,
+		EncryptionKmsKeyId:   bucket.EncryptionKmsKeyID,
+		Encrypted:            bucket.Encrypted,
 		Public:               bucket.Public,
 		Disabled:             bucket.Disabled,
 		Locked:               bucket.Locked,
@@ -454,3 +2627,162 @@ func (os *ObjectService) getBucketById(ctx context.Context, bucketId string, op
 		UpdatedAt:            bucket.UpdatedAt,
 	}, nil
 }
+
+// getBucketBackend returns the storage backend the objects of a bucket are kept in
+func (os *ObjectService) getBucketBackend(ctx context.Context, bucket *models.Bucket, op string) (storage.Backend, error) {
+	reqId := utils.RequestId(ctx)
+
+	backend, err := os.storage.Backend(bucket.Backend)
+	if err != nil {
+		os.logger.Error("failed to get storage backend of bucket", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, fmt.Sprintf("storage backend '%s' of bucket '%s' is not configured", bucket.Backend, bucket.Id), op, reqId, err)
+	}
+
+	return backend, nil
+}
+
+// getBucketEncryption returns the encryption new objects of a bucket are written with. objects of buckets with sse-c
+// encryption can only be written with the customer key sent with the request
+func (os *ObjectService) getBucketEncryption(ctx context.Context, bucket *models.Bucket, op string) (*storage.Encryption, error) {
+	if bucket.Encryption == nil {
+		return nil, nil
+	}
+
+	encryption := &storage.Encryption{
+		Algorithm: *bucket.Encryption,
+		KmsKeyId:  bucket.EncryptionKmsKeyId,
+	}
+
+	if encryption.Algorithm == models.BucketEncryptionSSEC {
+		customerKey, err := getEncryptionCustomerKey(ctx, fmt.Sprintf("bucket '%s'", bucket.Id), op)
+		if err != nil {
+			return nil, err
+		}
+		encryption.CustomerKey = customerKey
+	}
+
+	return encryption, nil
+}
+
+// getObjectEncryption returns the encryption an object was written with. objects encrypted with sse-c can only be
+// read with the customer key they were written with, which is checked against the md5 digest recorded on the object
+func (os *ObjectService) getObjectEncryption(ctx context.Context, object *database.StorageObject, op string) (*storage.Encryption, error) {
+	reqId := utils.RequestId(ctx)
+
+	if object.Encryption == nil {
+		return nil, nil
+	}
+
+	encryption := &storage.Encryption{
+		Algorithm: *object.Encryption,
+		KmsKeyId:  object.EncryptionKmsKeyID,
+	}
+
+	if encryption.Algorithm == models.BucketEncryptionSSEC {
+		customerKey, err := getEncryptionCustomerKey(ctx, fmt.Sprintf("object '%s'", object.ID), op)
+		if err != nil {
+			return nil, err
+		}
+
+		customerKeyMd5, _ := storage.CustomerKeyMD5(*customerKey)
+		if customerKeyMd5 != lo.FromPtr(object.EncryptionCustomerKeyMd5) {
+			return nil, srverr.NewServiceError(srverr.ForbiddenError, fmt.Sprintf("customer key does not match the key object '%s' was encrypted with", object.ID), op, reqId, nil)
+		}
+		encryption.CustomerKey = customerKey
+	}
+
+	return encryption, nil
+}
+
+// getEncryptionCustomerKey returns the sse-c customer key sent with the request after checking that it is a base64
+// encoded 256-bit key
+func getEncryptionCustomerKey(ctx context.Context, target string, op string) (*string, error) {
+	reqId := utils.RequestId(ctx)
+
+	customerKey := utils.EncryptionCustomerKey(ctx)
+	if customerKey == nil {
+		return nil, srverr.NewServiceError(srverr.BadRequestError, fmt.Sprintf("%s is encrypted with sse-c. the customer key is required in the 'X-Encryption-Customer-Key' header", target), op, reqId, nil)
+	}
+
+	if _, err := storage.CustomerKeyMD5(*customerKey); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	return customerKey, nil
+}
+
+// encryptionColumns returns the encryption, kms key id and customer key md5 recorded on the row of an object written
+// with the encryption. the customer key has already been validated by getEncryptionCustomerKey
+func encryptionColumns(encryption *storage.Encryption) (*string, *string, *string) {
+	if encryption == nil {
+		return nil, nil, nil
+	}
+
+	var customerKeyMd5 *string
+	if encryption.CustomerKey != nil {
+		md5, _ := storage.CustomerKeyMD5(*encryption.CustomerKey)
+		customerKeyMd5 = &md5
+	}
+
+	return &encryption.Algorithm, encryption.KmsKeyId, customerKeyMd5
+}
+
+// newObjectDataKey generates the data key of a new object of an encrypted bucket and returns its cipher together with
+// the wrapped data key and the id of the master key it is wrapped with, which are recorded on the object
+func (os *ObjectService) newObjectDataKey(ctx context.Context, bucket *models.Bucket, op string) (*envelope.Cipher, *string, *string, error) {
+	reqId := utils.RequestId(ctx)
+
+	if !bucket.Encrypted {
+		return nil, nil, nil, nil
+	}
+
+	dataKey, wrappedDataKey, masterKeyId, err := os.keyring.NewDataKey()
+	if err != nil {
+		os.logger.Error("failed to generate object data key", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, nil, nil, srverr.NewServiceError(srverr.UnknownError, fmt.Sprintf("failed to encrypt object of bucket '%s'", bucket.Id), op, reqId, err)
+	}
+
+	dataKeyCipher, err := envelope.NewEncryptingCipher(dataKey)
+	if err != nil {
+		os.logger.Error("failed to create object data key cipher", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, nil, nil, srverr.NewServiceError(srverr.UnknownError, fmt.Sprintf("failed to encrypt object of bucket '%s'", bucket.Id), op, reqId, err)
+	}
+
+	return dataKeyCipher, &wrappedDataKey, &masterKeyId, nil
+}
+
+// getObjectCipher returns the cipher of the data key of an object encrypted by the service, or nil when the object
+// is not encrypted
+func (os *ObjectService) getObjectCipher(ctx context.Context, object *database.StorageObject, op string) (*envelope.Cipher, error) {
+	reqId := utils.RequestId(ctx)
+
+	if object.EncryptionDataKey == nil {
+		return nil, nil
+	}
+
+	dataKey, err := os.keyring.UnwrapDataKey(*object.EncryptionDataKey, lo.FromPtr(object.EncryptionMasterKeyID))
+	if err != nil {
+		os.logger.Error("failed to unwrap object data key", zap.Error(err), zap.String("master_key_id", lo.FromPtr(object.EncryptionMasterKeyID)), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, fmt.Sprintf("failed to decrypt object '%s'", object.ID), op, reqId, err)
+	}
+
+	dataKeyCipher, err := envelope.NewCipher(dataKey)
+	if err != nil {
+		os.logger.Error("failed to create object data key cipher", zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return nil, srverr.NewServiceError(srverr.UnknownError, fmt.Sprintf("failed to decrypt object '%s'", object.ID), op, reqId, err)
+	}
+
+	return dataKeyCipher, nil
+}
+
+// getCopyDataKey returns the wrapped data key and master key id of the copy of an object in the destination bucket.
+// copies between encrypted buckets share the data key of the object since the encrypted content is copied as it is,
+// no cipher is returned for them so that the shared data key never encrypts other content. copies into an encrypted
+// bucket from one that is not get a new data key, whose cipher the content is encrypted with
+func (os *ObjectService) getCopyDataKey(ctx context.Context, object *database.StorageObject, destinationBucket *models.Bucket, op string) (*envelope.Cipher, *string, *string, error) {
+	if destinationBucket.Encrypted && object.EncryptionDataKey != nil {
+		return nil, object.EncryptionDataKey, object.EncryptionMasterKeyID, nil
+	}
+
+	return os.newObjectDataKey(ctx, destinationBucket, op)
+}
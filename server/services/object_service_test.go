@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/envelope"
+	"github.com/driftdev/storage/server/models"
+	"github.com/driftdev/storage/server/srverr"
+	"github.com/driftdev/storage/server/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDB records the statements executed by the service and answers single row queries with the queued rows in order.
+// a row is a struct of the sqlc model the query scans into, whose fields are scanned in order
+type fakeDB struct {
+	execs [][]any
+	rows  []any
+}
+
+func (db *fakeDB) Exec(_ context.Context, _ string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.execs = append(db.execs, args)
+	return pgconn.NewCommandTag("DELETE 1"), nil
+}
+
+func (db *fakeDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, pgx.ErrNoRows
+}
+
+func (db *fakeDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	if len(db.rows) == 0 {
+		return &fakeRow{}
+	}
+	row := db.rows[0]
+	db.rows = db.rows[1:]
+	return &fakeRow{value: row}
+}
+
+type fakeRow struct {
+	value any
+}
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if r.value == nil {
+		return pgx.ErrNoRows
+	}
+	value := reflect.ValueOf(r.value)
+	for i := range dest {
+		reflect.ValueOf(dest[i]).Elem().Set(value.Field(i))
+	}
+	return nil
+}
+
+func newTestObjectService(t *testing.T, db *fakeDB) (*ObjectService, *storage.MemoryBackend, *storage.MemoryBackend) {
+	keyring, err := envelope.NewKeyring(&config.Config{
+		EncryptionMasterKey:   base64.StdEncoding.EncodeToString([]byte(strings.Repeat("m", 32))),
+		EncryptionMasterKeyId: "default",
+	})
+	require.NoError(t, err)
+
+	defaultBackend := storage.NewMemoryBackend(&config.Config{})
+	euBackend := storage.NewMemoryBackend(&config.Config{})
+
+	return &ObjectService{
+		queries: database.New(db),
+		storage: storage.NewRegistryOf(map[string]storage.Backend{config.DefaultStorageBackend: defaultBackend, "eu": euBackend}),
+		keyring: keyring,
+		logger:  zap.NewNop(),
+	}, defaultBackend, euBackend
+}
+
+func readMemoryObject(t *testing.T, backend *storage.MemoryBackend, bucket string, name string) []byte {
+	objectContent, err := backend.GetObject(context.Background(), &storage.ObjectGet{Bucket: bucket, Name: name})
+	require.NoError(t, err)
+	defer objectContent.Content.Close()
+
+	content, err := io.ReadAll(objectContent.Content)
+	require.NoError(t, err)
+	return content
+}
+
+func TestObjectService_CopyObjectInStorage(t *testing.T) {
+	ctx := context.Background()
+	os, defaultBackend, euBackend := newTestObjectService(t, &fakeDB{})
+
+	sourceBucket := &models.Bucket{Id: "bucket_01HPG4GN5JY2Z6S0638ERSG375", Name: "avatars", Backend: config.DefaultStorageBackend}
+	encryptedBucket := &models.Bucket{Id: "bucket_01HPG4GN5JY2Z6S0638ERSG376", Name: "vault", Backend: "eu", Encrypted: true}
+
+	err := defaultBackend.UploadObject(ctx, &storage.ObjectUpload{Bucket: "avatars", Name: "avatar.txt", ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	require.NoError(t, err)
+
+	object := &database.StorageObject{ID: "object_01HPG4GN5JY2Z6S0638ERSG375", Name: "avatar.txt", MimeType: "text/plain", Size: 11}
+
+	destinationCipher, dataKey, masterKeyId, err := os.getCopyDataKey(ctx, object, encryptedBucket, "test")
+	require.NoError(t, err)
+	require.NotNil(t, destinationCipher)
+
+	err = os.copyObjectInStorage(ctx, sourceBucket, object, nil, encryptedBucket, nil, destinationCipher, "avatar.txt", "test")
+	require.NoError(t, err)
+
+	encrypted := readMemoryObject(t, euBackend, "vault", "avatar.txt")
+	assert.Equal(t, envelope.EncryptedSize(11), int64(len(encrypted)))
+	assert.NotContains(t, string(encrypted), "hello world")
+
+	// the encrypted copy is decrypted again when it is copied back to a bucket that is not encrypted
+	encryptedObject := &database.StorageObject{ID: "object_01HPG4GN5JY2Z6S0638ERSG376", Name: "avatar.txt", MimeType: "text/plain", Size: 11, EncryptionDataKey: dataKey, EncryptionMasterKeyID: masterKeyId}
+
+	err = os.copyObjectInStorage(ctx, encryptedBucket, encryptedObject, nil, sourceBucket, nil, nil, "restored.txt", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(readMemoryObject(t, defaultBackend, "avatars", "restored.txt")))
+}
+
+func TestObjectService_AbortObjectUpload(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{}
+	os, defaultBackend, _ := newTestObjectService(t, db)
+
+	err := defaultBackend.UploadObject(ctx, &storage.ObjectUpload{Bucket: "avatars", Name: "avatar.txt", ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	require.NoError(t, err)
+
+	os.abortObjectUpload(ctx, defaultBackend, "avatars", "object_01HPG4GN5JY2Z6S0638ERSG375", "avatar.txt", "test")
+	assert.Empty(t, defaultBackend.ObjectNames("avatars"))
+	assert.Equal(t, [][]any{{"object_01HPG4GN5JY2Z6S0638ERSG375"}}, db.execs)
+}
+
+func TestObjectService_UploadObject_RejectsDetectedMimeType(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{rows: []any{
+		database.StorageBucket{ID: "bucket_01HPG4GN5JY2Z6S0638ERSG375", Name: "avatars", AllowedMimeTypes: []string{"image/png"}, Backend: config.DefaultStorageBackend},
+	}}
+	os, defaultBackend, _ := newTestObjectService(t, db)
+
+	content := "<html><body>not an image</body></html>"
+	_, err := os.UploadObject(ctx, &models.ObjectUploadCreate{BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375", Name: "avatar.png", MimeType: lo.ToPtr("image/png"), Size: int64(len(content))}, strings.NewReader(content))
+
+	var serviceError srverr.ServiceError
+	require.ErrorAs(t, err, &serviceError)
+	assert.Equal(t, srverr.BadRequestError, serviceError.ErrorCode)
+	assert.Contains(t, serviceError.Message, "'text/html'")
+	assert.Empty(t, defaultBackend.ObjectNames("avatars"), "rejected content is never written to storage")
+	assert.Empty(t, db.execs, "no pending object is created for rejected content")
+}
+
+func TestObjectService_CompleteMultipartUploadSession_RejectsDetectedMimeType(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{}
+	os, defaultBackend, _ := newTestObjectService(t, db)
+
+	uploadId, err := defaultBackend.CreateMultipartUpload(ctx, &storage.MultipartUploadCreate{Bucket: "videos", Name: "video.mp4", ContentType: "video/mp4"})
+	require.NoError(t, err)
+
+	preSignedObject, err := defaultBackend.CreatePreSignedUploadPart(ctx, &storage.PreSignedUploadPartCreate{Bucket: "videos", Name: "video.mp4", UploadId: uploadId, PartNumber: 1})
+	require.NoError(t, err)
+
+	etag, err := defaultBackend.UploadPreSigned(ctx, preSignedObject.Url, "", strings.NewReader("<html><body>not a video</body></html>"))
+	require.NoError(t, err)
+
+	db.rows = []any{
+		database.StorageBucket{ID: "bucket_01HPG4GN5JY2Z6S0638ERSG375", Name: "videos", AllowedMimeTypes: []string{"video/mp4"}, Backend: config.DefaultStorageBackend},
+		database.StorageObject{ID: "object_01HPG4GN5JY2Z6S0638ERSG375", BucketID: "bucket_01HPG4GN5JY2Z6S0638ERSG375", Name: "video.mp4", MimeType: "video/mp4", UploadStatus: models.ObjectUploadStatusPending},
+		database.StorageMultipartUploadSession{ObjectID: "object_01HPG4GN5JY2Z6S0638ERSG375", UploadID: uploadId, ExpiresAt: time.Now().Add(time.Hour)},
+	}
+
+	_, err = os.CompleteMultipartUploadSession(ctx, &models.MultipartUploadSessionComplete{
+		BucketId: "bucket_01HPG4GN5JY2Z6S0638ERSG375",
+		ObjectId: "object_01HPG4GN5JY2Z6S0638ERSG375",
+		Parts:    []*models.MultipartUploadCompletedPart{{PartNumber: 1, ETag: etag}},
+	})
+
+	var serviceError srverr.ServiceError
+	require.ErrorAs(t, err, &serviceError)
+	assert.Equal(t, srverr.BadRequestError, serviceError.ErrorCode)
+	assert.Contains(t, serviceError.Message, "'text/html'")
+	assert.Empty(t, defaultBackend.ObjectNames("videos"), "rejected content is deleted from storage")
+	assert.Equal(t, [][]any{{"object_01HPG4GN5JY2Z6S0638ERSG375"}}, db.execs, "the object of rejected content is deleted")
+}
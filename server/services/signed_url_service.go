@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/driftdev/storage/server/database"
+	"github.com/driftdev/storage/server/models"
+	"github.com/driftdev/storage/server/srverr"
+	"github.com/driftdev/storage/server/storage"
+	"github.com/driftdev/storage/server/utils"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// SignedUrlService serves the pre-signed urls of the filesystem storage backends, which unlike s3 can not serve
+// them themselves. the backend of a url is the backend of the bucket in its path
+type SignedUrlService struct {
+	queries *database.Queries
+	storage *storage.Registry
+	logger  *zap.Logger
+}
+
+func NewSignedUrlService(db *pgxpool.Pool, storage *storage.Registry, logger *zap.Logger) *SignedUrlService {
+	return &SignedUrlService{
+		queries: database.New(db),
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// UploadObject writes the content sent to a pre-signed upload object or upload part url and returns its etag
+func (ss *SignedUrlService) UploadObject(ctx context.Context, signedObjectUpload *models.SignedObjectUpload) (string, error) {
+	const op = "SignedUrlService.UploadObject"
+	reqId := utils.RequestId(ctx)
+
+	if err := signedObjectUpload.IsValid(); err != nil {
+		return "", srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	filesystem, err := ss.getFilesystemBackend(ctx, signedObjectUpload.BucketName)
+	if err != nil {
+		return "", signedUrlServiceError(err, "failed to upload object", op, reqId, ss.logger)
+	}
+
+	etag, err := filesystem.UploadSignedObject(ctx, toSignedRequest(http.MethodPut, &signedObjectUpload.SignedObjectRequest), &storage.SignedUpload{
+		ContentType: signedObjectUpload.MimeType,
+		Content:     signedObjectUpload.Content,
+	})
+	if err != nil {
+		return "", signedUrlServiceError(err, "failed to upload object", op, reqId, ss.logger)
+	}
+
+	return etag, nil
+}
+
+// DownloadObject gets the content of the object of a pre-signed download url
+func (ss *SignedUrlService) DownloadObject(ctx context.Context, signedObjectDownload *models.SignedObjectDownload) (*models.ObjectContent, error) {
+	const op = "SignedUrlService.DownloadObject"
+	reqId := utils.RequestId(ctx)
+
+	if err := signedObjectDownload.IsValid(); err != nil {
+		return nil, srverr.NewServiceError(srverr.InvalidInputError, err.Error(), op, reqId, err)
+	}
+
+	filesystem, err := ss.getFilesystemBackend(ctx, signedObjectDownload.BucketName)
+	if err != nil {
+		return nil, signedUrlServiceError(err, "failed to download object", op, reqId, ss.logger)
+	}
+
+	storageContent, err := filesystem.DownloadSignedObject(ctx, toSignedRequest(http.MethodGet, &signedObjectDownload.SignedObjectRequest), signedObjectDownload.Range)
+	if err != nil {
+		return nil, signedUrlServiceError(err, "failed to download object", op, reqId, ss.logger)
+	}
+
+	objectContent := &models.ObjectContent{
+		MimeType:      storageContent.ContentType,
+		ContentLength: storageContent.ContentLength,
+		ContentRange:  storageContent.ContentRange,
+		ETag:          storageContent.ETag,
+		LastModified:  storageContent.LastModified,
+		Content:       storageContent.Content,
+	}
+
+	if storageContent.ContentRange == nil {
+		objectContent.Size = storageContent.ContentLength
+	}
+
+	return objectContent, nil
+}
+
+// getFilesystemBackend returns the filesystem backend of a bucket. urls of missing buckets and of buckets that are
+// not kept in a filesystem backend are invalid, the same as a url with a signature that does not match
+func (ss *SignedUrlService) getFilesystemBackend(ctx context.Context, bucketName string) (*storage.FilesystemBackend, error) {
+	bucket, err := ss.queries.BucketGetByName(ctx, bucketName)
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, storage.ErrSignedUrlInvalid
+		}
+		return nil, err
+	}
+
+	backend, err := ss.storage.Backend(bucket.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	filesystem, ok := backend.(*storage.FilesystemBackend)
+	if !ok {
+		return nil, storage.ErrSignedUrlInvalid
+	}
+
+	return filesystem, nil
+}
+
+// signedUrlServiceError maps the errors of the filesystem backend to service errors. a signature that does not match
+// is forbidden like it is on s3
+func signedUrlServiceError(err error, message string, op string, reqId string, logger *zap.Logger) error {
+	switch {
+	case errors.Is(err, storage.ErrSignedUrlInvalid), errors.Is(err, storage.ErrSignedUrlExpired):
+		return srverr.NewServiceError(srverr.ForbiddenError, err.Error(), op, reqId, err)
+	case errors.Is(err, storage.ErrContentTypeMismatch), errors.Is(err, storage.ErrContentLengthMismatch), errors.Is(err, storage.ErrChecksumMismatch), errors.Is(err, storage.ErrInvalidObjectName):
+		return srverr.NewServiceError(srverr.BadRequestError, err.Error(), op, reqId, err)
+	case errors.Is(err, storage.ErrRangeNotSatisfiable):
+		return srverr.NewServiceError(srverr.RangeNotSatisfiableError, err.Error(), op, reqId, err)
+	case errors.Is(err, storage.ErrObjectNotFound):
+		return srverr.NewServiceError(srverr.NotFoundError, "object not found", op, reqId, err)
+	default:
+		logger.Error(message, zap.Error(err), zapfield.Operation(op), zapfield.RequestId(reqId))
+		return srverr.NewServiceError(srverr.UnknownError, message, op, reqId, err)
+	}
+}
@@ -12,6 +12,7 @@ var ConflictError ErrorCode = errors.New("conflict error")
 var InvalidInputError ErrorCode = errors.New("invalid input error")
 var BadRequestError ErrorCode = errors.New("bad request error")
 var ForbiddenError ErrorCode = errors.New("forbidden error")
+var RangeNotSatisfiableError ErrorCode = errors.New("range not satisfiable error")
 var UnknownError ErrorCode = errors.New("unknown error")
 
 type ServiceError struct {
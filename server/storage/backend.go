@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/driftdev/storage/server/config"
+	"go.uber.org/zap"
+)
+
+// contentTypeDetectionSize is the number of bytes http.DetectContentType considers
+const contentTypeDetectionSize = 512
+
+// trashBucketPrefix is prepended to the name of a bucket to build the bucket its trashed objects are kept in
+const trashBucketPrefix = ".trash/"
+
+var (
+	ErrObjectNotFound        = errors.New("object not found in storage")
+	ErrSignedUrlInvalid      = errors.New("signed url is invalid")
+	ErrSignedUrlExpired      = errors.New("signed url has expired")
+	ErrContentTypeMismatch   = errors.New("content type does not match the signed url")
+	ErrContentLengthMismatch = errors.New("content length does not match the signed url")
+	ErrChecksumMismatch      = errors.New("checksum does not match the signed url")
+	ErrRangeNotSatisfiable   = errors.New("range not satisfiable")
+	ErrInvalidPart           = errors.New("part has not been uploaded or its etag does not match")
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Backend is a store the content of objects is kept in. buckets and objects are addressed by name, a backend keeps
+// the objects of every bucket and is free to lay them out however it wants. methods return ErrObjectNotFound when
+// the object they read does not exist
+type Backend interface {
+	// UploadObject puts the content of an object, replacing the object when it already exists
+	UploadObject(ctx context.Context, objectUpload *ObjectUpload) error
+	GetObject(ctx context.Context, objectGet *ObjectGet) (*ObjectContent, error)
+	HeadObject(ctx context.Context, objectHead *ObjectHead) (*ObjectInfo, error)
+	CheckIfObjectExists(ctx context.Context, objectExistsCheck *ObjectExistsCheck) (bool, error)
+	// DetectContentType sniffs the content type of an object from its first bytes with the algorithm of
+	// http.DetectContentType, which falls back to application/octet-stream for binary content it does not recognise.
+	// only the media type is returned, without parameters like charset
+	DetectContentType(ctx context.Context, objectContentTypeDetection *ObjectContentTypeDetection) (string, error)
+	CopyObject(ctx context.Context, objectCopy *ObjectCopy) error
+	// DeleteObject deletes an object, deleting an object that does not exist is not an error
+	DeleteObject(ctx context.Context, objectDelete *ObjectDelete) error
+	// DeleteObjects deletes many objects at once. objects that could not be deleted are returned instead of failing
+	// the whole call, an error is only returned when the deletion could not be attempted
+	DeleteObjects(ctx context.Context, objectsDelete *ObjectsDelete) ([]*ObjectDeleteError, error)
+	// ListObjects lists up to MaxKeys objects of a bucket whose name starts with the prefix, sorted by name. a listing
+	// is continued with the name of the last object as StartAfter until fewer than MaxKeys objects are returned
+	ListObjects(ctx context.Context, objectsList *ObjectsList) ([]*ListedObject, error)
+
+	CreateMultipartUpload(ctx context.Context, multipartUploadCreate *MultipartUploadCreate) (string, error)
+	ListUploadedParts(ctx context.Context, uploadedPartsList *UploadedPartsList) ([]*UploadedPart, error)
+	CompleteMultipartUpload(ctx context.Context, multipartUploadComplete *MultipartUploadComplete) error
+	// AbortMultipartUpload aborts a multipart upload, aborting an upload that does not exist is not an error
+	AbortMultipartUpload(ctx context.Context, multipartUploadAbort *MultipartUploadAbort) error
+
+	// CreatePreSignedUploadObject, CreatePreSignedDownloadObject and CreatePreSignedUploadPart create urls clients
+	// send requests to directly, without going through the server
+	CreatePreSignedUploadObject(ctx context.Context, preSignedUploadObjectCreate *PreSignedUploadObjectCreate) (*PreSignedObject, error)
+	CreatePreSignedDownloadObject(ctx context.Context, preSignedDownloadObjectCreate *PreSignedDownloadObjectCreate) (*PreSignedObject, error)
+	CreatePreSignedUploadPart(ctx context.Context, preSignedUploadPartCreate *PreSignedUploadPartCreate) (*PreSignedObject, error)
+}
+
+// NewBackend creates the backend selected by the storage_backend of the config
+func NewBackend(cfg *config.Config, logger *zap.Logger) (Backend, error) {
+	switch cfg.StorageBackend {
+	case config.StorageBackendS3:
+		return NewS3Backend(cfg, logger)
+	case config.StorageBackendFilesystem:
+		return NewFilesystemBackend(cfg, logger)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend '%s'", cfg.StorageBackend)
+	}
+}
+
+// TrashBucket returns the bucket the trashed objects of a bucket are kept in, under their object id.
+// bucket names start with an alphanumeric character, so the keys of trashed objects never collide with live objects
+func TrashBucket(bucket string) string {
+	return trashBucketPrefix + bucket
+}
+
+// contentDigest is the size and digests of the content of an object or part
+type contentDigest struct {
+	size   int64
+	md5    []byte
+	sha256 []byte
+	crc32c []byte
+}
+
+// etag returns the etag s3 gives content uploaded with a single put, the quoted hex encoded md5 digest
+func (d *contentDigest) etag() string {
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(d.md5))
+}
+
+// digestContent copies content to w and returns its size and digests
+func digestContent(w io.Writer, content io.Reader) (*contentDigest, error) {
+	md5Hash, sha256Hash, crc32cHash := md5.New(), sha256.New(), crc32.New(crc32cTable)
+
+	size, err := io.Copy(io.MultiWriter(w, md5Hash, sha256Hash, crc32cHash), content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contentDigest{
+		size:   size,
+		md5:    md5Hash.Sum(nil),
+		sha256: sha256Hash.Sum(nil),
+		crc32c: crc32cHash.Sum(nil),
+	}, nil
+}
+
+// multipartETag returns the etag s3 gives an object completed from parts, the md5 digest of the md5 digests of the
+// parts followed by the number of parts
+func multipartETag(partETags []string) (string, error) {
+	digests := md5.New()
+	for _, partETag := range partETags {
+		digest, err := hex.DecodeString(strings.Trim(partETag, `"`))
+		if err != nil {
+			return "", err
+		}
+		digests.Write(digest)
+	}
+	return fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(digests.Sum(nil)), len(partETags)), nil
+}
+
+// isMultipartETag reports whether an unquoted etag is the etag of an object completed from parts
+func isMultipartETag(etag string) bool {
+	return strings.Contains(etag, "-")
+}
+
+// limitSignedContent limits content sent to a signed url to the content length signed into it. reading past the
+// signed length fails with ErrContentLengthMismatch, so that a url signed for a few bytes can not be used to write
+// an unbounded body before the digest is verified
+func limitSignedContent(signedRequest *SignedRequest, content io.Reader) io.Reader {
+	if signedRequest.ContentLength == nil {
+		return content
+	}
+	return &signedContentReader{
+		reader:    io.LimitReader(content, *signedRequest.ContentLength+1),
+		remaining: *signedRequest.ContentLength,
+	}
+}
+
+type signedContentReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *signedContentReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), ErrContentLengthMismatch
+	}
+	return n, err
+}
+
+// verifySignedDigest checks the size and checksum of uploaded content against the values signed into the url
+func verifySignedDigest(signedRequest *SignedRequest, digest *contentDigest) error {
+	if signedRequest.ContentLength != nil && *signedRequest.ContentLength != digest.size {
+		return ErrContentLengthMismatch
+	}
+
+	if signedRequest.ChecksumAlgorithm == nil || signedRequest.Checksum == nil {
+		return nil
+	}
+
+	var sum []byte
+	switch *signedRequest.ChecksumAlgorithm {
+	case ChecksumAlgorithmSHA256:
+		sum = digest.sha256
+	case ChecksumAlgorithmCRC32C:
+		sum = digest.crc32c
+	case ChecksumAlgorithmMD5:
+		sum = digest.md5
+	}
+
+	if base64.StdEncoding.EncodeToString(sum) != *signedRequest.Checksum {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+// parseRange parses a single range of an http range header value like `bytes=0-1023`, `bytes=1024-` or
+// `bytes=-1024`. like s3 the end is clamped to the size of the object
+func parseRange(value string, size int64) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(value, "bytes=")
+	if !ok {
+		return 0, 0, ErrRangeNotSatisfiable
+	}
+
+	startValue, endValue, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, ErrRangeNotSatisfiable
+	}
+
+	if startValue == "" {
+		suffix, err := strconv.ParseInt(endValue, 10, 64)
+		if err != nil || suffix <= 0 || size == 0 {
+			return 0, 0, ErrRangeNotSatisfiable
+		}
+		return max(size-suffix, 0), size - 1, nil
+	}
+
+	start, err := strconv.ParseInt(startValue, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, ErrRangeNotSatisfiable
+	}
+
+	end := size - 1
+	if endValue != "" {
+		end, err = strconv.ParseInt(endValue, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, ErrRangeNotSatisfiable
+		}
+		end = min(end, size-1)
+	}
+
+	return start, end, nil
+}
+
+// preSignedExpiresIn returns how long a pre-signed url is valid for, the default expiry is used when expiresIn is nil
+func preSignedExpiresIn(expiresIn *int64, defaultExpiry int64) time.Duration {
+	if expiresIn != nil {
+		return time.Duration(*expiresIn) * time.Second
+	}
+	return time.Duration(defaultExpiry) * time.Second
+}
+
+// DetectContentType sniffs the content type of content that has not been written to storage yet like
+// Backend.DetectContentType does for stored objects. the first bytes are peeked, so the content is still read in full
+func DetectContentType(content *bufio.Reader) (string, error) {
+	head, err := content.Peek(contentTypeDetectionSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return detectContentType(bytes.NewReader(head))
+}
+
+func detectContentType(content io.Reader) (string, error) {
+	head, err := io.ReadAll(io.LimitReader(content, contentTypeDetectionSize))
+	if err != nil {
+		return "", err
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "", err
+	}
+
+	return contentType, nil
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		start    int64
+		end      int64
+		expected error
+	}{
+		{name: "Closed Range", value: "bytes=0-1023", start: 0, end: 1023},
+		{name: "Open Range", value: "bytes=1024-", start: 1024, end: 2047},
+		{name: "Suffix Range", value: "bytes=-24", start: 2024, end: 2047},
+		{name: "Clamped Range", value: "bytes=2000-4095", start: 2000, end: 2047},
+		{name: "Start Beyond Size", value: "bytes=2048-", expected: ErrRangeNotSatisfiable},
+		{name: "End Before Start", value: "bytes=10-5", expected: ErrRangeNotSatisfiable},
+		{name: "Invalid Unit", value: "items=0-1", expected: ErrRangeNotSatisfiable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, err := parseRange(test.value, 2048)
+			assert.Equal(t, test.expected, err)
+			if test.expected == nil {
+				assert.Equal(t, test.start, start)
+				assert.Equal(t, test.end, end)
+			}
+		})
+	}
+}
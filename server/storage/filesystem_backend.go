@@ -0,0 +1,1142 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// filesystemTempDirectory, filesystemMetadataDirectory and filesystemUploadsDirectory are kept in the root next to
+// the buckets. bucket names start with an alphanumeric character, so they never collide with a bucket
+const (
+	filesystemTempDirectory     = ".tmp"
+	filesystemMetadataDirectory = ".metadata"
+	filesystemUploadsDirectory  = ".uploads"
+)
+
+// filesystemUploadFile is the file the bucket, name and content type of a multipart upload are kept in
+const filesystemUploadFile = "upload.json"
+
+// filesystemPartPrefix is the prefix of the files the parts of a multipart upload are kept in, followed by the part number
+const filesystemPartPrefix = "part-"
+
+// filesystemLockCount is the number of locks the objects are spread over by key
+const filesystemLockCount = 256
+
+// filesystemSignedUrlPath is the path the pre-signed urls of the filesystem backend are served under
+const filesystemSignedUrlPath = "/signed/"
+
+var ErrInvalidObjectName = errors.New("object name can not be stored on the filesystem")
+
+// FilesystemBackend keeps objects in a directory of the local filesystem with the same `bucket/name` layout as the
+// keys of the s3 backend. content is written to a temp file and renamed into place, so readers never see a partially
+// written object. the content type, etag and checksums of an object are kept in a metadata file under the same path
+// in the metadata directory.
+//
+// pre-signed urls are HMAC signed urls served by the server itself. the backend is meant for a single server, the
+// root must not be shared between servers. names with empty, `.` or `..` segments and names of which another name
+// is a prefix up to a `/` can not be stored on a filesystem and fail with ErrInvalidObjectName or a filesystem error
+type FilesystemBackend struct {
+	root       string
+	publicUrl  string
+	signingKey []byte
+	locks      [filesystemLockCount]sync.RWMutex
+	config     *config.Config
+	logger     *zap.Logger
+}
+
+// filesystemMetadata is the metadata of an object that the filesystem does not keep
+type filesystemMetadata struct {
+	ContentType    string `json:"content_type"`
+	ETag           string `json:"etag"`
+	ChecksumSHA256 string `json:"checksum_sha256"`
+	ChecksumCRC32C string `json:"checksum_crc32c"`
+}
+
+// filesystemUpload is a multipart upload in progress
+type filesystemUpload struct {
+	Bucket      string `json:"bucket"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+}
+
+var _ Backend = (*FilesystemBackend)(nil)
+
+// NewFilesystemBackend creates a backend that keeps objects under the filesystem root of the config. temp files left
+// over by a previous run are removed
+func NewFilesystemBackend(config *config.Config, logger *zap.Logger) (*FilesystemBackend, error) {
+	root, err := filepath.Abs(config.FilesystemRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.RemoveAll(filepath.Join(root, filesystemTempDirectory))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, directory := range []string{filesystemTempDirectory, filesystemMetadataDirectory, filesystemUploadsDirectory} {
+		err = os.MkdirAll(filepath.Join(root, directory), 0o755)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &FilesystemBackend{
+		root:       root,
+		publicUrl:  strings.TrimSuffix(config.FilesystemPublicUrl, "/"),
+		signingKey: []byte(config.FilesystemSigningKey),
+		config:     config,
+		logger:     logger,
+	}, nil
+}
+
+func (f *FilesystemBackend) UploadObject(ctx context.Context, objectUpload *ObjectUpload) error {
+	const op = "FilesystemBackend.UploadObject"
+
+	tempPath, digest, err := f.writeTemp(ctx, objectUpload.Content)
+	if err != nil {
+		f.logger.Error("failed to write object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	err = f.commitObject(objectUpload.Bucket, objectUpload.Name, tempPath, filesystemMetadataOf(digest, objectUpload.ContentType))
+	if err != nil {
+		f.logger.Error("failed to commit object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	return nil
+}
+
+func (f *FilesystemBackend) CreatePreSignedUploadObject(ctx context.Context, preSignedUploadObjectCreate *PreSignedUploadObjectCreate) (*PreSignedObject, error) {
+	if preSignedUploadObjectCreate.ChecksumAlgorithm != nil && preSignedUploadObjectCreate.Checksum != nil {
+		switch *preSignedUploadObjectCreate.ChecksumAlgorithm {
+		case ChecksumAlgorithmSHA256, ChecksumAlgorithmCRC32C, ChecksumAlgorithmMD5:
+		default:
+			return nil, fmt.Errorf("unsupported checksum algorithm '%s'", *preSignedUploadObjectCreate.ChecksumAlgorithm)
+		}
+	}
+
+	expiresIn := preSignedExpiresIn(preSignedUploadObjectCreate.ExpiresIn, f.config.DefaultPreSignedUploadUrlExpiry)
+
+	signedRequest := &SignedRequest{
+		Method:        http.MethodPut,
+		Bucket:        preSignedUploadObjectCreate.Bucket,
+		Name:          preSignedUploadObjectCreate.Name,
+		ExpiresAt:     time.Now().Add(expiresIn).Unix(),
+		ContentType:   &preSignedUploadObjectCreate.ContentType,
+		ContentLength: &preSignedUploadObjectCreate.ContentLength,
+	}
+	if preSignedUploadObjectCreate.ChecksumAlgorithm != nil && preSignedUploadObjectCreate.Checksum != nil {
+		signedRequest.ChecksumAlgorithm = preSignedUploadObjectCreate.ChecksumAlgorithm
+		signedRequest.Checksum = preSignedUploadObjectCreate.Checksum
+	}
+
+	return &PreSignedObject{
+		Url:       f.signedUrl(signedRequest),
+		Method:    signedRequest.Method,
+		ExpiresAt: signedRequest.ExpiresAt,
+		Headers: map[string]string{
+			"Content-Type": preSignedUploadObjectCreate.ContentType,
+		},
+	}, nil
+}
+
+func (f *FilesystemBackend) CreatePreSignedDownloadObject(ctx context.Context, preSignedDownloadObjectCreate *PreSignedDownloadObjectCreate) (*PreSignedObject, error) {
+	expiresIn := preSignedExpiresIn(preSignedDownloadObjectCreate.ExpiresIn, f.config.DefaultPreSignedDownloadUrlExpiry)
+
+	signedRequest := &SignedRequest{
+		Method:    http.MethodGet,
+		Bucket:    preSignedDownloadObjectCreate.Bucket,
+		Name:      preSignedDownloadObjectCreate.Name,
+		ExpiresAt: time.Now().Add(expiresIn).Unix(),
+	}
+
+	return &PreSignedObject{
+		Url:       f.signedUrl(signedRequest),
+		Method:    signedRequest.Method,
+		ExpiresAt: signedRequest.ExpiresAt,
+	}, nil
+}
+
+func (f *FilesystemBackend) CreateMultipartUpload(ctx context.Context, multipartUploadCreate *MultipartUploadCreate) (string, error) {
+	const op = "FilesystemBackend.CreateMultipartUpload"
+
+	if _, err := f.objectPath(multipartUploadCreate.Bucket, multipartUploadCreate.Name); err != nil {
+		return "", err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	uploadId := hex.EncodeToString(id)
+
+	upload, err := json.Marshal(&filesystemUpload{
+		Bucket:      multipartUploadCreate.Bucket,
+		Name:        multipartUploadCreate.Name,
+		ContentType: multipartUploadCreate.ContentType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	uploadPath := filepath.Join(f.root, filesystemUploadsDirectory, uploadId)
+
+	err = os.Mkdir(uploadPath, 0o755)
+	if err != nil {
+		f.logger.Error("failed to create multipart upload directory", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	tempPath, _, err := f.writeTemp(ctx, bytes.NewReader(upload))
+	if err != nil {
+		f.logger.Error("failed to write multipart upload", zap.Error(err), zapfield.Operation(op))
+		_ = os.RemoveAll(uploadPath)
+		return "", err
+	}
+
+	err = os.Rename(tempPath, filepath.Join(uploadPath, filesystemUploadFile))
+	if err != nil {
+		f.logger.Error("failed to commit multipart upload", zap.Error(err), zapfield.Operation(op))
+		_ = os.Remove(tempPath)
+		_ = os.RemoveAll(uploadPath)
+		return "", err
+	}
+
+	return uploadId, nil
+}
+
+func (f *FilesystemBackend) CreatePreSignedUploadPart(ctx context.Context, preSignedUploadPartCreate *PreSignedUploadPartCreate) (*PreSignedObject, error) {
+	expiresIn := preSignedExpiresIn(preSignedUploadPartCreate.ExpiresIn, f.config.DefaultPreSignedUploadUrlExpiry)
+
+	signedRequest := &SignedRequest{
+		Method:     http.MethodPut,
+		Bucket:     preSignedUploadPartCreate.Bucket,
+		Name:       preSignedUploadPartCreate.Name,
+		ExpiresAt:  time.Now().Add(expiresIn).Unix(),
+		UploadId:   &preSignedUploadPartCreate.UploadId,
+		PartNumber: &preSignedUploadPartCreate.PartNumber,
+	}
+
+	return &PreSignedObject{
+		Url:       f.signedUrl(signedRequest),
+		Method:    signedRequest.Method,
+		ExpiresAt: signedRequest.ExpiresAt,
+	}, nil
+}
+
+func (f *FilesystemBackend) ListUploadedParts(ctx context.Context, uploadedPartsList *UploadedPartsList) ([]*UploadedPart, error) {
+	const op = "FilesystemBackend.ListUploadedParts"
+
+	uploadPath, err := f.uploadPath(uploadedPartsList.Bucket, uploadedPartsList.Name, uploadedPartsList.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(uploadPath)
+	if err != nil {
+		if isFilesystemNotFoundError(err) {
+			return nil, ErrObjectNotFound
+		}
+		f.logger.Error("failed to read multipart upload directory", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	var uploadedParts []*UploadedPart
+
+	for _, entry := range entries {
+		partNumber, ok := parseFilesystemPartName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if isFilesystemNotFoundError(err) {
+				continue
+			}
+			f.logger.Error("failed to stat uploaded part", zap.Error(err), zapfield.Operation(op))
+			return nil, err
+		}
+
+		etag, err := os.ReadFile(filepath.Join(uploadPath, entry.Name()+".etag"))
+		if err != nil {
+			if isFilesystemNotFoundError(err) {
+				continue
+			}
+			f.logger.Error("failed to read uploaded part etag", zap.Error(err), zapfield.Operation(op))
+			return nil, err
+		}
+
+		uploadedParts = append(uploadedParts, &UploadedPart{
+			PartNumber:   partNumber,
+			ETag:         string(etag),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	}
+
+	slices.SortFunc(uploadedParts, func(a *UploadedPart, b *UploadedPart) int {
+		return int(a.PartNumber - b.PartNumber)
+	})
+
+	return uploadedParts, nil
+}
+
+// CompleteMultipartUpload concatenates the parts into the object. like s3 the etag of the object is the md5 digest of
+// the md5 digests of the parts followed by the number of parts
+func (f *FilesystemBackend) CompleteMultipartUpload(ctx context.Context, multipartUploadComplete *MultipartUploadComplete) error {
+	const op = "FilesystemBackend.CompleteMultipartUpload"
+
+	uploadPath, err := f.uploadPath(multipartUploadComplete.Bucket, multipartUploadComplete.Name, multipartUploadComplete.UploadId)
+	if err != nil {
+		return err
+	}
+
+	upload, err := f.readUpload(uploadPath)
+	if err != nil {
+		return err
+	}
+
+	if len(multipartUploadComplete.Parts) == 0 {
+		return ErrInvalidPart
+	}
+
+	partPaths := make([]string, 0, len(multipartUploadComplete.Parts))
+	partETags := make([]string, 0, len(multipartUploadComplete.Parts))
+
+	for i, part := range multipartUploadComplete.Parts {
+		if i > 0 && part.PartNumber <= multipartUploadComplete.Parts[i-1].PartNumber {
+			return ErrInvalidPart
+		}
+
+		partPath := filepath.Join(uploadPath, filesystemPartName(part.PartNumber))
+
+		etag, err := os.ReadFile(partPath + ".etag")
+		if err != nil {
+			if isFilesystemNotFoundError(err) {
+				return ErrInvalidPart
+			}
+			f.logger.Error("failed to read uploaded part etag", zap.Error(err), zapfield.Operation(op))
+			return err
+		}
+
+		if strings.Trim(string(etag), `"`) != strings.Trim(part.ETag, `"`) {
+			return ErrInvalidPart
+		}
+
+		partPaths = append(partPaths, partPath)
+		partETags = append(partETags, string(etag))
+	}
+
+	etag, err := multipartETag(partETags)
+	if err != nil {
+		return err
+	}
+
+	parts := &filesystemPartsReader{paths: partPaths}
+	defer parts.Close()
+
+	tempPath, digest, err := f.writeTemp(ctx, parts)
+	if err != nil {
+		f.logger.Error("failed to concatenate uploaded parts", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	metadata := filesystemMetadataOf(digest, upload.ContentType)
+	metadata.ETag = etag
+
+	err = f.commitObject(upload.Bucket, upload.Name, tempPath, metadata)
+	if err != nil {
+		f.logger.Error("failed to commit object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	err = os.RemoveAll(uploadPath)
+	if err != nil {
+		f.logger.Error("failed to remove multipart upload directory", zap.Error(err), zapfield.Operation(op))
+	}
+
+	return nil
+}
+
+func (f *FilesystemBackend) AbortMultipartUpload(ctx context.Context, multipartUploadAbort *MultipartUploadAbort) error {
+	const op = "FilesystemBackend.AbortMultipartUpload"
+
+	uploadPath, err := f.uploadPath(multipartUploadAbort.Bucket, multipartUploadAbort.Name, multipartUploadAbort.UploadId)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	err = os.RemoveAll(uploadPath)
+	if err != nil {
+		f.logger.Error("failed to remove multipart upload directory", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	return nil
+}
+
+func (f *FilesystemBackend) CheckIfObjectExists(ctx context.Context, objectExistsCheck *ObjectExistsCheck) (bool, error) {
+	const op = "FilesystemBackend.CheckIfObjectExists"
+
+	path, err := f.objectPath(objectExistsCheck.Bucket, objectExistsCheck.Name)
+	if err != nil {
+		return false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if isFilesystemNotFoundError(err) {
+			return false, nil
+		}
+		f.logger.Error("failed to stat object", zap.Error(err), zapfield.Operation(op))
+		return false, err
+	}
+
+	return info.Mode().IsRegular(), nil
+}
+
+func (f *FilesystemBackend) HeadObject(ctx context.Context, objectHead *ObjectHead) (*ObjectInfo, error) {
+	const op = "FilesystemBackend.HeadObject"
+
+	file, metadata, err := f.openObject(objectHead.Bucket, objectHead.Name)
+	if err != nil {
+		if !errors.Is(err, ErrObjectNotFound) {
+			f.logger.Error("failed to open object", zap.Error(err), zapfield.Operation(op))
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		f.logger.Error("failed to stat object", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		ContentType:    metadata.ContentType,
+		ContentLength:  info.Size(),
+		ETag:           metadata.ETag,
+		LastModified:   info.ModTime(),
+		ChecksumSHA256: metadata.ChecksumSHA256,
+		ChecksumCRC32C: metadata.ChecksumCRC32C,
+	}, nil
+}
+
+func (f *FilesystemBackend) DetectContentType(ctx context.Context, objectContentTypeDetection *ObjectContentTypeDetection) (string, error) {
+	const op = "FilesystemBackend.DetectContentType"
+
+	file, _, err := f.openObject(objectContentTypeDetection.Bucket, objectContentTypeDetection.Name)
+	if err != nil {
+		if !errors.Is(err, ErrObjectNotFound) {
+			f.logger.Error("failed to open object", zap.Error(err), zapfield.Operation(op))
+		}
+		return "", err
+	}
+	defer file.Close()
+
+	contentType, err := detectContentType(file)
+	if err != nil {
+		f.logger.Error("failed to read object", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	return contentType, nil
+}
+
+// GetObject gets the content of an object. the content has to be closed by the caller
+func (f *FilesystemBackend) GetObject(ctx context.Context, objectGet *ObjectGet) (*ObjectContent, error) {
+	const op = "FilesystemBackend.GetObject"
+
+	file, metadata, err := f.openObject(objectGet.Bucket, objectGet.Name)
+	if err != nil {
+		if !errors.Is(err, ErrObjectNotFound) {
+			f.logger.Error("failed to open object", zap.Error(err), zapfield.Operation(op))
+		}
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		f.logger.Error("failed to stat object", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	objectContent := &ObjectContent{
+		ContentType:   metadata.ContentType,
+		ContentLength: info.Size(),
+		ETag:          metadata.ETag,
+		LastModified:  info.ModTime(),
+		Content:       file,
+	}
+
+	if objectGet.Range == nil {
+		return objectContent, nil
+	}
+
+	start, end, err := parseRange(*objectGet.Range, info.Size())
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	_, err = file.Seek(start, io.SeekStart)
+	if err != nil {
+		_ = file.Close()
+		f.logger.Error("failed to seek object", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	objectContent.ContentLength = end - start + 1
+	objectContent.ContentRange = lo.ToPtr(fmt.Sprintf("bytes %d-%d/%d", start, end, info.Size()))
+	objectContent.Content = &filesystemRangeReader{Reader: io.LimitReader(file, end-start+1), Closer: file}
+
+	return objectContent, nil
+}
+
+func (f *FilesystemBackend) CopyObject(ctx context.Context, objectCopy *ObjectCopy) error {
+	const op = "FilesystemBackend.CopyObject"
+
+	source, metadata, err := f.openObject(objectCopy.SourceBucket, objectCopy.SourceName)
+	if err != nil {
+		if !errors.Is(err, ErrObjectNotFound) {
+			f.logger.Error("failed to open source object", zap.Error(err), zapfield.Operation(op))
+		}
+		return err
+	}
+	defer source.Close()
+
+	tempPath, _, err := f.writeTemp(ctx, source)
+	if err != nil {
+		f.logger.Error("failed to copy object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	if objectCopy.ContentType != nil {
+		metadata.ContentType = *objectCopy.ContentType
+	}
+
+	err = f.commitObject(objectCopy.DestinationBucket, objectCopy.DestinationName, tempPath, metadata)
+	if err != nil {
+		f.logger.Error("failed to commit object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	return nil
+}
+
+func (f *FilesystemBackend) DeleteObject(ctx context.Context, objectDelete *ObjectDelete) error {
+	const op = "FilesystemBackend.DeleteObject"
+
+	err := f.deleteObject(objectDelete.Bucket, objectDelete.Name)
+	if err != nil {
+		f.logger.Error("failed to delete object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteObjects deletes the objects one by one. objects that fail to delete are returned instead of failing the
+// whole call, an error is only returned when the context is done. objects that do not exist are considered deleted
+func (f *FilesystemBackend) DeleteObjects(ctx context.Context, objectsDelete *ObjectsDelete) ([]*ObjectDeleteError, error) {
+	const op = "FilesystemBackend.DeleteObjects"
+
+	var deleteErrors []*ObjectDeleteError
+
+	for _, name := range objectsDelete.Names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		err := f.deleteObject(objectsDelete.Bucket, name)
+		if err != nil {
+			f.logger.Error("failed to delete object", zap.Error(err), zapfield.Operation(op))
+			deleteErrors = append(deleteErrors, &ObjectDeleteError{
+				Name:    name,
+				Code:    "InternalError",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	return deleteErrors, nil
+}
+
+// ListObjects walks the bucket directory below the last `/` of the prefix. the names are sorted before the objects
+// are read since the walk order of nested directories is not the byte order of their names. objects that are
+// deleted while they are listed are left out
+func (f *FilesystemBackend) ListObjects(ctx context.Context, objectsList *ObjectsList) ([]*ListedObject, error) {
+	const op = "FilesystemBackend.ListObjects"
+
+	bucketPath := filepath.Join(f.root, filepath.FromSlash(objectsList.Bucket))
+
+	directory := ""
+	if i := strings.LastIndex(objectsList.Prefix, "/"); i >= 0 {
+		directory = objectsList.Prefix[:i]
+
+		// a prefix that does not map to a directory of the bucket can not match any stored object
+		if _, err := f.objectPath(objectsList.Bucket, directory); err != nil {
+			return nil, nil
+		}
+	}
+
+	var names []string
+	err := filepath.WalkDir(filepath.Join(bucketPath, filepath.FromSlash(directory)), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if isFilesystemNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(bucketPath, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relativePath)
+		if strings.HasPrefix(name, objectsList.Prefix) && name > objectsList.StartAfter {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		f.logger.Error("failed to walk bucket directory", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	slices.Sort(names)
+
+	var listedObjects []*ListedObject
+	for _, name := range names {
+		if len(listedObjects) >= int(objectsList.MaxKeys) {
+			break
+		}
+
+		objectInfo, err := f.HeadObject(ctx, &ObjectHead{Bucket: objectsList.Bucket, Name: name})
+		if err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		listedObjects = append(listedObjects, &ListedObject{
+			Name:         name,
+			Size:         objectInfo.ContentLength,
+			ETag:         objectInfo.ETag,
+			LastModified: objectInfo.LastModified,
+		})
+	}
+
+	return listedObjects, nil
+}
+
+// UploadSignedObject writes the content sent to a pre-signed upload object or upload part url and returns its etag.
+// the content type, length and checksum signed into the url are enforced like s3 enforces signed headers
+func (f *FilesystemBackend) UploadSignedObject(ctx context.Context, signedRequest *SignedRequest, signedUpload *SignedUpload) (string, error) {
+	const op = "FilesystemBackend.UploadSignedObject"
+
+	err := f.verifySignedRequest(signedRequest, http.MethodPut)
+	if err != nil {
+		return "", err
+	}
+
+	if signedRequest.ContentType != nil && *signedRequest.ContentType != signedUpload.ContentType {
+		return "", ErrContentTypeMismatch
+	}
+
+	content := limitSignedContent(signedRequest, signedUpload.Content)
+
+	if signedRequest.UploadId != nil && signedRequest.PartNumber != nil {
+		return f.uploadPart(ctx, signedRequest, content, op)
+	}
+
+	tempPath, digest, err := f.writeTemp(ctx, content)
+	if err != nil {
+		if errors.Is(err, ErrContentLengthMismatch) {
+			return "", err
+		}
+		f.logger.Error("failed to write object", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	err = verifySignedDigest(signedRequest, digest)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return "", err
+	}
+
+	metadata := filesystemMetadataOf(digest, signedUpload.ContentType)
+
+	err = f.commitObject(signedRequest.Bucket, signedRequest.Name, tempPath, metadata)
+	if err != nil {
+		f.logger.Error("failed to commit object", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	return metadata.ETag, nil
+}
+
+// DownloadSignedObject gets the content of the object of a pre-signed download url. the content has to be closed by
+// the caller
+func (f *FilesystemBackend) DownloadSignedObject(ctx context.Context, signedRequest *SignedRequest, rangeHeader *string) (*ObjectContent, error) {
+	err := f.verifySignedRequest(signedRequest, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetObject(ctx, &ObjectGet{
+		Bucket: signedRequest.Bucket,
+		Name:   signedRequest.Name,
+		Range:  rangeHeader,
+	})
+}
+
+func (f *FilesystemBackend) uploadPart(ctx context.Context, signedRequest *SignedRequest, content io.Reader, op string) (string, error) {
+	uploadPath, err := f.uploadPath(signedRequest.Bucket, signedRequest.Name, *signedRequest.UploadId)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = f.readUpload(uploadPath); err != nil {
+		return "", err
+	}
+
+	tempPath, digest, err := f.writeTemp(ctx, content)
+	if err != nil {
+		if errors.Is(err, ErrContentLengthMismatch) {
+			return "", err
+		}
+		f.logger.Error("failed to write part", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	err = verifySignedDigest(signedRequest, digest)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return "", err
+	}
+
+	etag := digest.etag()
+
+	etagTempPath, _, err := f.writeTemp(ctx, strings.NewReader(etag))
+	if err != nil {
+		_ = os.Remove(tempPath)
+		f.logger.Error("failed to write part etag", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	partPath := filepath.Join(uploadPath, filesystemPartName(*signedRequest.PartNumber))
+
+	err = os.Rename(tempPath, partPath)
+	if err == nil {
+		err = os.Rename(etagTempPath, partPath+".etag")
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+		_ = os.Remove(etagTempPath)
+		f.logger.Error("failed to commit part", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	return etag, nil
+}
+
+// verifySignedRequest checks that the request was signed by this backend for the method and has not expired
+func (f *FilesystemBackend) verifySignedRequest(signedRequest *SignedRequest, method string) error {
+	if signedRequest.Method != method || !hmac.Equal([]byte(f.sign(signedRequest)), []byte(signedRequest.Signature)) {
+		return ErrSignedUrlInvalid
+	}
+
+	if time.Now().Unix() > signedRequest.ExpiresAt {
+		return ErrSignedUrlExpired
+	}
+
+	return nil
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the signed fields of the request. object names can not contain new
+// lines, so the fields are joined with new lines
+func (f *FilesystemBackend) sign(signedRequest *SignedRequest) string {
+	var partNumber, contentLength string
+	if signedRequest.PartNumber != nil {
+		partNumber = strconv.FormatInt(int64(*signedRequest.PartNumber), 10)
+	}
+	if signedRequest.ContentLength != nil {
+		contentLength = strconv.FormatInt(*signedRequest.ContentLength, 10)
+	}
+
+	mac := hmac.New(sha256.New, f.signingKey)
+	mac.Write([]byte(strings.Join([]string{
+		signedRequest.Method,
+		signedRequest.Bucket,
+		signedRequest.Name,
+		strconv.FormatInt(signedRequest.ExpiresAt, 10),
+		lo.FromPtr(signedRequest.UploadId),
+		partNumber,
+		lo.FromPtr(signedRequest.ContentType),
+		contentLength,
+		lo.FromPtr(signedRequest.ChecksumAlgorithm),
+		lo.FromPtr(signedRequest.Checksum),
+	}, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedUrl signs the request and returns the url it is served at
+func (f *FilesystemBackend) signedUrl(signedRequest *SignedRequest) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(signedRequest.ExpiresAt, 10))
+	if signedRequest.UploadId != nil {
+		query.Set("upload_id", *signedRequest.UploadId)
+	}
+	if signedRequest.PartNumber != nil {
+		query.Set("part_number", strconv.FormatInt(int64(*signedRequest.PartNumber), 10))
+	}
+	if signedRequest.ContentType != nil {
+		query.Set("content_type", *signedRequest.ContentType)
+	}
+	if signedRequest.ContentLength != nil {
+		query.Set("content_length", strconv.FormatInt(*signedRequest.ContentLength, 10))
+	}
+	if signedRequest.ChecksumAlgorithm != nil && signedRequest.Checksum != nil {
+		query.Set("checksum_algorithm", *signedRequest.ChecksumAlgorithm)
+		query.Set("checksum", *signedRequest.Checksum)
+	}
+	query.Set("signature", f.sign(signedRequest))
+
+	segments := strings.Split(signedRequest.Name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return f.publicUrl + filesystemSignedUrlPath + url.PathEscape(signedRequest.Bucket) + "/" + strings.Join(segments, "/") + "?" + query.Encode()
+}
+
+// objectPath returns the path of the content of an object. names that can not be mapped to a path under the bucket
+// directory fail with ErrInvalidObjectName
+func (f *FilesystemBackend) objectPath(bucket string, name string) (string, error) {
+	for _, segment := range strings.Split(createS3Key(bucket, name), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", ErrInvalidObjectName
+		}
+	}
+	return filepath.Join(f.root, filepath.FromSlash(createS3Key(bucket, name))), nil
+}
+
+func (f *FilesystemBackend) metadataPath(bucket string, name string) string {
+	return filepath.Join(f.root, filesystemMetadataDirectory, filepath.FromSlash(createS3Key(bucket, name)))
+}
+
+// uploadPath returns the directory of a multipart upload. upload ids are generated by the backend, ids of another
+// form do not exist
+func (f *FilesystemBackend) uploadPath(bucket string, name string, uploadId string) (string, error) {
+	if _, err := f.objectPath(bucket, name); err != nil {
+		return "", err
+	}
+	if _, err := hex.DecodeString(uploadId); err != nil || len(uploadId) != 32 {
+		return "", ErrObjectNotFound
+	}
+	return filepath.Join(f.root, filesystemUploadsDirectory, uploadId), nil
+}
+
+func (f *FilesystemBackend) readUpload(uploadPath string) (*filesystemUpload, error) {
+	content, err := os.ReadFile(filepath.Join(uploadPath, filesystemUploadFile))
+	if err != nil {
+		if isFilesystemNotFoundError(err) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	var upload filesystemUpload
+	err = json.Unmarshal(content, &upload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+// lock returns the lock of an object. it is held while the content and metadata of the object are renamed into
+// place or opened, so readers never see the content of one write with the metadata of another
+func (f *FilesystemBackend) lock(bucket string, name string) *sync.RWMutex {
+	key := fnv.New32a()
+	key.Write([]byte(createS3Key(bucket, name)))
+	return &f.locks[key.Sum32()%filesystemLockCount]
+}
+
+// openObject opens the content of an object and reads its metadata
+func (f *FilesystemBackend) openObject(bucket string, name string) (*os.File, *filesystemMetadata, error) {
+	path, err := f.objectPath(bucket, name)
+	if err != nil {
+		return nil, nil, ErrObjectNotFound
+	}
+
+	lock := f.lock(bucket, name)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		if isFilesystemNotFoundError(err) {
+			return nil, nil, ErrObjectNotFound
+		}
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		_ = file.Close()
+		return nil, nil, ErrObjectNotFound
+	}
+
+	metadata := &filesystemMetadata{ContentType: "application/octet-stream"}
+
+	content, err := os.ReadFile(f.metadataPath(bucket, name))
+	if err != nil && !isFilesystemNotFoundError(err) {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(content, metadata)
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+	}
+
+	return file, metadata, nil
+}
+
+// writeTemp writes content to a new temp file and returns its path and the digests of the content. the temp file is
+// removed when writing fails
+func (f *FilesystemBackend) writeTemp(ctx context.Context, content io.Reader) (string, *contentDigest, error) {
+	file, err := os.CreateTemp(filepath.Join(f.root, filesystemTempDirectory), "object-*")
+	if err != nil {
+		return "", nil, err
+	}
+
+	digest, err := digestContent(file, &filesystemContextReader{ctx: ctx, reader: content})
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", nil, err
+	}
+
+	return file.Name(), digest, nil
+}
+
+// commitObject renames a temp file written by writeTemp into place as the content of an object together with its
+// metadata. the temp file is removed when committing fails
+func (f *FilesystemBackend) commitObject(bucket string, name string, tempPath string, metadata *filesystemMetadata) error {
+	path, err := f.objectPath(bucket, name)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	metadataPath := f.metadataPath(bucket, name)
+
+	content, err := json.Marshal(metadata)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+
+	metadataTempPath, _, err := f.writeTemp(context.Background(), bytes.NewReader(content))
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+
+	for _, directory := range []string{filepath.Dir(path), filepath.Dir(metadataPath)} {
+		err = os.MkdirAll(directory, 0o755)
+		if err != nil {
+			_ = os.Remove(tempPath)
+			_ = os.Remove(metadataTempPath)
+			return err
+		}
+	}
+
+	lock := f.lock(bucket, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		_ = os.Remove(metadataTempPath)
+		return err
+	}
+
+	err = os.Rename(metadataTempPath, metadataPath)
+	if err != nil {
+		_ = os.Remove(metadataTempPath)
+		return err
+	}
+
+	return nil
+}
+
+// deleteObject removes the content and metadata of an object. objects that do not exist are considered deleted
+func (f *FilesystemBackend) deleteObject(bucket string, name string) error {
+	path, err := f.objectPath(bucket, name)
+	if err != nil {
+		return nil
+	}
+
+	lock := f.lock(bucket, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, p := range []string{path, f.metadataPath(bucket, name)} {
+		err = os.Remove(p)
+		if err != nil && !isFilesystemNotFoundError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func filesystemMetadataOf(digest *contentDigest, contentType string) *filesystemMetadata {
+	return &filesystemMetadata{
+		ContentType:    contentType,
+		ETag:           digest.etag(),
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(digest.sha256),
+		ChecksumCRC32C: base64.StdEncoding.EncodeToString(digest.crc32c),
+	}
+}
+
+func filesystemPartName(partNumber int32) string {
+	return fmt.Sprintf("%s%05d", filesystemPartPrefix, partNumber)
+}
+
+func parseFilesystemPartName(name string) (int32, bool) {
+	number, ok := strings.CutPrefix(name, filesystemPartPrefix)
+	if !ok || strings.Contains(number, ".") {
+		return 0, false
+	}
+
+	partNumber, err := strconv.ParseInt(number, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return int32(partNumber), true
+}
+
+// isFilesystemNotFoundError reports whether a path does not exist, including paths of which a parent is a file
+func isFilesystemNotFoundError(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR)
+}
+
+// filesystemRangeReader reads a range of a file and closes the file
+type filesystemRangeReader struct {
+	io.Reader
+	io.Closer
+}
+
+// filesystemContextReader stops reading once the context is done, so writes of large content can be cancelled
+type filesystemContextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *filesystemContextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// filesystemPartsReader reads the parts of a multipart upload one after another. each part is only opened once it is
+// reached, so uploads with many parts do not hold a file open for every part
+type filesystemPartsReader struct {
+	paths   []string
+	current *os.File
+}
+
+func (r *filesystemPartsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+
+			file, err := os.Open(r.paths[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.paths = file, r.paths[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			_ = r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (r *filesystemPartsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
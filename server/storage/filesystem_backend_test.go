@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestFilesystemBackend(t *testing.T) *FilesystemBackend {
+	backend, err := NewFilesystemBackend(&config.Config{
+		FilesystemRoot:                    t.TempDir(),
+		FilesystemPublicUrl:               "http://localhost:3001/",
+		FilesystemSigningKey:              "01HPG7BZW4HDEHWPS0FT50N3NX",
+		DefaultPreSignedUploadUrlExpiry:   120,
+		DefaultPreSignedDownloadUrlExpiry: 300,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	return backend
+}
+
+// signedRequestFromUrl parses a pre-signed url back into the request the controller builds from it
+func signedRequestFromUrl(t *testing.T, method string, signedUrl string) *SignedRequest {
+	parsedUrl, err := url.Parse(signedUrl)
+	require.NoError(t, err)
+
+	bucket, name, _ := strings.Cut(strings.TrimPrefix(parsedUrl.Path, filesystemSignedUrlPath), "/")
+	query := parsedUrl.Query()
+
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	require.NoError(t, err)
+
+	signedRequest := &SignedRequest{
+		Method:    method,
+		Bucket:    bucket,
+		Name:      name,
+		ExpiresAt: expiresAt,
+		Signature: query.Get("signature"),
+	}
+	if query.Has("upload_id") {
+		signedRequest.UploadId = lo.ToPtr(query.Get("upload_id"))
+	}
+	if query.Has("part_number") {
+		partNumber, err := strconv.ParseInt(query.Get("part_number"), 10, 32)
+		require.NoError(t, err)
+		signedRequest.PartNumber = lo.ToPtr(int32(partNumber))
+	}
+	if query.Has("content_type") {
+		signedRequest.ContentType = lo.ToPtr(query.Get("content_type"))
+	}
+	if query.Has("content_length") {
+		contentLength, err := strconv.ParseInt(query.Get("content_length"), 10, 64)
+		require.NoError(t, err)
+		signedRequest.ContentLength = &contentLength
+	}
+	if query.Has("checksum_algorithm") {
+		signedRequest.ChecksumAlgorithm = lo.ToPtr(query.Get("checksum_algorithm"))
+		signedRequest.Checksum = lo.ToPtr(query.Get("checksum"))
+	}
+
+	return signedRequest
+}
+
+func readObject(t *testing.T, backend *FilesystemBackend, bucket string, name string, contentRange *string) (*ObjectContent, string) {
+	objectContent, err := backend.GetObject(context.Background(), &ObjectGet{Bucket: bucket, Name: name, Range: contentRange})
+	require.NoError(t, err)
+	defer objectContent.Content.Close()
+
+	content, err := io.ReadAll(objectContent.Content)
+	require.NoError(t, err)
+
+	return objectContent, string(content)
+}
+
+func TestFilesystemBackend_Objects(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestFilesystemBackend(t)
+
+	err := backend.UploadObject(ctx, &ObjectUpload{Bucket: "avatars", Name: "user/david/avatar.txt", ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	require.NoError(t, err)
+
+	objectInfo, err := backend.HeadObject(ctx, &ObjectHead{Bucket: "avatars", Name: "user/david/avatar.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", objectInfo.ContentType)
+	assert.Equal(t, int64(11), objectInfo.ContentLength)
+	assert.True(t, objectInfo.MatchesChecksum(ChecksumAlgorithmMD5, "XrY7u+Ae7tCTyyK7j1rNww=="))
+
+	objectContent, content := readObject(t, backend, "avatars", "user/david/avatar.txt", lo.ToPtr("bytes=6-"))
+	assert.Equal(t, "world", content)
+	assert.Equal(t, "bytes 6-10/11", lo.FromPtr(objectContent.ContentRange))
+
+	err = backend.CopyObject(ctx, &ObjectCopy{SourceBucket: "avatars", SourceName: "user/david/avatar.txt", DestinationBucket: TrashBucket("avatars"), DestinationName: "object_01HPG4GN5JY2Z6S0638ERSG375", ContentType: lo.ToPtr("text/markdown")})
+	require.NoError(t, err)
+
+	objectContent, content = readObject(t, backend, TrashBucket("avatars"), "object_01HPG4GN5JY2Z6S0638ERSG375", nil)
+	assert.Equal(t, "hello world", content)
+	assert.Equal(t, "text/markdown", objectContent.ContentType)
+
+	deleteErrors, err := backend.DeleteObjects(ctx, &ObjectsDelete{Bucket: "avatars", Names: []string{"user/david/avatar.txt", "missing.txt"}})
+	require.NoError(t, err)
+	assert.Empty(t, deleteErrors)
+
+	exists, err := backend.CheckIfObjectExists(ctx, &ObjectExistsCheck{Bucket: "avatars", Name: "user/david/avatar.txt"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = backend.HeadObject(ctx, &ObjectHead{Bucket: "avatars", Name: "user/david/avatar.txt"})
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	err = backend.UploadObject(ctx, &ObjectUpload{Bucket: "avatars", Name: "user/../escape.txt", ContentType: "text/plain", Content: strings.NewReader("escape")})
+	assert.ErrorIs(t, err, ErrInvalidObjectName)
+}
+
+func TestFilesystemBackend_ListObjects(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestFilesystemBackend(t)
+
+	for _, name := range []string{"docs/b/c.txt", "docs/a.txt", "docs-archive.txt", "docs/b-d.txt", "other.txt"} {
+		err := backend.UploadObject(ctx, &ObjectUpload{Bucket: "avatars", Name: name, ContentType: "text/plain", Content: strings.NewReader(name)})
+		require.NoError(t, err)
+	}
+
+	listedObjects, err := backend.ListObjects(ctx, &ObjectsList{Bucket: "avatars", Prefix: "docs", MaxKeys: 2})
+	require.NoError(t, err)
+	require.Len(t, listedObjects, 2)
+	assert.Equal(t, "docs-archive.txt", listedObjects[0].Name)
+	assert.Equal(t, "docs/a.txt", listedObjects[1].Name)
+	assert.Equal(t, int64(10), listedObjects[1].Size)
+
+	listedObjects, err = backend.ListObjects(ctx, &ObjectsList{Bucket: "avatars", Prefix: "docs", StartAfter: "docs/a.txt", MaxKeys: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/b-d.txt", "docs/b/c.txt"}, lo.Map(listedObjects, func(object *ListedObject, _ int) string { return object.Name }))
+
+	listedObjects, err = backend.ListObjects(ctx, &ObjectsList{Bucket: "avatars", Prefix: "docs/b", MaxKeys: 10})
+	require.NoError(t, err)
+	assert.Len(t, listedObjects, 2)
+
+	listedObjects, err = backend.ListObjects(ctx, &ObjectsList{Bucket: "avatars", Prefix: "../", MaxKeys: 10})
+	require.NoError(t, err)
+	assert.Empty(t, listedObjects)
+
+	listedObjects, err = backend.ListObjects(ctx, &ObjectsList{Bucket: "videos", MaxKeys: 10})
+	require.NoError(t, err)
+	assert.Empty(t, listedObjects)
+}
+
+func TestFilesystemBackend_SignedUpload(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestFilesystemBackend(t)
+
+	checksum := sha256.Sum256([]byte("hello world"))
+
+	preSignedObject, err := backend.CreatePreSignedUploadObject(ctx, &PreSignedUploadObjectCreate{
+		Bucket:            "avatars",
+		Name:              "avatar.txt",
+		ContentType:       "text/plain",
+		ContentLength:     11,
+		ChecksumAlgorithm: lo.ToPtr(ChecksumAlgorithmSHA256),
+		Checksum:          lo.ToPtr(base64.StdEncoding.EncodeToString(checksum[:])),
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(preSignedObject.Url, "http://localhost:3001/signed/avatars/avatar.txt?"))
+
+	signedRequest := signedRequestFromUrl(t, preSignedObject.Method, preSignedObject.Url)
+
+	_, err = backend.UploadSignedObject(ctx, signedRequest, &SignedUpload{ContentType: "text/plain", Content: strings.NewReader("hello there")})
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// an unbounded body fails as soon as it exceeds the signed content length
+	_, err = backend.UploadSignedObject(ctx, signedRequest, &SignedUpload{ContentType: "text/plain", Content: io.MultiReader(strings.NewReader("hello world"), rand.Reader)})
+	assert.ErrorIs(t, err, ErrContentLengthMismatch)
+
+	_, err = backend.UploadSignedObject(ctx, signedRequest, &SignedUpload{ContentType: "text/html", Content: strings.NewReader("hello world")})
+	assert.ErrorIs(t, err, ErrContentTypeMismatch)
+
+	tamperedRequest := *signedRequest
+	tamperedRequest.Name = "other.txt"
+	_, err = backend.UploadSignedObject(ctx, &tamperedRequest, &SignedUpload{ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	assert.ErrorIs(t, err, ErrSignedUrlInvalid)
+
+	expiredRequest := *signedRequest
+	expiredRequest.ExpiresAt = 1
+	expiredRequest.Signature = backend.sign(&expiredRequest)
+	_, err = backend.UploadSignedObject(ctx, &expiredRequest, &SignedUpload{ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	assert.ErrorIs(t, err, ErrSignedUrlExpired)
+
+	_, err = backend.UploadSignedObject(ctx, signedRequest, &SignedUpload{ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	require.NoError(t, err)
+
+	preSignedObject, err = backend.CreatePreSignedDownloadObject(ctx, &PreSignedDownloadObjectCreate{Bucket: "avatars", Name: "avatar.txt"})
+	require.NoError(t, err)
+
+	_, err = backend.UploadSignedObject(ctx, signedRequestFromUrl(t, "PUT", preSignedObject.Url), &SignedUpload{ContentType: "text/plain", Content: strings.NewReader("replaced")})
+	assert.ErrorIs(t, err, ErrSignedUrlInvalid)
+
+	objectContent, err := backend.DownloadSignedObject(ctx, signedRequestFromUrl(t, preSignedObject.Method, preSignedObject.Url), nil)
+	require.NoError(t, err)
+	defer objectContent.Content.Close()
+
+	content, err := io.ReadAll(objectContent.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+}
+
+func TestFilesystemBackend_MultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestFilesystemBackend(t)
+
+	uploadId, err := backend.CreateMultipartUpload(ctx, &MultipartUploadCreate{Bucket: "videos", Name: "video.txt", ContentType: "text/plain"})
+	require.NoError(t, err)
+
+	var completedParts []*CompletedPart
+	for partNumber, part := range []string{"hello ", "world"} {
+		preSignedObject, err := backend.CreatePreSignedUploadPart(ctx, &PreSignedUploadPartCreate{Bucket: "videos", Name: "video.txt", UploadId: uploadId, PartNumber: int32(partNumber + 1)})
+		require.NoError(t, err)
+
+		etag, err := backend.UploadSignedObject(ctx, signedRequestFromUrl(t, preSignedObject.Method, preSignedObject.Url), &SignedUpload{Content: strings.NewReader(part)})
+		require.NoError(t, err)
+
+		completedParts = append(completedParts, &CompletedPart{PartNumber: int32(partNumber + 1), ETag: etag})
+	}
+
+	uploadedParts, err := backend.ListUploadedParts(ctx, &UploadedPartsList{Bucket: "videos", Name: "video.txt", UploadId: uploadId})
+	require.NoError(t, err)
+	assert.Len(t, uploadedParts, 2)
+	assert.Equal(t, int64(6), uploadedParts[0].Size)
+
+	err = backend.CompleteMultipartUpload(ctx, &MultipartUploadComplete{Bucket: "videos", Name: "video.txt", UploadId: uploadId, Parts: completedParts})
+	require.NoError(t, err)
+
+	objectContent, content := readObject(t, backend, "videos", "video.txt", nil)
+	assert.Equal(t, "hello world", content)
+	assert.True(t, strings.HasSuffix(objectContent.ETag, `-2"`))
+
+	_, err = backend.ListUploadedParts(ctx, &UploadedPartsList{Bucket: "videos", Name: "video.txt", UploadId: uploadId})
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	err = backend.AbortMultipartUpload(ctx, &MultipartUploadAbort{Bucket: "videos", Name: "video.txt", UploadId: uploadId})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,702 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/samber/lo"
+)
+
+// memoryPreSignedUrlScheme is the scheme of the pre-signed urls of the memory backend, they can only be used with
+// MemoryBackend.UploadPreSigned and MemoryBackend.DownloadPreSigned
+const memoryPreSignedUrlScheme = "memory"
+
+// MemoryFault makes the matching operations of a MemoryBackend wait for Latency and then fail with Err, a fault
+// without an error only adds latency. Operation is the name of a method of the backend like "GetObject", an empty
+// operation, bucket or name matches any. Times limits how often the fault is applied, zero applies it until the
+// faults are cleared.
+//
+// a DeleteObjects fault without a name fails the whole call, a fault with a name only fails the deletion of that
+// object and is reported as an ObjectDeleteError like s3 reports objects it failed to delete
+type MemoryFault struct {
+	Operation string
+	Bucket    string
+	Name      string
+	Err       error
+	Latency   time.Duration
+	Times     int
+}
+
+// MemoryBackend keeps objects in memory. it behaves like the s3 backend, including multipart uploads, head semantics
+// and enforcing the content type, length and checksum of pre-signed uploads, so services and workers can be tested
+// in-process. pre-signed urls are simulated, a client request to one is made with UploadPreSigned or DownloadPreSigned.
+// failures and latency can be injected with InjectFault
+type MemoryBackend struct {
+	mu                sync.Mutex
+	objects           map[string]*memoryObject
+	uploads           map[string]*memoryUpload
+	preSignedRequests map[string]*SignedRequest
+	faults            []*memoryFault
+	config            *config.Config
+}
+
+type memoryObject struct {
+	content        []byte
+	contentType    string
+	etag           string
+	checksumSHA256 string
+	checksumCRC32C string
+	lastModified   time.Time
+}
+
+type memoryUpload struct {
+	bucket      string
+	name        string
+	contentType string
+	parts       map[int32]*memoryPart
+}
+
+type memoryPart struct {
+	content      []byte
+	etag         string
+	lastModified time.Time
+}
+
+type memoryFault struct {
+	MemoryFault
+	applied int
+}
+
+var _ Backend = (*MemoryBackend)(nil)
+
+func NewMemoryBackend(config *config.Config) *MemoryBackend {
+	return &MemoryBackend{
+		objects:           make(map[string]*memoryObject),
+		uploads:           make(map[string]*memoryUpload),
+		preSignedRequests: make(map[string]*SignedRequest),
+		config:            config,
+	}
+}
+
+// InjectFault adds a fault, faults are matched in the order they were added
+func (m *MemoryBackend) InjectFault(fault MemoryFault) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.faults = append(m.faults, &memoryFault{MemoryFault: fault})
+}
+
+// ClearFaults removes all faults
+func (m *MemoryBackend) ClearFaults() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.faults = nil
+}
+
+// ObjectNames returns the sorted names of the objects of a bucket
+func (m *MemoryBackend) ObjectNames(bucket string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var names []string
+	for key := range m.objects {
+		if name, ok := strings.CutPrefix(key, bucket+"/"); ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func (m *MemoryBackend) UploadObject(ctx context.Context, objectUpload *ObjectUpload) error {
+	if err := m.fault(ctx, "UploadObject", objectUpload.Bucket, objectUpload.Name); err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	digest, err := digestContent(&content, objectUpload.Content)
+	if err != nil {
+		return err
+	}
+
+	m.putObject(objectUpload.Bucket, objectUpload.Name, content.Bytes(), objectUpload.ContentType, digest, digest.etag())
+
+	return nil
+}
+
+func (m *MemoryBackend) CreatePreSignedUploadObject(ctx context.Context, preSignedUploadObjectCreate *PreSignedUploadObjectCreate) (*PreSignedObject, error) {
+	if err := m.fault(ctx, "CreatePreSignedUploadObject", preSignedUploadObjectCreate.Bucket, preSignedUploadObjectCreate.Name); err != nil {
+		return nil, err
+	}
+
+	signedRequest := &SignedRequest{
+		Method:        http.MethodPut,
+		Bucket:        preSignedUploadObjectCreate.Bucket,
+		Name:          preSignedUploadObjectCreate.Name,
+		ExpiresAt:     time.Now().Add(preSignedExpiresIn(preSignedUploadObjectCreate.ExpiresIn, m.config.DefaultPreSignedUploadUrlExpiry)).Unix(),
+		ContentType:   &preSignedUploadObjectCreate.ContentType,
+		ContentLength: &preSignedUploadObjectCreate.ContentLength,
+	}
+
+	if preSignedUploadObjectCreate.ChecksumAlgorithm != nil && preSignedUploadObjectCreate.Checksum != nil {
+		switch *preSignedUploadObjectCreate.ChecksumAlgorithm {
+		case ChecksumAlgorithmSHA256, ChecksumAlgorithmCRC32C, ChecksumAlgorithmMD5:
+		default:
+			return nil, fmt.Errorf("unsupported checksum algorithm '%s'", *preSignedUploadObjectCreate.ChecksumAlgorithm)
+		}
+		signedRequest.ChecksumAlgorithm = preSignedUploadObjectCreate.ChecksumAlgorithm
+		signedRequest.Checksum = preSignedUploadObjectCreate.Checksum
+	}
+
+	preSignedObject, err := m.preSign(signedRequest)
+	if err != nil {
+		return nil, err
+	}
+	preSignedObject.Headers = map[string]string{
+		"Content-Type": preSignedUploadObjectCreate.ContentType,
+	}
+
+	return preSignedObject, nil
+}
+
+func (m *MemoryBackend) CreatePreSignedDownloadObject(ctx context.Context, preSignedDownloadObjectCreate *PreSignedDownloadObjectCreate) (*PreSignedObject, error) {
+	if err := m.fault(ctx, "CreatePreSignedDownloadObject", preSignedDownloadObjectCreate.Bucket, preSignedDownloadObjectCreate.Name); err != nil {
+		return nil, err
+	}
+
+	return m.preSign(&SignedRequest{
+		Method:    http.MethodGet,
+		Bucket:    preSignedDownloadObjectCreate.Bucket,
+		Name:      preSignedDownloadObjectCreate.Name,
+		ExpiresAt: time.Now().Add(preSignedExpiresIn(preSignedDownloadObjectCreate.ExpiresIn, m.config.DefaultPreSignedDownloadUrlExpiry)).Unix(),
+	})
+}
+
+func (m *MemoryBackend) CreateMultipartUpload(ctx context.Context, multipartUploadCreate *MultipartUploadCreate) (string, error) {
+	if err := m.fault(ctx, "CreateMultipartUpload", multipartUploadCreate.Bucket, multipartUploadCreate.Name); err != nil {
+		return "", err
+	}
+
+	uploadId, err := randomMemoryId()
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.uploads[uploadId] = &memoryUpload{
+		bucket:      multipartUploadCreate.Bucket,
+		name:        multipartUploadCreate.Name,
+		contentType: multipartUploadCreate.ContentType,
+		parts:       make(map[int32]*memoryPart),
+	}
+
+	return uploadId, nil
+}
+
+func (m *MemoryBackend) CreatePreSignedUploadPart(ctx context.Context, preSignedUploadPartCreate *PreSignedUploadPartCreate) (*PreSignedObject, error) {
+	if err := m.fault(ctx, "CreatePreSignedUploadPart", preSignedUploadPartCreate.Bucket, preSignedUploadPartCreate.Name); err != nil {
+		return nil, err
+	}
+
+	return m.preSign(&SignedRequest{
+		Method:     http.MethodPut,
+		Bucket:     preSignedUploadPartCreate.Bucket,
+		Name:       preSignedUploadPartCreate.Name,
+		ExpiresAt:  time.Now().Add(preSignedExpiresIn(preSignedUploadPartCreate.ExpiresIn, m.config.DefaultPreSignedUploadUrlExpiry)).Unix(),
+		UploadId:   &preSignedUploadPartCreate.UploadId,
+		PartNumber: &preSignedUploadPartCreate.PartNumber,
+	})
+}
+
+func (m *MemoryBackend) ListUploadedParts(ctx context.Context, uploadedPartsList *UploadedPartsList) ([]*UploadedPart, error) {
+	if err := m.fault(ctx, "ListUploadedParts", uploadedPartsList.Bucket, uploadedPartsList.Name); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	upload, err := m.upload(uploadedPartsList.Bucket, uploadedPartsList.Name, uploadedPartsList.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	var uploadedParts []*UploadedPart
+	for partNumber, part := range upload.parts {
+		uploadedParts = append(uploadedParts, &UploadedPart{
+			PartNumber:   partNumber,
+			ETag:         part.etag,
+			Size:         int64(len(part.content)),
+			LastModified: part.lastModified,
+		})
+	}
+
+	slices.SortFunc(uploadedParts, func(a *UploadedPart, b *UploadedPart) int {
+		return int(a.PartNumber - b.PartNumber)
+	})
+
+	return uploadedParts, nil
+}
+
+func (m *MemoryBackend) CompleteMultipartUpload(ctx context.Context, multipartUploadComplete *MultipartUploadComplete) error {
+	if err := m.fault(ctx, "CompleteMultipartUpload", multipartUploadComplete.Bucket, multipartUploadComplete.Name); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+
+	upload, err := m.upload(multipartUploadComplete.Bucket, multipartUploadComplete.Name, multipartUploadComplete.UploadId)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+
+	if len(multipartUploadComplete.Parts) == 0 {
+		m.mu.Unlock()
+		return ErrInvalidPart
+	}
+
+	parts := make([]io.Reader, 0, len(multipartUploadComplete.Parts))
+	partETags := make([]string, 0, len(multipartUploadComplete.Parts))
+
+	for i, completedPart := range multipartUploadComplete.Parts {
+		part, ok := upload.parts[completedPart.PartNumber]
+		if !ok || strings.Trim(part.etag, `"`) != strings.Trim(completedPart.ETag, `"`) {
+			m.mu.Unlock()
+			return ErrInvalidPart
+		}
+		if i > 0 && completedPart.PartNumber <= multipartUploadComplete.Parts[i-1].PartNumber {
+			m.mu.Unlock()
+			return ErrInvalidPart
+		}
+
+		parts = append(parts, bytes.NewReader(part.content))
+		partETags = append(partETags, part.etag)
+	}
+
+	delete(m.uploads, multipartUploadComplete.UploadId)
+
+	m.mu.Unlock()
+
+	etag, err := multipartETag(partETags)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	digest, err := digestContent(&content, io.MultiReader(parts...))
+	if err != nil {
+		return err
+	}
+
+	m.putObject(upload.bucket, upload.name, content.Bytes(), upload.contentType, digest, etag)
+
+	return nil
+}
+
+func (m *MemoryBackend) AbortMultipartUpload(ctx context.Context, multipartUploadAbort *MultipartUploadAbort) error {
+	if err := m.fault(ctx, "AbortMultipartUpload", multipartUploadAbort.Bucket, multipartUploadAbort.Name); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.upload(multipartUploadAbort.Bucket, multipartUploadAbort.Name, multipartUploadAbort.UploadId); err == nil {
+		delete(m.uploads, multipartUploadAbort.UploadId)
+	}
+
+	return nil
+}
+
+func (m *MemoryBackend) CheckIfObjectExists(ctx context.Context, objectExistsCheck *ObjectExistsCheck) (bool, error) {
+	if err := m.fault(ctx, "CheckIfObjectExists", objectExistsCheck.Bucket, objectExistsCheck.Name); err != nil {
+		return false, err
+	}
+
+	_, err := m.object(objectExistsCheck.Bucket, objectExistsCheck.Name)
+
+	return err == nil, nil
+}
+
+func (m *MemoryBackend) HeadObject(ctx context.Context, objectHead *ObjectHead) (*ObjectInfo, error) {
+	if err := m.fault(ctx, "HeadObject", objectHead.Bucket, objectHead.Name); err != nil {
+		return nil, err
+	}
+
+	object, err := m.object(objectHead.Bucket, objectHead.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		ContentType:    object.contentType,
+		ContentLength:  int64(len(object.content)),
+		ETag:           object.etag,
+		LastModified:   object.lastModified,
+		ChecksumSHA256: object.checksumSHA256,
+		ChecksumCRC32C: object.checksumCRC32C,
+	}, nil
+}
+
+func (m *MemoryBackend) DetectContentType(ctx context.Context, objectContentTypeDetection *ObjectContentTypeDetection) (string, error) {
+	if err := m.fault(ctx, "DetectContentType", objectContentTypeDetection.Bucket, objectContentTypeDetection.Name); err != nil {
+		return "", err
+	}
+
+	object, err := m.object(objectContentTypeDetection.Bucket, objectContentTypeDetection.Name)
+	if err != nil {
+		return "", err
+	}
+
+	return detectContentType(bytes.NewReader(object.content))
+}
+
+func (m *MemoryBackend) GetObject(ctx context.Context, objectGet *ObjectGet) (*ObjectContent, error) {
+	if err := m.fault(ctx, "GetObject", objectGet.Bucket, objectGet.Name); err != nil {
+		return nil, err
+	}
+
+	return m.getObject(objectGet.Bucket, objectGet.Name, objectGet.Range)
+}
+
+func (m *MemoryBackend) CopyObject(ctx context.Context, objectCopy *ObjectCopy) error {
+	if err := m.fault(ctx, "CopyObject", objectCopy.SourceBucket, objectCopy.SourceName); err != nil {
+		return err
+	}
+
+	source, err := m.object(objectCopy.SourceBucket, objectCopy.SourceName)
+	if err != nil {
+		return err
+	}
+
+	destination := *source
+	destination.contentType = lo.FromPtrOr(objectCopy.ContentType, source.contentType)
+	destination.lastModified = time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[createS3Key(objectCopy.DestinationBucket, objectCopy.DestinationName)] = &destination
+
+	return nil
+}
+
+func (m *MemoryBackend) DeleteObject(ctx context.Context, objectDelete *ObjectDelete) error {
+	if err := m.fault(ctx, "DeleteObject", objectDelete.Bucket, objectDelete.Name); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.objects, createS3Key(objectDelete.Bucket, objectDelete.Name))
+
+	return nil
+}
+
+func (m *MemoryBackend) DeleteObjects(ctx context.Context, objectsDelete *ObjectsDelete) ([]*ObjectDeleteError, error) {
+	if err := m.fault(ctx, "DeleteObjects", objectsDelete.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	var deleteErrors []*ObjectDeleteError
+
+	for _, name := range objectsDelete.Names {
+		if fault := m.objectFault("DeleteObjects", objectsDelete.Bucket, name); fault != nil {
+			deleteErrors = append(deleteErrors, &ObjectDeleteError{
+				Name:    name,
+				Code:    "InternalError",
+				Message: fault.Error(),
+			})
+			continue
+		}
+
+		m.mu.Lock()
+		delete(m.objects, createS3Key(objectsDelete.Bucket, name))
+		m.mu.Unlock()
+	}
+
+	return deleteErrors, nil
+}
+
+func (m *MemoryBackend) ListObjects(ctx context.Context, objectsList *ObjectsList) ([]*ListedObject, error) {
+	if err := m.fault(ctx, "ListObjects", objectsList.Bucket, ""); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var listedObjects []*ListedObject
+	for key, object := range m.objects {
+		name, ok := strings.CutPrefix(key, createS3Key(objectsList.Bucket, ""))
+		if !ok || !strings.HasPrefix(name, objectsList.Prefix) || name <= objectsList.StartAfter {
+			continue
+		}
+		listedObjects = append(listedObjects, &ListedObject{
+			Name:         name,
+			Size:         int64(len(object.content)),
+			ETag:         object.etag,
+			LastModified: object.lastModified,
+		})
+	}
+
+	slices.SortFunc(listedObjects, func(a *ListedObject, b *ListedObject) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return listedObjects[:min(len(listedObjects), int(objectsList.MaxKeys))], nil
+}
+
+// UploadPreSigned sends content to a pre-signed upload object or upload part url like a client would and returns
+// the etag of the content. the request fails like s3 fails it when the url expired or the content does not match
+// the content type, length or checksum the url was signed with
+func (m *MemoryBackend) UploadPreSigned(ctx context.Context, preSignedUrl string, contentType string, content io.Reader) (string, error) {
+	signedRequest, err := m.preSignedRequest(preSignedUrl, http.MethodPut)
+	if err != nil {
+		return "", err
+	}
+
+	if err = m.fault(ctx, "UploadPreSigned", signedRequest.Bucket, signedRequest.Name); err != nil {
+		return "", err
+	}
+
+	if signedRequest.ContentType != nil && *signedRequest.ContentType != contentType {
+		return "", ErrContentTypeMismatch
+	}
+
+	var buffer bytes.Buffer
+	digest, err := digestContent(&buffer, limitSignedContent(signedRequest, content))
+	if err != nil {
+		return "", err
+	}
+
+	err = verifySignedDigest(signedRequest, digest)
+	if err != nil {
+		return "", err
+	}
+
+	if signedRequest.UploadId == nil || signedRequest.PartNumber == nil {
+		m.putObject(signedRequest.Bucket, signedRequest.Name, buffer.Bytes(), contentType, digest, digest.etag())
+		return digest.etag(), nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	upload, err := m.upload(signedRequest.Bucket, signedRequest.Name, *signedRequest.UploadId)
+	if err != nil {
+		return "", err
+	}
+
+	upload.parts[*signedRequest.PartNumber] = &memoryPart{
+		content:      buffer.Bytes(),
+		etag:         digest.etag(),
+		lastModified: time.Now(),
+	}
+
+	return digest.etag(), nil
+}
+
+// DownloadPreSigned gets the content of the object of a pre-signed download url like a client would
+func (m *MemoryBackend) DownloadPreSigned(ctx context.Context, preSignedUrl string, rangeHeader *string) (*ObjectContent, error) {
+	signedRequest, err := m.preSignedRequest(preSignedUrl, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.fault(ctx, "DownloadPreSigned", signedRequest.Bucket, signedRequest.Name); err != nil {
+		return nil, err
+	}
+
+	return m.getObject(signedRequest.Bucket, signedRequest.Name, rangeHeader)
+}
+
+// fault applies the first fault matching the operation, it waits for the latency of the fault and returns its error
+func (m *MemoryBackend) fault(ctx context.Context, operation string, bucket string, name string) error {
+	m.mu.Lock()
+	fault, ok := lo.Find(m.faults, func(fault *memoryFault) bool {
+		return fault.matches(operation, bucket, name)
+	})
+	if ok {
+		fault.applied++
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return ctx.Err()
+	}
+
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return fault.Err
+}
+
+// objectFault applies the first fault matching the operation for a single object by name and returns its error
+func (m *MemoryBackend) objectFault(operation string, bucket string, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fault, ok := lo.Find(m.faults, func(fault *memoryFault) bool {
+		return fault.Name != "" && fault.Err != nil && fault.matches(operation, bucket, name)
+	})
+	if !ok {
+		return nil
+	}
+	fault.applied++
+
+	return fault.Err
+}
+
+func (f *memoryFault) matches(operation string, bucket string, name string) bool {
+	return (f.Times == 0 || f.applied < f.Times) &&
+		(f.Operation == "" || f.Operation == operation) &&
+		(f.Bucket == "" || f.Bucket == bucket) &&
+		(f.Name == "" || f.Name == name)
+}
+
+func (m *MemoryBackend) object(bucket string, name string) (*memoryObject, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	object, ok := m.objects[createS3Key(bucket, name)]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+
+	return object, nil
+}
+
+func (m *MemoryBackend) putObject(bucket string, name string, content []byte, contentType string, digest *contentDigest, etag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[createS3Key(bucket, name)] = &memoryObject{
+		content:        content,
+		contentType:    contentType,
+		etag:           etag,
+		checksumSHA256: base64.StdEncoding.EncodeToString(digest.sha256),
+		checksumCRC32C: base64.StdEncoding.EncodeToString(digest.crc32c),
+		lastModified:   time.Now(),
+	}
+}
+
+func (m *MemoryBackend) getObject(bucket string, name string, rangeHeader *string) (*ObjectContent, error) {
+	object, err := m.object(bucket, name)
+	if err != nil {
+		return nil, err
+	}
+
+	objectContent := &ObjectContent{
+		ContentType:   object.contentType,
+		ContentLength: int64(len(object.content)),
+		ETag:          object.etag,
+		LastModified:  object.lastModified,
+		Content:       io.NopCloser(bytes.NewReader(object.content)),
+	}
+
+	if rangeHeader == nil {
+		return objectContent, nil
+	}
+
+	start, end, err := parseRange(*rangeHeader, int64(len(object.content)))
+	if err != nil {
+		return nil, err
+	}
+
+	objectContent.ContentLength = end - start + 1
+	objectContent.ContentRange = lo.ToPtr(fmt.Sprintf("bytes %d-%d/%d", start, end, len(object.content)))
+	objectContent.Content = io.NopCloser(bytes.NewReader(object.content[start : end+1]))
+
+	return objectContent, nil
+}
+
+// upload returns a multipart upload of an object, it has to be called with the lock held
+func (m *MemoryBackend) upload(bucket string, name string, uploadId string) (*memoryUpload, error) {
+	upload, ok := m.uploads[uploadId]
+	if !ok || upload.bucket != bucket || upload.name != name {
+		return nil, ErrObjectNotFound
+	}
+	return upload, nil
+}
+
+func (m *MemoryBackend) preSign(signedRequest *SignedRequest) (*PreSignedObject, error) {
+	token, err := randomMemoryId()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.preSignedRequests[token] = signedRequest
+	m.mu.Unlock()
+
+	preSignedUrl := url.URL{
+		Scheme:   memoryPreSignedUrlScheme,
+		Host:     signedRequest.Bucket,
+		Path:     "/" + signedRequest.Name,
+		RawQuery: url.Values{"token": []string{token}}.Encode(),
+	}
+
+	return &PreSignedObject{
+		Url:       preSignedUrl.String(),
+		Method:    signedRequest.Method,
+		ExpiresAt: signedRequest.ExpiresAt,
+	}, nil
+}
+
+// preSignedRequest returns the request a pre-signed url was created for, if it is valid for the method and has
+// not expired
+func (m *MemoryBackend) preSignedRequest(preSignedUrl string, method string) (*SignedRequest, error) {
+	parsedUrl, err := url.Parse(preSignedUrl)
+	if err != nil || parsedUrl.Scheme != memoryPreSignedUrlScheme {
+		return nil, ErrSignedUrlInvalid
+	}
+
+	m.mu.Lock()
+	signedRequest, ok := m.preSignedRequests[parsedUrl.Query().Get("token")]
+	m.mu.Unlock()
+
+	if !ok || signedRequest.Method != method {
+		return nil, ErrSignedUrlInvalid
+	}
+
+	if time.Now().Unix() > signedRequest.ExpiresAt {
+		return nil, ErrSignedUrlExpired
+	}
+
+	return signedRequest, nil
+}
+
+func randomMemoryId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
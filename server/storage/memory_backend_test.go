@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryBackend() *MemoryBackend {
+	return NewMemoryBackend(&config.Config{
+		DefaultPreSignedUploadUrlExpiry:   120,
+		DefaultPreSignedDownloadUrlExpiry: 300,
+	})
+}
+
+func TestMemoryBackend_Objects(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestMemoryBackend()
+
+	_, err := backend.HeadObject(ctx, &ObjectHead{Bucket: "avatars", Name: "avatar.txt"})
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	err = backend.UploadObject(ctx, &ObjectUpload{Bucket: "avatars", Name: "avatar.txt", ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	require.NoError(t, err)
+
+	objectInfo, err := backend.HeadObject(ctx, &ObjectHead{Bucket: "avatars", Name: "avatar.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), objectInfo.ContentLength)
+	assert.Equal(t, `"5eb63bbbe01eeed093cb22bb8f5acdc3"`, objectInfo.ETag)
+	assert.True(t, objectInfo.MatchesChecksum(ChecksumAlgorithmSHA256, "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="))
+
+	contentType, err := backend.DetectContentType(ctx, &ObjectContentTypeDetection{Bucket: "avatars", Name: "avatar.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", contentType)
+
+	objectContent, err := backend.GetObject(ctx, &ObjectGet{Bucket: "avatars", Name: "avatar.txt", Range: lo.ToPtr("bytes=0-4")})
+	require.NoError(t, err)
+	content, err := io.ReadAll(objectContent.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	assert.Equal(t, "bytes 0-4/11", lo.FromPtr(objectContent.ContentRange))
+
+	err = backend.CopyObject(ctx, &ObjectCopy{SourceBucket: "avatars", SourceName: "avatar.txt", DestinationBucket: "avatars", DestinationName: "copy.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avatar.txt", "copy.txt"}, backend.ObjectNames("avatars"))
+
+	err = backend.DeleteObject(ctx, &ObjectDelete{Bucket: "avatars", Name: "avatar.txt"})
+	require.NoError(t, err)
+
+	exists, err := backend.CheckIfObjectExists(ctx, &ObjectExistsCheck{Bucket: "avatars", Name: "avatar.txt"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestMemoryBackend_PreSigned(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestMemoryBackend()
+
+	preSignedObject, err := backend.CreatePreSignedUploadObject(ctx, &PreSignedUploadObjectCreate{
+		Bucket:            "avatars",
+		Name:              "avatar.txt",
+		ContentType:       "text/plain",
+		ContentLength:     11,
+		ChecksumAlgorithm: lo.ToPtr(ChecksumAlgorithmMD5),
+		Checksum:          lo.ToPtr("XrY7u+Ae7tCTyyK7j1rNww=="),
+	})
+	require.NoError(t, err)
+
+	_, err = backend.UploadPreSigned(ctx, preSignedObject.Url, "text/plain", strings.NewReader("hello"))
+	assert.ErrorIs(t, err, ErrContentLengthMismatch)
+
+	_, err = backend.UploadPreSigned(ctx, preSignedObject.Url, "text/plain", strings.NewReader("hello there"))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	etag, err := backend.UploadPreSigned(ctx, preSignedObject.Url, "text/plain", strings.NewReader("hello world"))
+	require.NoError(t, err)
+	assert.Equal(t, `"5eb63bbbe01eeed093cb22bb8f5acdc3"`, etag)
+
+	_, err = backend.DownloadPreSigned(ctx, preSignedObject.Url, nil)
+	assert.ErrorIs(t, err, ErrSignedUrlInvalid)
+
+	preSignedObject, err = backend.CreatePreSignedDownloadObject(ctx, &PreSignedDownloadObjectCreate{Bucket: "avatars", Name: "avatar.txt", ExpiresIn: lo.ToPtr(int64(-1))})
+	require.NoError(t, err)
+
+	_, err = backend.DownloadPreSigned(ctx, preSignedObject.Url, nil)
+	assert.ErrorIs(t, err, ErrSignedUrlExpired)
+
+	preSignedObject, err = backend.CreatePreSignedDownloadObject(ctx, &PreSignedDownloadObjectCreate{Bucket: "avatars", Name: "avatar.txt"})
+	require.NoError(t, err)
+
+	objectContent, err := backend.DownloadPreSigned(ctx, preSignedObject.Url, nil)
+	require.NoError(t, err)
+	content, err := io.ReadAll(objectContent.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+}
+
+func TestMemoryBackend_ListObjects(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestMemoryBackend()
+
+	for _, name := range []string{"docs/b/c.txt", "docs/a.txt", "docs-archive.txt", "docs/b-d.txt", "other.txt"} {
+		err := backend.UploadObject(ctx, &ObjectUpload{Bucket: "avatars", Name: name, ContentType: "text/plain", Content: strings.NewReader(name)})
+		require.NoError(t, err)
+	}
+
+	listedObjects, err := backend.ListObjects(ctx, &ObjectsList{Bucket: "avatars", Prefix: "docs", MaxKeys: 2})
+	require.NoError(t, err)
+	require.Len(t, listedObjects, 2)
+	assert.Equal(t, "docs-archive.txt", listedObjects[0].Name)
+	assert.Equal(t, "docs/a.txt", listedObjects[1].Name)
+	assert.Equal(t, int64(10), listedObjects[1].Size)
+
+	listedObjects, err = backend.ListObjects(ctx, &ObjectsList{Bucket: "avatars", Prefix: "docs", StartAfter: "docs/a.txt", MaxKeys: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/b-d.txt", "docs/b/c.txt"}, lo.Map(listedObjects, func(object *ListedObject, _ int) string { return object.Name }))
+
+	listedObjects, err = backend.ListObjects(ctx, &ObjectsList{Bucket: "avatars", Prefix: "docs/b", MaxKeys: 10})
+	require.NoError(t, err)
+	assert.Len(t, listedObjects, 2)
+
+	listedObjects, err = backend.ListObjects(ctx, &ObjectsList{Bucket: "videos", MaxKeys: 10})
+	require.NoError(t, err)
+	assert.Empty(t, listedObjects)
+}
+
+func TestMemoryBackend_MultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestMemoryBackend()
+
+	uploadId, err := backend.CreateMultipartUpload(ctx, &MultipartUploadCreate{Bucket: "videos", Name: "video.txt", ContentType: "text/plain"})
+	require.NoError(t, err)
+
+	var completedParts []*CompletedPart
+	for partNumber, part := range []string{"hello ", "world"} {
+		preSignedObject, err := backend.CreatePreSignedUploadPart(ctx, &PreSignedUploadPartCreate{Bucket: "videos", Name: "video.txt", UploadId: uploadId, PartNumber: int32(partNumber + 1)})
+		require.NoError(t, err)
+
+		etag, err := backend.UploadPreSigned(ctx, preSignedObject.Url, "", strings.NewReader(part))
+		require.NoError(t, err)
+
+		completedParts = append(completedParts, &CompletedPart{PartNumber: int32(partNumber + 1), ETag: etag})
+	}
+
+	uploadedParts, err := backend.ListUploadedParts(ctx, &UploadedPartsList{Bucket: "videos", Name: "video.txt", UploadId: uploadId})
+	require.NoError(t, err)
+	assert.Len(t, uploadedParts, 2)
+
+	err = backend.CompleteMultipartUpload(ctx, &MultipartUploadComplete{Bucket: "videos", Name: "video.txt", UploadId: uploadId, Parts: lo.Reverse(slices.Clone(completedParts))})
+	assert.ErrorIs(t, err, ErrInvalidPart)
+
+	err = backend.CompleteMultipartUpload(ctx, &MultipartUploadComplete{Bucket: "videos", Name: "video.txt", UploadId: uploadId, Parts: completedParts})
+	require.NoError(t, err)
+
+	objectInfo, err := backend.HeadObject(ctx, &ObjectHead{Bucket: "videos", Name: "video.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), objectInfo.ContentLength)
+	assert.True(t, strings.HasSuffix(objectInfo.ETag, `-2"`))
+	assert.True(t, objectInfo.MatchesChecksum(ChecksumAlgorithmMD5, "XrY7u+Ae7tCTyyK7j1rNww=="))
+
+	_, err = backend.ListUploadedParts(ctx, &UploadedPartsList{Bucket: "videos", Name: "video.txt", UploadId: uploadId})
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestMemoryBackend_Faults(t *testing.T) {
+	ctx := context.Background()
+	backend := newTestMemoryBackend()
+
+	errUnavailable := errors.New("service unavailable")
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		err := backend.UploadObject(ctx, &ObjectUpload{Bucket: "docs", Name: name, ContentType: "text/plain", Content: strings.NewReader(name)})
+		require.NoError(t, err)
+	}
+
+	backend.InjectFault(MemoryFault{Operation: "HeadObject", Err: errUnavailable, Times: 1})
+
+	_, err := backend.HeadObject(ctx, &ObjectHead{Bucket: "docs", Name: "a.txt"})
+	assert.ErrorIs(t, err, errUnavailable)
+
+	_, err = backend.HeadObject(ctx, &ObjectHead{Bucket: "docs", Name: "a.txt"})
+	assert.NoError(t, err)
+
+	backend.InjectFault(MemoryFault{Operation: "DeleteObjects", Name: "b.txt", Err: errUnavailable})
+
+	deleteErrors, err := backend.DeleteObjects(ctx, &ObjectsDelete{Bucket: "docs", Names: []string{"a.txt", "b.txt", "c.txt"}})
+	require.NoError(t, err)
+	require.Len(t, deleteErrors, 1)
+	assert.Equal(t, "b.txt", deleteErrors[0].Name)
+	assert.Equal(t, []string{"b.txt"}, backend.ObjectNames("docs"))
+
+	backend.ClearFaults()
+	backend.InjectFault(MemoryFault{Operation: "GetObject", Latency: time.Second})
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err = backend.GetObject(timeoutCtx, &ObjectGet{Bucket: "docs", Name: "b.txt"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/driftdev/storage/server/config"
+	"go.uber.org/zap"
+)
+
+var ErrBackendNotFound = errors.New("storage backend not found")
+
+// Registry holds the storage backends declared in the config by name. every bucket keeps its objects in one of them,
+// the default backend is the one configured by the top level storage settings
+type Registry struct {
+	backends map[string]Backend
+}
+
+// NewRegistry creates the default backend and every named backend of the config
+func NewRegistry(cfg *config.Config, logger *zap.Logger) (*Registry, error) {
+	backends := make(map[string]Backend)
+
+	for _, name := range cfg.StorageBackendNames() {
+		backendConfig, _ := cfg.StorageBackendConfig(name)
+
+		backend, err := NewBackend(backendConfig, logger.With(zap.String("storage_backend", name)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create storage backend %s: %w", name, err)
+		}
+
+		backends[name] = backend
+	}
+
+	return &Registry{backends: backends}, nil
+}
+
+// NewRegistryOf creates a registry of the given backends by name, which lets services and workers run against
+// in-memory backends in tests
+func NewRegistryOf(backends map[string]Backend) *Registry {
+	return &Registry{backends: backends}
+}
+
+// Backend returns the backend with the given name or ErrBackendNotFound when the config does not declare it
+func (r *Registry) Backend(name string) (Backend, error) {
+	backend, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBackendNotFound, name)
+	}
+	return backend, nil
+}
+
+// HasBackend reports whether the config declares a backend with the given name
+func (r *Registry) HasBackend(name string) bool {
+	_, ok := r.backends[name]
+	return ok
+}
+
+// HasFilesystemBackend reports whether any backend is a filesystem backend, whose signed urls are served by the server
+func (r *Registry) HasFilesystemBackend() bool {
+	for _, backend := range r.backends {
+		if _, ok := backend.(*FilesystemBackend); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsEncryption reports whether the backend with the given name applies server-side encryption. only s3 backends
+// do, the other backends ignore the encryption of requests
+func (r *Registry) SupportsEncryption(name string) bool {
+	_, ok := r.backends[name].(*S3Backend)
+	return ok
+}
+
+// CopyObjectBetween copies an object from one backend to another. copies within a backend use the copy of the
+// backend, copies across backends stream the content of the source through the server
+func CopyObjectBetween(ctx context.Context, source Backend, destination Backend, objectCopy *ObjectCopy) error {
+	if source == destination {
+		return source.CopyObject(ctx, objectCopy)
+	}
+
+	objectContent, err := source.GetObject(ctx, &ObjectGet{
+		Bucket:     objectCopy.SourceBucket,
+		Name:       objectCopy.SourceName,
+		Encryption: objectCopy.SourceEncryption,
+	})
+	if err != nil {
+		return err
+	}
+	defer objectContent.Content.Close()
+
+	contentType := objectContent.ContentType
+	if objectCopy.ContentType != nil {
+		contentType = *objectCopy.ContentType
+	}
+
+	return destination.UploadObject(ctx, &ObjectUpload{
+		Bucket:      objectCopy.DestinationBucket,
+		Name:        objectCopy.DestinationName,
+		ContentType: contentType,
+		Content:     objectContent.Content,
+		Encryption:  objectCopy.Encryption,
+	})
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/driftdev/storage/server/config"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewRegistry(t *testing.T) {
+	registry, err := NewRegistry(&config.Config{
+		StorageBackend:       config.StorageBackendFilesystem,
+		FilesystemRoot:       t.TempDir(),
+		FilesystemSigningKey: "01HPG7BZW4HDEHWPS0FT50N3NX",
+		StorageBackends: map[string]config.StorageBackendConfig{
+			"eu": {FilesystemRoot: t.TempDir()},
+		},
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.True(t, registry.HasBackend(config.DefaultStorageBackend))
+	assert.True(t, registry.HasBackend("eu"))
+	assert.True(t, registry.HasFilesystemBackend())
+	assert.False(t, registry.SupportsEncryption(config.DefaultStorageBackend))
+
+	defaultBackend, err := registry.Backend(config.DefaultStorageBackend)
+	require.NoError(t, err)
+	euBackend, err := registry.Backend("eu")
+	require.NoError(t, err)
+	assert.NotSame(t, defaultBackend, euBackend)
+
+	_, err = registry.Backend("us")
+	assert.ErrorIs(t, err, ErrBackendNotFound)
+}
+
+func TestCopyObjectBetween(t *testing.T) {
+	ctx := context.Background()
+	source := newTestMemoryBackend()
+	destination := newTestMemoryBackend()
+
+	err := source.UploadObject(ctx, &ObjectUpload{Bucket: "avatars", Name: "avatar.txt", ContentType: "text/plain", Content: strings.NewReader("hello world")})
+	require.NoError(t, err)
+
+	err = CopyObjectBetween(ctx, source, source, &ObjectCopy{SourceBucket: "avatars", SourceName: "avatar.txt", DestinationBucket: "avatars", DestinationName: "copy.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avatar.txt", "copy.txt"}, source.ObjectNames("avatars"))
+
+	err = CopyObjectBetween(ctx, source, destination, &ObjectCopy{SourceBucket: "avatars", SourceName: "avatar.txt", DestinationBucket: "archive", DestinationName: "avatar.md", ContentType: lo.ToPtr("text/markdown")})
+	require.NoError(t, err)
+	assert.Empty(t, destination.ObjectNames("avatars"))
+
+	objectContent, err := destination.GetObject(ctx, &ObjectGet{Bucket: "archive", Name: "avatar.md"})
+	require.NoError(t, err)
+	content, err := io.ReadAll(objectContent.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+	assert.Equal(t, "text/markdown", objectContent.ContentType)
+
+	err = CopyObjectBetween(ctx, source, destination, &ObjectCopy{SourceBucket: "avatars", SourceName: "missing.txt", DestinationBucket: "archive", DestinationName: "missing.txt"})
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
@@ -0,0 +1,853 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/driftdev/storage/server/config"
+	"github.com/driftdev/storage/server/zapfield"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// objectUploadPartSize is the size of each part streamed to s3 when uploading an object through the server.
+// s3 requires every part except the last one to be at least 5 MiB
+const objectUploadPartSize = 8 * 1024 * 1024
+
+// objectCopyMaxSize is the largest object s3 can copy with a single copy object call.
+// larger objects are copied part by part with a multipart upload
+const objectCopyMaxSize = 5 * 1024 * 1024 * 1024
+
+// objectCopyPartSize is the size of each part copied when copying an object with a multipart upload
+const objectCopyPartSize = 512 * 1024 * 1024
+
+// objectCopyMaxPartCount is the maximum number of parts s3 allows in a multipart upload
+const objectCopyMaxPartCount = 10000
+
+// objectDeleteBatchSize is the maximum number of objects s3 deletes with a single delete objects call
+const objectDeleteBatchSize = 1000
+
+type S3Backend struct {
+	s3Client          *s3.Client
+	s3PreSignedClient *s3.PresignClient
+	bucket            string
+	config            *config.Config
+	logger            *zap.Logger
+}
+
+var _ Backend = (*S3Backend)(nil)
+
+// NewS3Backend creates a backend that keeps all buckets in the single s3 bucket of the config under the bucket name
+func NewS3Backend(config *config.Config, logger *zap.Logger) (*S3Backend, error) {
+	s3Config, err := awsConfig.LoadDefaultConfig(
+		context.Background(),
+		awsConfig.WithRegion(config.S3Region),
+		awsConfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(config.S3AccessKeyId, config.S3SecretAccessKey, ""),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Client := s3.NewFromConfig(
+		s3Config,
+		func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(config.S3Endpoint)
+			o.UsePathStyle = config.S3ForcePathStyle
+			o.EndpointOptions.DisableHTTPS = config.S3DisableSSL
+		},
+	)
+
+	return &S3Backend{
+		s3Client:          s3Client,
+		s3PreSignedClient: s3.NewPresignClient(s3Client),
+		bucket:            config.S3Bucket,
+		config:            config,
+		logger:            logger,
+	}, nil
+}
+
+func (s *S3Backend) UploadObject(ctx context.Context, objectUpload *ObjectUpload) error {
+	const op = "S3Backend.UploadObject"
+
+	key := createS3Key(objectUpload.Bucket, objectUpload.Name)
+
+	part := make([]byte, objectUploadPartSize)
+
+	n, err := io.ReadFull(objectUpload.Content, part)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		s.logger.Error("failed to read object content", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	// objects smaller than a single part are uploaded with one put object call,
+	// larger objects are streamed to s3 part by part so that the whole object is never held in memory
+	serverSideEncryption, kmsKeyId := s3ServerSideEncryption(objectUpload.Encryption)
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(objectUpload.Encryption)
+
+	if n < objectUploadPartSize {
+		_, err = s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:               aws.String(s.bucket),
+			Key:                  aws.String(key),
+			ContentType:          aws.String(objectUpload.ContentType),
+			ContentLength:        aws.Int64(int64(n)),
+			Body:                 bytes.NewReader(part[:n]),
+			ServerSideEncryption: serverSideEncryption,
+			SSEKMSKeyId:          kmsKeyId,
+			SSECustomerAlgorithm: customerAlgorithm,
+			SSECustomerKey:       customerKey,
+			SSECustomerKeyMD5:    customerKeyMD5,
+		})
+		if err != nil {
+			s.logger.Error("failed to put object", zap.Error(err), zapfield.Operation(op))
+			return err
+		}
+
+		return nil
+	}
+
+	multipartUpload, err := s.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		ContentType:          aws.String(objectUpload.ContentType),
+		ServerSideEncryption: serverSideEncryption,
+		SSEKMSKeyId:          kmsKeyId,
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	})
+	if err != nil {
+		s.logger.Error("failed to create multipart upload", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	var completedParts []types.CompletedPart
+
+	for partNumber := int32(1); n > 0; partNumber++ {
+		uploadedPart, err := s.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:               aws.String(s.bucket),
+			Key:                  aws.String(key),
+			UploadId:             multipartUpload.UploadId,
+			PartNumber:           aws.Int32(partNumber),
+			ContentLength:        aws.Int64(int64(n)),
+			Body:                 bytes.NewReader(part[:n]),
+			SSECustomerAlgorithm: customerAlgorithm,
+			SSECustomerKey:       customerKey,
+			SSECustomerKeyMD5:    customerKeyMD5,
+		})
+		if err != nil {
+			s.logger.Error("failed to upload object part", zap.Error(err), zapfield.Operation(op))
+			s.abortMultipartUpload(ctx, key, multipartUpload.UploadId, op)
+			return err
+		}
+
+		completedParts = append(completedParts, types.CompletedPart{
+			ETag:       uploadedPart.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+
+		n, err = io.ReadFull(objectUpload.Content, part)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			s.logger.Error("failed to read object content", zap.Error(err), zapfield.Operation(op))
+			s.abortMultipartUpload(ctx, key, multipartUpload.UploadId, op)
+			return err
+		}
+	}
+
+	_, err = s.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: multipartUpload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	})
+	if err != nil {
+		s.logger.Error("failed to complete multipart upload", zap.Error(err), zapfield.Operation(op))
+		s.abortMultipartUpload(ctx, key, multipartUpload.UploadId, op)
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Backend) CreatePreSignedUploadObject(ctx context.Context, preSignedUploadObjectCreate *PreSignedUploadObjectCreate) (*PreSignedObject, error) {
+	const op = "S3Backend.CreatePreSignedUploadObject"
+
+	expiresIn := preSignedExpiresIn(preSignedUploadObjectCreate.ExpiresIn, s.config.DefaultPreSignedUploadUrlExpiry)
+
+	key := createS3Key(preSignedUploadObjectCreate.Bucket, preSignedUploadObjectCreate.Name)
+
+	putObjectInput := &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(preSignedUploadObjectCreate.ContentType),
+		ContentLength: aws.Int64(preSignedUploadObjectCreate.ContentLength),
+	}
+
+	putObjectInput.ServerSideEncryption, putObjectInput.SSEKMSKeyId = s3ServerSideEncryption(preSignedUploadObjectCreate.Encryption)
+	putObjectInput.SSECustomerAlgorithm, putObjectInput.SSECustomerKey, putObjectInput.SSECustomerKeyMD5 = s3CustomerKey(preSignedUploadObjectCreate.Encryption)
+
+	if preSignedUploadObjectCreate.ChecksumAlgorithm != nil && preSignedUploadObjectCreate.Checksum != nil {
+		switch *preSignedUploadObjectCreate.ChecksumAlgorithm {
+		case ChecksumAlgorithmSHA256:
+			putObjectInput.ChecksumSHA256 = preSignedUploadObjectCreate.Checksum
+		case ChecksumAlgorithmCRC32C:
+			putObjectInput.ChecksumCRC32C = preSignedUploadObjectCreate.Checksum
+		case ChecksumAlgorithmMD5:
+			putObjectInput.ContentMD5 = preSignedUploadObjectCreate.Checksum
+		default:
+			return nil, fmt.Errorf("unsupported checksum algorithm '%s'", *preSignedUploadObjectCreate.ChecksumAlgorithm)
+		}
+	}
+
+	preSignedPutObject, err := s.s3PreSignedClient.PresignPutObject(ctx, putObjectInput,
+		s3.WithPresignExpires(expiresIn),
+	)
+	if err != nil {
+		s.logger.Error("failed to create pre-signed put object", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	return &PreSignedObject{
+		Url:       preSignedPutObject.URL,
+		Method:    preSignedPutObject.Method,
+		ExpiresAt: time.Now().Unix() + int64(expiresIn.Seconds()),
+		Headers:   signedHeaders(preSignedPutObject.SignedHeader),
+	}, nil
+}
+
+func (s *S3Backend) CreatePreSignedDownloadObject(ctx context.Context, preSignedDownloadObjectCreate *PreSignedDownloadObjectCreate) (*PreSignedObject, error) {
+	const op = "S3Backend.CreatePreSignedDownloadObject"
+
+	expiresIn := preSignedExpiresIn(preSignedDownloadObjectCreate.ExpiresIn, s.config.DefaultPreSignedDownloadUrlExpiry)
+
+	key := createS3Key(preSignedDownloadObjectCreate.Bucket, preSignedDownloadObjectCreate.Name)
+
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(preSignedDownloadObjectCreate.Encryption)
+
+	preSignedGetObject, err := s.s3PreSignedClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	},
+		s3.WithPresignExpires(expiresIn),
+	)
+	if err != nil {
+		s.logger.Error("failed to create pre-signed get object", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	return &PreSignedObject{
+		Url:       preSignedGetObject.URL,
+		Method:    preSignedGetObject.Method,
+		ExpiresAt: time.Now().Unix() + int64(expiresIn.Seconds()),
+		Headers:   signedHeaders(preSignedGetObject.SignedHeader),
+	}, nil
+}
+
+func (s *S3Backend) CreateMultipartUpload(ctx context.Context, multipartUploadCreate *MultipartUploadCreate) (string, error) {
+	const op = "S3Backend.CreateMultipartUpload"
+
+	key := createS3Key(multipartUploadCreate.Bucket, multipartUploadCreate.Name)
+
+	serverSideEncryption, kmsKeyId := s3ServerSideEncryption(multipartUploadCreate.Encryption)
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(multipartUploadCreate.Encryption)
+
+	multipartUpload, err := s.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		ContentType:          aws.String(multipartUploadCreate.ContentType),
+		ServerSideEncryption: serverSideEncryption,
+		SSEKMSKeyId:          kmsKeyId,
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	})
+	if err != nil {
+		s.logger.Error("failed to create multipart upload", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	return aws.ToString(multipartUpload.UploadId), nil
+}
+
+func (s *S3Backend) CreatePreSignedUploadPart(ctx context.Context, preSignedUploadPartCreate *PreSignedUploadPartCreate) (*PreSignedObject, error) {
+	const op = "S3Backend.CreatePreSignedUploadPart"
+
+	expiresIn := preSignedExpiresIn(preSignedUploadPartCreate.ExpiresIn, s.config.DefaultPreSignedUploadUrlExpiry)
+
+	key := createS3Key(preSignedUploadPartCreate.Bucket, preSignedUploadPartCreate.Name)
+
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(preSignedUploadPartCreate.Encryption)
+
+	preSignedUploadPart, err := s.s3PreSignedClient.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		UploadId:             aws.String(preSignedUploadPartCreate.UploadId),
+		PartNumber:           aws.Int32(preSignedUploadPartCreate.PartNumber),
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	},
+		s3.WithPresignExpires(expiresIn),
+	)
+	if err != nil {
+		s.logger.Error("failed to create pre-signed upload part", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	return &PreSignedObject{
+		Url:       preSignedUploadPart.URL,
+		Method:    preSignedUploadPart.Method,
+		ExpiresAt: time.Now().Unix() + int64(expiresIn.Seconds()),
+		Headers:   signedHeaders(preSignedUploadPart.SignedHeader),
+	}, nil
+}
+
+func (s *S3Backend) ListObjects(ctx context.Context, objectsList *ObjectsList) ([]*ListedObject, error) {
+	const op = "S3Backend.ListObjects"
+
+	bucketPrefix := createS3Key(objectsList.Bucket, "")
+
+	listObjectsInput := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		Prefix:  aws.String(bucketPrefix + objectsList.Prefix),
+		MaxKeys: aws.Int32(objectsList.MaxKeys),
+	}
+	if objectsList.StartAfter != "" {
+		listObjectsInput.StartAfter = aws.String(createS3Key(objectsList.Bucket, objectsList.StartAfter))
+	}
+
+	var listedObjects []*ListedObject
+
+	// s3 returns at most 1000 objects per call, larger listings are continued until max keys objects are listed
+	for {
+		listObjects, err := s.s3Client.ListObjectsV2(ctx, listObjectsInput)
+		if err != nil {
+			s.logger.Error("failed to list objects", zap.Error(err), zapfield.Operation(op))
+			return nil, err
+		}
+
+		for _, object := range listObjects.Contents {
+			listedObjects = append(listedObjects, &ListedObject{
+				Name:         strings.TrimPrefix(aws.ToString(object.Key), bucketPrefix),
+				Size:         aws.ToInt64(object.Size),
+				ETag:         aws.ToString(object.ETag),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+
+		if !aws.ToBool(listObjects.IsTruncated) || len(listedObjects) >= int(objectsList.MaxKeys) {
+			break
+		}
+		listObjectsInput.ContinuationToken = listObjects.NextContinuationToken
+		listObjectsInput.MaxKeys = aws.Int32(objectsList.MaxKeys - int32(len(listedObjects)))
+	}
+
+	return listedObjects, nil
+}
+
+func (s *S3Backend) ListUploadedParts(ctx context.Context, uploadedPartsList *UploadedPartsList) ([]*UploadedPart, error) {
+	const op = "S3Backend.ListUploadedParts"
+
+	key := createS3Key(uploadedPartsList.Bucket, uploadedPartsList.Name)
+
+	var uploadedParts []*UploadedPart
+	var partNumberMarker *string
+
+	for {
+		listParts, err := s.s3Client.ListParts(ctx, &s3.ListPartsInput{
+			Bucket:           aws.String(s.bucket),
+			Key:              aws.String(key),
+			UploadId:         aws.String(uploadedPartsList.UploadId),
+			PartNumberMarker: partNumberMarker,
+		})
+		if err != nil {
+			if isNotFoundError(err) {
+				return nil, ErrObjectNotFound
+			}
+			s.logger.Error("failed to list uploaded parts", zap.Error(err), zapfield.Operation(op))
+			return nil, err
+		}
+
+		for _, part := range listParts.Parts {
+			uploadedParts = append(uploadedParts, &UploadedPart{
+				PartNumber:   aws.ToInt32(part.PartNumber),
+				ETag:         aws.ToString(part.ETag),
+				Size:         aws.ToInt64(part.Size),
+				LastModified: aws.ToTime(part.LastModified),
+			})
+		}
+
+		if !aws.ToBool(listParts.IsTruncated) {
+			break
+		}
+		partNumberMarker = listParts.NextPartNumberMarker
+	}
+
+	return uploadedParts, nil
+}
+
+func (s *S3Backend) CompleteMultipartUpload(ctx context.Context, multipartUploadComplete *MultipartUploadComplete) error {
+	const op = "S3Backend.CompleteMultipartUpload"
+
+	key := createS3Key(multipartUploadComplete.Bucket, multipartUploadComplete.Name)
+
+	var completedParts []types.CompletedPart
+	for _, part := range multipartUploadComplete.Parts {
+		completedParts = append(completedParts, types.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int32(part.PartNumber),
+		})
+	}
+
+	_, err := s.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(multipartUploadComplete.UploadId),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	})
+	if err != nil {
+		if isInvalidPartError(err) {
+			return ErrInvalidPart
+		}
+		s.logger.Error("failed to complete multipart upload", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Backend) AbortMultipartUpload(ctx context.Context, multipartUploadAbort *MultipartUploadAbort) error {
+	const op = "S3Backend.AbortMultipartUpload"
+
+	key := createS3Key(multipartUploadAbort.Bucket, multipartUploadAbort.Name)
+
+	_, err := s.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(multipartUploadAbort.UploadId),
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		s.logger.Error("failed to abort multipart upload", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Backend) CheckIfObjectExists(ctx context.Context, objectExistsCheck *ObjectExistsCheck) (bool, error) {
+	const op = "S3Backend.ObjectExistsCheck"
+
+	key := createS3Key(objectExistsCheck.Bucket, objectExistsCheck.Name)
+
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(objectExistsCheck.Encryption)
+
+	_, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return false, nil
+		}
+		s.logger.Error("failed to head object", zap.Error(err), zapfield.Operation(op))
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *S3Backend) HeadObject(ctx context.Context, objectHead *ObjectHead) (*ObjectInfo, error) {
+	const op = "S3Backend.HeadObject"
+
+	key := createS3Key(objectHead.Bucket, objectHead.Name)
+
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(objectHead.Encryption)
+
+	headObject, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		ChecksumMode:         types.ChecksumModeEnabled,
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, ErrObjectNotFound
+		}
+		s.logger.Error("failed to head object", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		ContentType:    aws.ToString(headObject.ContentType),
+		ContentLength:  aws.ToInt64(headObject.ContentLength),
+		ETag:           aws.ToString(headObject.ETag),
+		LastModified:   aws.ToTime(headObject.LastModified),
+		ChecksumSHA256: aws.ToString(headObject.ChecksumSHA256),
+		ChecksumCRC32C: aws.ToString(headObject.ChecksumCRC32C),
+	}, nil
+}
+
+func (s *S3Backend) DetectContentType(ctx context.Context, objectContentTypeDetection *ObjectContentTypeDetection) (string, error) {
+	const op = "S3Backend.DetectContentType"
+
+	key := createS3Key(objectContentTypeDetection.Bucket, objectContentTypeDetection.Name)
+
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(objectContentTypeDetection.Encryption)
+
+	getObject, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		Range:                aws.String(fmt.Sprintf("bytes=0-%d", contentTypeDetectionSize-1)),
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return "", ErrObjectNotFound
+		}
+		s.logger.Error("failed to get object", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+	defer getObject.Body.Close()
+
+	contentType, err := detectContentType(getObject.Body)
+	if err != nil {
+		s.logger.Error("failed to read object", zap.Error(err), zapfield.Operation(op))
+		return "", err
+	}
+
+	return contentType, nil
+}
+
+func (s *S3Backend) GetObject(ctx context.Context, objectGet *ObjectGet) (*ObjectContent, error) {
+	const op = "S3Backend.GetObject"
+
+	key := createS3Key(objectGet.Bucket, objectGet.Name)
+
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(objectGet.Encryption)
+
+	getObject, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(key),
+		Range:                objectGet.Range,
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, ErrObjectNotFound
+		}
+		s.logger.Error("failed to get object", zap.Error(err), zapfield.Operation(op))
+		return nil, err
+	}
+
+	return &ObjectContent{
+		ContentType:   aws.ToString(getObject.ContentType),
+		ContentLength: aws.ToInt64(getObject.ContentLength),
+		ContentRange:  getObject.ContentRange,
+		ETag:          aws.ToString(getObject.ETag),
+		LastModified:  aws.ToTime(getObject.LastModified),
+		Content:       getObject.Body,
+	}, nil
+}
+
+func (s *S3Backend) CopyObject(ctx context.Context, objectCopy *ObjectCopy) error {
+	const op = "S3Backend.CopyObject"
+
+	sourceKey := createS3Key(objectCopy.SourceBucket, objectCopy.SourceName)
+	destinationKey := createS3Key(objectCopy.DestinationBucket, objectCopy.DestinationName)
+	copySource := createS3CopySource(s.bucket, sourceKey)
+
+	sourceCustomerAlgorithm, sourceCustomerKey, sourceCustomerKeyMD5 := s3CustomerKey(objectCopy.SourceEncryption)
+	serverSideEncryption, kmsKeyId := s3ServerSideEncryption(objectCopy.Encryption)
+	customerAlgorithm, customerKey, customerKeyMD5 := s3CustomerKey(objectCopy.Encryption)
+
+	headObject, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(sourceKey),
+		SSECustomerAlgorithm: sourceCustomerAlgorithm,
+		SSECustomerKey:       sourceCustomerKey,
+		SSECustomerKeyMD5:    sourceCustomerKeyMD5,
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return ErrObjectNotFound
+		}
+		s.logger.Error("failed to head source object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	size := aws.ToInt64(headObject.ContentLength)
+
+	if size <= objectCopyMaxSize {
+		copyObjectInput := &s3.CopyObjectInput{
+			Bucket:                         aws.String(s.bucket),
+			Key:                            aws.String(destinationKey),
+			CopySource:                     aws.String(copySource),
+			CopySourceSSECustomerAlgorithm: sourceCustomerAlgorithm,
+			CopySourceSSECustomerKey:       sourceCustomerKey,
+			CopySourceSSECustomerKeyMD5:    sourceCustomerKeyMD5,
+			ServerSideEncryption:           serverSideEncryption,
+			SSEKMSKeyId:                    kmsKeyId,
+			SSECustomerAlgorithm:           customerAlgorithm,
+			SSECustomerKey:                 customerKey,
+			SSECustomerKeyMD5:              customerKeyMD5,
+		}
+		if objectCopy.ContentType != nil {
+			copyObjectInput.ContentType = objectCopy.ContentType
+			copyObjectInput.MetadataDirective = types.MetadataDirectiveReplace
+		}
+
+		_, err = s.s3Client.CopyObject(ctx, copyObjectInput)
+		if err != nil {
+			s.logger.Error("failed to copy object", zap.Error(err), zapfield.Operation(op))
+			return err
+		}
+
+		return nil
+	}
+
+	contentType := headObject.ContentType
+	if objectCopy.ContentType != nil {
+		contentType = objectCopy.ContentType
+	}
+
+	multipartUpload, err := s.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  aws.String(destinationKey),
+		ContentType:          contentType,
+		ServerSideEncryption: serverSideEncryption,
+		SSEKMSKeyId:          kmsKeyId,
+		SSECustomerAlgorithm: customerAlgorithm,
+		SSECustomerKey:       customerKey,
+		SSECustomerKeyMD5:    customerKeyMD5,
+	})
+	if err != nil {
+		s.logger.Error("failed to create multipart upload", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	partSize := int64(objectCopyPartSize)
+	if size > partSize*objectCopyMaxPartCount {
+		partSize = (size + objectCopyMaxPartCount - 1) / objectCopyMaxPartCount
+	}
+
+	var completedParts []types.CompletedPart
+
+	for partNumber, start := int32(1), int64(0); start < size; partNumber, start = partNumber+1, start+partSize {
+		end := min(start+partSize, size) - 1
+
+		copiedPart, err := s.s3Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:                         aws.String(s.bucket),
+			Key:                            aws.String(destinationKey),
+			UploadId:                       multipartUpload.UploadId,
+			PartNumber:                     aws.Int32(partNumber),
+			CopySource:                     aws.String(copySource),
+			CopySourceRange:                aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			CopySourceSSECustomerAlgorithm: sourceCustomerAlgorithm,
+			CopySourceSSECustomerKey:       sourceCustomerKey,
+			CopySourceSSECustomerKeyMD5:    sourceCustomerKeyMD5,
+			SSECustomerAlgorithm:           customerAlgorithm,
+			SSECustomerKey:                 customerKey,
+			SSECustomerKeyMD5:              customerKeyMD5,
+		})
+		if err != nil {
+			s.logger.Error("failed to copy object part", zap.Error(err), zapfield.Operation(op))
+			s.abortMultipartUpload(ctx, destinationKey, multipartUpload.UploadId, op)
+			return err
+		}
+
+		completedParts = append(completedParts, types.CompletedPart{
+			ETag:       copiedPart.CopyPartResult.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+	}
+
+	_, err = s.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(destinationKey),
+		UploadId: multipartUpload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	})
+	if err != nil {
+		s.logger.Error("failed to complete multipart upload", zap.Error(err), zapfield.Operation(op))
+		s.abortMultipartUpload(ctx, destinationKey, multipartUpload.UploadId, op)
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3Backend) DeleteObject(ctx context.Context, objectDelete *ObjectDelete) error {
+	const op = "S3Backend.DeleteObject"
+
+	key := createS3Key(objectDelete.Bucket, objectDelete.Name)
+
+	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		s.logger.Error("failed to delete object", zap.Error(err), zapfield.Operation(op))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteObjects deletes the objects in batches of up to 1000 objects. objects s3 fails to delete are returned instead
+// of failing the whole call, an error is only returned when a batch could not be sent. objects that do not exist are
+// considered deleted
+func (s *S3Backend) DeleteObjects(ctx context.Context, objectsDelete *ObjectsDelete) ([]*ObjectDeleteError, error) {
+	const op = "S3Backend.DeleteObjects"
+
+	var deleteErrors []*ObjectDeleteError
+
+	for _, names := range lo.Chunk(objectsDelete.Names, objectDeleteBatchSize) {
+		objectIdentifiers := make([]types.ObjectIdentifier, 0, len(names))
+		for _, name := range names {
+			objectIdentifiers = append(objectIdentifiers, types.ObjectIdentifier{
+				Key: aws.String(createS3Key(objectsDelete.Bucket, name)),
+			})
+		}
+
+		output, err := s.s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &types.Delete{
+				Objects: objectIdentifiers,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			s.logger.Error("failed to delete objects", zap.Error(err), zapfield.Operation(op))
+			return nil, err
+		}
+
+		for _, deleteError := range output.Errors {
+			deleteErrors = append(deleteErrors, &ObjectDeleteError{
+				Name:    strings.TrimPrefix(aws.ToString(deleteError.Key), objectsDelete.Bucket+"/"),
+				Code:    aws.ToString(deleteError.Code),
+				Message: aws.ToString(deleteError.Message),
+			})
+		}
+	}
+
+	return deleteErrors, nil
+}
+
+func (s *S3Backend) abortMultipartUpload(ctx context.Context, key string, uploadId *string, op string) {
+	_, err := s.s3Client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: uploadId,
+	})
+	if err != nil && !isNotFoundError(err) {
+		s.logger.Error("failed to abort multipart upload", zap.Error(err), zapfield.Operation(op))
+	}
+}
+
+// s3ServerSideEncryption returns the server-side encryption and kms key id of puts, multipart uploads and copies.
+// sse-c is sent with the customer key headers instead
+func s3ServerSideEncryption(encryption *Encryption) (types.ServerSideEncryption, *string) {
+	if encryption == nil {
+		return "", nil
+	}
+
+	switch encryption.Algorithm {
+	case EncryptionSSES3:
+		return types.ServerSideEncryptionAes256, nil
+	case EncryptionSSEKMS:
+		return types.ServerSideEncryptionAwsKms, encryption.KmsKeyId
+	default:
+		return "", nil
+	}
+}
+
+// s3CustomerKey returns the sse-c algorithm, key and key md5 headers that every request writing or reading an sse-c
+// object has to send. all three are nil for other encryptions
+func s3CustomerKey(encryption *Encryption) (*string, *string, *string) {
+	if encryption == nil || encryption.Algorithm != EncryptionSSEC || encryption.CustomerKey == nil {
+		return nil, nil, nil
+	}
+
+	customerKeyMD5, err := CustomerKeyMD5(*encryption.CustomerKey)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	return aws.String(string(types.ServerSideEncryptionAes256)), encryption.CustomerKey, aws.String(customerKeyMD5)
+}
+
+// signedHeaders returns the signed headers of a pre-signed request other than host, which the client has to send
+// with the request to the url
+func signedHeaders(signedHeader http.Header) map[string]string {
+	headers := make(map[string]string, len(signedHeader))
+	for name, values := range signedHeader {
+		if strings.EqualFold(name, "host") || len(values) == 0 {
+			continue
+		}
+		headers[name] = values[0]
+	}
+	return headers
+}
+
+func isNotFoundError(err error) bool {
+	var responseError *awshttp.ResponseError
+	return errors.As(err, &responseError) && responseError.ResponseError.HTTPStatusCode() == http.StatusNotFound
+}
+
+// isInvalidPartError reports whether s3 rejected a multipart upload completion because of the parts it was sent
+func isInvalidPartError(err error) bool {
+	var apiError smithy.APIError
+	if !errors.As(err, &apiError) {
+		return false
+	}
+	return lo.Contains([]string{"InvalidPart", "InvalidPartOrder", "EntityTooSmall"}, apiError.ErrorCode())
+}
+
+func createS3Key(bucket string, name string) string {
+	return fmt.Sprintf(`%s/%s`, bucket, name)
+}
+
+// createS3CopySource creates the url encoded copy source of a key that s3 expects in copy requests
+func createS3CopySource(s3Bucket string, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf(`%s/%s`, s3Bucket, strings.Join(segments, "/"))
+}
@@ -1,33 +1,82 @@
 package storage
 
-import "io"
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
 
-type ObjectUpload struct {
-	Bucket      string    `json:"bucket"`
-	Name        string    `json:"name"`
-	ContentType string    `json:"content_type"`
-	Content     io.Reader `json:"content"`
+const (
+	ChecksumAlgorithmSHA256 = "sha256"
+	ChecksumAlgorithmCRC32C = "crc32c"
+	ChecksumAlgorithmMD5    = "md5"
+)
+
+const (
+	EncryptionSSES3  = "sse-s3"
+	EncryptionSSEKMS = "sse-kms"
+	EncryptionSSEC   = "sse-c"
+)
+
+// customerKeySize is the size of the aes-256 keys s3 accepts for sse-c
+const customerKeySize = 32
+
+var ErrInvalidCustomerKey = errors.New("customer key must be a base64 encoded 256-bit key")
+
+// Encryption is the server-side encryption s3 applies to an object. KmsKeyId is only used with sse-kms, nil uses the
+// aws managed kms key. CustomerKey is the base64 encoded key of sse-c, s3 does not keep it so every request that reads
+// the object has to send the key again. nil encryption leaves the object to the default encryption of the s3 bucket
+type Encryption struct {
+	Algorithm   string  `json:"algorithm"`
+	KmsKeyId    *string `json:"kms_key_id"`
+	CustomerKey *string `json:"customer_key"`
 }
 
-type ObjectRename struct {
-	OldName string `json:"old_name"`
-	NewName string `json:"new_name"`
+// CustomerKeyMD5 returns the base64 encoded md5 digest of a base64 encoded sse-c customer key, which identifies the key
+// without revealing it
+func CustomerKeyMD5(customerKey string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(customerKey)
+	if err != nil || len(key) != customerKeySize {
+		return "", ErrInvalidCustomerKey
+	}
+	digest := md5.Sum(key)
+	return base64.StdEncoding.EncodeToString(digest[:]), nil
 }
 
-type ObjectCopy struct {
-	OldPath string `json:"old_path"`
-	NewPath string `json:"new_path"`
+type ObjectUpload struct {
+	Bucket      string      `json:"bucket"`
+	Name        string      `json:"name"`
+	ContentType string      `json:"content_type"`
+	Content     io.Reader   `json:"content"`
+	Encryption  *Encryption `json:"encryption"`
 }
 
-type ObjectMove struct {
-	OldPath string `json:"old_path"`
-	NewPath string `json:"new_path"`
+// ObjectCopy copies an object to another name within the same bucket or to another bucket.
+// renames and moves are a copy followed by a deletion of the source object
+type ObjectCopy struct {
+	SourceBucket      string `json:"source_bucket"`
+	SourceName        string `json:"source_name"`
+	DestinationBucket string `json:"destination_bucket"`
+	DestinationName   string `json:"destination_name"`
+	// ContentType replaces the content type of the copy when set, otherwise the content type of the source is kept.
+	// copying an object onto itself with a content type updates the content type in place
+	ContentType *string `json:"content_type"`
+	// SourceEncryption is the encryption the source was written with, only needed to read sse-c sources.
+	// Encryption is applied to the copy
+	SourceEncryption *Encryption `json:"source_encryption"`
+	Encryption       *Encryption `json:"encryption"`
 }
 
 type PreSignedObject struct {
 	Url       string `json:"url"`
 	Method    string `json:"method"`
 	ExpiresAt int64  `json:"expires_at"`
+	// Headers are the signed headers other than host that the request to the url has to be sent with
+	Headers map[string]string `json:"headers"`
 }
 
 type PreSignedUploadObjectCreate struct {
@@ -36,17 +85,24 @@ type PreSignedUploadObjectCreate struct {
 	ExpiresIn     *int64 `json:"expires_in"`
 	ContentType   string `json:"content_type"`
 	ContentLength int64  `json:"content_length"`
+	// ChecksumAlgorithm and Checksum are signed into the url when set so that s3 rejects uploads with other content.
+	// Checksum is the base64 encoded digest
+	ChecksumAlgorithm *string     `json:"checksum_algorithm"`
+	Checksum          *string     `json:"checksum"`
+	Encryption        *Encryption `json:"encryption"`
 }
 
 type PreSignedDownloadObjectCreate struct {
-	Bucket    string `json:"bucket"`
-	Name      string `json:"name"`
-	ExpiresIn *int64 `json:"expires_in"`
+	Bucket     string      `json:"bucket"`
+	Name       string      `json:"name"`
+	ExpiresIn  *int64      `json:"expires_in"`
+	Encryption *Encryption `json:"encryption"`
 }
 
 type ObjectExistsCheck struct {
-	Bucket string `json:"bucket"`
-	Name   string `json:"name"`
+	Bucket     string      `json:"bucket"`
+	Name       string      `json:"name"`
+	Encryption *Encryption `json:"encryption"`
 }
 
 type ObjectDelete struct {
@@ -54,6 +110,167 @@ type ObjectDelete struct {
 	Name   string `json:"name"`
 }
 
+// ObjectsDelete deletes many objects of a bucket with as few requests as s3 allows
+type ObjectsDelete struct {
+	Bucket string   `json:"bucket"`
+	Names  []string `json:"names"`
+}
+
+// ObjectDeleteError is an object s3 failed to delete in a bulk delete, the other objects of the request are deleted
+type ObjectDeleteError struct {
+	Name    string `json:"name"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ObjectsList struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+	// StartAfter lists the objects whose name sorts after it
+	StartAfter string `json:"start_after"`
+	MaxKeys    int32  `json:"max_keys"`
+}
+
+type ListedObject struct {
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
 type BucketEmpty struct {
 	Bucket string `json:"bucket"`
 }
+
+// ObjectContentTypeDetection sniffs the content type of an object from its first bytes
+type ObjectContentTypeDetection struct {
+	Bucket     string      `json:"bucket"`
+	Name       string      `json:"name"`
+	Encryption *Encryption `json:"encryption"`
+}
+
+type ObjectHead struct {
+	Bucket     string      `json:"bucket"`
+	Name       string      `json:"name"`
+	Encryption *Encryption `json:"encryption"`
+}
+
+type ObjectInfo struct {
+	ContentType    string    `json:"content_type"`
+	ContentLength  int64     `json:"content_length"`
+	ETag           string    `json:"etag"`
+	LastModified   time.Time `json:"last_modified"`
+	ChecksumSHA256 string    `json:"checksum_sha256"`
+	ChecksumCRC32C string    `json:"checksum_crc32c"`
+}
+
+// MatchesChecksum reports whether the object has the given base64 encoded checksum.
+// s3 does not store md5 checksums, those are compared against the etag which is the hex encoded md5 digest
+// of objects uploaded with a single put. the `<digest>-<parts>` etag of objects completed from parts is not a digest
+// of the content, so md5 checksums can not be verified for them and are skipped
+func (o *ObjectInfo) MatchesChecksum(algorithm string, checksum string) bool {
+	switch algorithm {
+	case ChecksumAlgorithmSHA256:
+		return o.ChecksumSHA256 == checksum
+	case ChecksumAlgorithmCRC32C:
+		return o.ChecksumCRC32C == checksum
+	case ChecksumAlgorithmMD5:
+		digest, err := base64.StdEncoding.DecodeString(checksum)
+		if err != nil {
+			return false
+		}
+		etag := strings.Trim(o.ETag, `"`)
+		if isMultipartETag(etag) {
+			return true
+		}
+		return etag == hex.EncodeToString(digest)
+	default:
+		return false
+	}
+}
+
+type ObjectGet struct {
+	Bucket string `json:"bucket"`
+	Name   string `json:"name"`
+	// Range is an http range header value like `bytes=0-1023`. nil gets the whole object
+	Range      *string     `json:"range"`
+	Encryption *Encryption `json:"encryption"`
+}
+
+type ObjectContent struct {
+	ContentType   string        `json:"content_type"`
+	ContentLength int64         `json:"content_length"`
+	ContentRange  *string       `json:"content_range"`
+	ETag          string        `json:"etag"`
+	LastModified  time.Time     `json:"last_modified"`
+	Content       io.ReadCloser `json:"content"`
+}
+
+type MultipartUploadCreate struct {
+	Bucket      string      `json:"bucket"`
+	Name        string      `json:"name"`
+	ContentType string      `json:"content_type"`
+	Encryption  *Encryption `json:"encryption"`
+}
+
+type PreSignedUploadPartCreate struct {
+	Bucket     string      `json:"bucket"`
+	Name       string      `json:"name"`
+	UploadId   string      `json:"upload_id"`
+	PartNumber int32       `json:"part_number"`
+	ExpiresIn  *int64      `json:"expires_in"`
+	Encryption *Encryption `json:"encryption"`
+}
+
+type UploadedPartsList struct {
+	Bucket   string `json:"bucket"`
+	Name     string `json:"name"`
+	UploadId string `json:"upload_id"`
+}
+
+type UploadedPart struct {
+	PartNumber   int32     `json:"part_number"`
+	ETag         string    `json:"etag"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+type MultipartUploadComplete struct {
+	Bucket   string           `json:"bucket"`
+	Name     string           `json:"name"`
+	UploadId string           `json:"upload_id"`
+	Parts    []*CompletedPart `json:"parts"`
+}
+
+type CompletedPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+type MultipartUploadAbort struct {
+	Bucket   string `json:"bucket"`
+	Name     string `json:"name"`
+	UploadId string `json:"upload_id"`
+}
+
+// SignedRequest is a request to a pre-signed url of the filesystem backend. every field except the signature is
+// covered by the signature, so a request is only accepted with exactly the values the url was created with
+type SignedRequest struct {
+	Method            string  `json:"method"`
+	Bucket            string  `json:"bucket"`
+	Name              string  `json:"name"`
+	ExpiresAt         int64   `json:"expires_at"`
+	UploadId          *string `json:"upload_id"`
+	PartNumber        *int32  `json:"part_number"`
+	ContentType       *string `json:"content_type"`
+	ContentLength     *int64  `json:"content_length"`
+	ChecksumAlgorithm *string `json:"checksum_algorithm"`
+	Checksum          *string `json:"checksum"`
+	Signature         string  `json:"signature"`
+}
+
+// SignedUpload is the content sent to a pre-signed upload object or upload part url of the filesystem backend
+type SignedUpload struct {
+	ContentType string    `json:"content_type"`
+	Content     io.Reader `json:"content"`
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerKeyMD5(t *testing.T) {
+	customerKey := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+
+	customerKeyMD5, err := CustomerKeyMD5(customerKey)
+	require.NoError(t, err)
+	assert.Equal(t, "mT2HRsMGJ5IX5C+0rreZ8Q==", customerKeyMD5)
+
+	_, err = CustomerKeyMD5(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorIs(t, err, ErrInvalidCustomerKey)
+
+	_, err = CustomerKeyMD5("not base64")
+	assert.ErrorIs(t, err, ErrInvalidCustomerKey)
+}
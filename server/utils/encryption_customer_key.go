@@ -0,0 +1,12 @@
+package utils
+
+import "context"
+
+// EncryptionCustomerKey returns the sse-c customer key sent with the request or nil when none was sent
+func EncryptionCustomerKey(ctx context.Context) *string {
+	if customerKey, ok := ctx.Value("encryption_customer_key").(string); ok {
+		return &customerKey
+	}
+
+	return nil
+}